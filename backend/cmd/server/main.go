@@ -1,17 +1,27 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	// Import package internal
+	"github.com/gusti3111/TKBMG/backend/internal/config"
 	"github.com/gusti3111/TKBMG/backend/internal/db"
 	"github.com/gusti3111/TKBMG/backend/internal/handler"
+	"github.com/gusti3111/TKBMG/backend/internal/mailer"
 	"github.com/gusti3111/TKBMG/backend/internal/middleware"
+	"github.com/gusti3111/TKBMG/backend/internal/notifier"
 	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/scheduler"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+	"github.com/gusti3111/TKBMG/backend/internal/stream"
 )
 
 func main() {
@@ -22,12 +32,27 @@ func main() {
 	defer db.CloseDB()
 
 	// 2. Setup Router Gin
-	r := gin.Default()
+	// Sama dengan gin.Default(), tetapi kredensial di query string tidak ikut tercatat di log
+	r := gin.New()
+	r.Use(middleware.LoggerMiddleware(), gin.Recovery())
 
 	// 3. Setup Routes
-	setupRoutes(r)
+	hub := stream.NewHub(config.StreamHistorySize)
+	setupRoutes(r, hub)
 
-	// 4. Jalankan Server
+	// 4. Jalankan Background Jobs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobs := setupScheduler()
+	jobs.Start(ctx)
+
+	// Bridge LISTEN/NOTIFY agar event live sampai ke klien di replika lain
+	if config.StreamPGBridge {
+		go stream.NewPGBridge(db.DB, db.ConnString()).Run(ctx, hub)
+	}
+
+	// 5. Jalankan Server
 	server := &http.Server{
 		Addr:         ":8080",
 		Handler:      r,
@@ -36,31 +61,110 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	log.Printf("✅ Backend BMG berjalan di http://localhost%s", server.Addr)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Gagal menjalankan server: %v", err)
+	go func() {
+		log.Printf("✅ Backend BMG berjalan di http://localhost%s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Gagal menjalankan server: %v", err)
+		}
+	}()
+
+	// 6. Tunggu sinyal berhenti, lalu matikan server dan jobs dengan rapi
+	<-ctx.Done()
+	log.Println("Mematikan server...")
+
+	// Koneksi stream tidak pernah selesai sendiri, putus dulu agar Shutdown tidak menunggu
+	hub.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Gagal mematikan server dengan rapi: %v", err)
 	}
+	jobs.Wait()
 }
 
-func setupRoutes(r *gin.Engine) {
+func setupScheduler() *scheduler.Scheduler {
+	s := scheduler.New()
+	s.Register(scheduler.RecurringItemsJob(repository.NewTemplateRepository(), config.RecurringInterval))
+	s.Register(scheduler.TrashPurgeJob(repository.NewTrashRepository(), config.TrashRetention, config.TrashPurgeInterval))
+	s.Register(scheduler.SavingsSweepJob(
+		service.NewSavingsService(repository.NewSavingsRepository(), repository.NewBudgetRepository(), repository.NewSettingsRepository()),
+		config.SavingsSweepInterval,
+	))
+	s.Register(scheduler.AnomalyScanJob(
+		service.NewAnomalyService(repository.NewAnomalyRepository(), repository.NewSettingsRepository()),
+		config.AnomalyScanInterval,
+	))
+	s.Register(scheduler.ReportMailJob(
+		service.NewReportMailService(
+			repository.NewSubscriptionRepository(),
+			service.NewReportService(repository.NewReportRepository(), repository.NewUserRepository(),
+				service.NewInflationService(repository.NewAnalyticsRepository())),
+			repository.NewUserRepository(),
+			repository.NewSettingsRepository(),
+			mailer.New(config.SMTPAddr, config.SMTPUsername, config.SMTPPassword, config.MailFrom),
+		),
+		config.ReportMailInterval,
+	))
+	return s
+}
+
+func setupRoutes(r *gin.Engine, hub *stream.Hub) {
 	// --- Inisialisasi Repository ---
 	itemRepo := repository.NewItemRepository()
 	categoryRepo := repository.NewCategoryRepository()
 	budgetRepo := repository.NewBudgetRepository()
 	reportRepo := repository.NewReportRepository()
+	templateRepo := repository.NewTemplateRepository()
+	trashRepo := repository.NewTrashRepository()
+	ruleRepo := repository.NewRuleRepository()
+	settingsRepo := repository.NewSettingsRepository()
+	notificationRepo := repository.NewNotificationRepository()
+	userRepo := repository.NewUserRepository()
+	savingsRepo := repository.NewSavingsRepository()
+	subscriptionRepo := repository.NewSubscriptionRepository()
+	analyticsRepo := repository.NewAnalyticsRepository()
+	anomalyRepo := repository.NewAnomalyRepository()
+	eventRepo := repository.NewEventRepository()
+	householdRepo := repository.NewHouseholdRepository()
+	streamTicketRepo := repository.NewStreamTicketRepository()
+
+	// --- Inisialisasi Service ---
+	categorizeService := service.NewCategorizeService(ruleRepo, config.RuleCacheTTL)
+	savingsService := service.NewSavingsService(savingsRepo, budgetRepo, settingsRepo)
+	inflationService := service.NewInflationService(analyticsRepo)
+	reportService := service.NewReportService(reportRepo, userRepo, inflationService)
+	anomalyService := service.NewAnomalyService(anomalyRepo, settingsRepo)
+	dashboardService := service.NewDashboardService(itemRepo, budgetRepo, reportRepo)
+	auditService := service.NewAuditService(eventRepo)
+	alertService := service.NewAlertService(budgetRepo, notificationRepo, userRepo, settingsRepo,
+		notifier.NewEmailChannel(mailer.New(config.SMTPAddr, config.SMTPUsername, config.SMTPPassword, config.MailFrom)),
+	)
 
 	// --- Inisialisasi Handler ---
-	authHandler := handler.NewAuthHandler()
-	categoryHandler := handler.NewCategoryHandler(categoryRepo)
-	itemHandler := handler.NewItemHandler(itemRepo, categoryRepo)
-	dashHandler := handler.NewDashboardHandler(itemRepo, budgetRepo, reportRepo)
-	budgetHandler := handler.NewBudgetHandler(budgetRepo)
+	authHandler := handler.NewAuthHandler(auditService)
+	categoryHandler := handler.NewCategoryHandler(categoryRepo, categorizeService, auditService, hub)
+	itemHandler := handler.NewItemHandler(itemRepo, categoryRepo, categorizeService, alertService, anomalyService, auditService, hub)
+	dashHandler := handler.NewDashboardHandler(itemRepo, budgetRepo, reportRepo, dashboardService)
+	budgetHandler := handler.NewBudgetHandler(budgetRepo, notificationRepo, alertService, auditService, hub)
+	templateHandler := handler.NewTemplateHandler(templateRepo, itemRepo, categorizeService)
+	trashHandler := handler.NewTrashHandler(trashRepo, categorizeService)
+	ruleHandler := handler.NewRuleHandler(ruleRepo, itemRepo, categorizeService)
+	settingsHandler := handler.NewSettingsHandler(settingsRepo)
+	notificationHandler := handler.NewNotificationHandler(notificationRepo)
+	savingsHandler := handler.NewSavingsHandler(savingsRepo, savingsService)
 
 	// Variabel yang menyebabkan error 'declared and not used'
-	reportHandler := handler.NewReportHandler(reportRepo)
+	reportHandler := handler.NewReportHandler(reportService)
+	subscriptionHandler := handler.NewReportSubscriptionHandler(subscriptionRepo)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsRepo, inflationService)
+	anomalyHandler := handler.NewAnomalyHandler(anomalyRepo)
+	activityHandler := handler.NewActivityHandler(eventRepo)
+	streamHandler := handler.NewStreamHandler(hub, streamTicketRepo, config.StreamHeartbeat)
+	householdHandler := handler.NewHouseholdHandler(householdRepo, hub)
 
-	// Terapkan CORS untuk semua endpoint
-	r.Use(middleware.CORSMiddleware())
+	// Terapkan CORS dan request ID (untuk log audit) untuk semua endpoint
+	r.Use(middleware.CORSMiddleware(), middleware.RequestIDMiddleware())
 
 	// --- HEALTH CHECK ---
 	r.GET("/api/health", func(c *gin.Context) {
@@ -76,28 +180,135 @@ func setupRoutes(r *gin.Engine) {
 
 	// --- RUTE TERLINDUNGI (PERLU TOKEN) ---
 	secureV1 := r.Group("/api/v1")
-	secureV1.Use(middleware.AuthMiddleware())
+	// Kalender dimuat setelah household karena periode budget mengikuti pengaturan owner household
+	secureV1.Use(middleware.AuthMiddleware(), middleware.HouseholdMiddleware(householdRepo), middleware.CalendarMiddleware(settingsRepo))
+
+	// Item, kategori, budget dan template milik household; viewer hanya boleh membaca
+	writer := middleware.HouseholdWriterMiddleware()
 	{
 		// Dashboard
+		secureV1.GET("/dashboard", dashHandler.GetDashboard)
 		secureV1.GET("/dashboard/summary", dashHandler.GetDashboardSummary)
 		secureV1.GET("/dashboard/charts", dashHandler.GetDashboardCharts)
+		secureV1.GET("/dashboard/forecast", dashHandler.GetDashboardForecast)
 
 		// Items
-		secureV1.POST("/items", itemHandler.CreateItem)
+		secureV1.POST("/items", writer, itemHandler.CreateItem)
 		secureV1.GET("/items", itemHandler.GetItems)
-		secureV1.PUT("/items/:id", itemHandler.UpdateItem)
-		secureV1.DELETE("/items/:id", itemHandler.DeleteItem)
+		secureV1.PUT("/items/:id", writer, itemHandler.UpdateItem)
+		secureV1.DELETE("/items/:id", writer, itemHandler.DeleteItem)
 
 		// Kategori
-		secureV1.POST("/kategori", categoryHandler.CreateCategory)
+		secureV1.POST("/kategori", writer, categoryHandler.CreateCategory)
 		secureV1.GET("/kategori", categoryHandler.GetCategories)
-		secureV1.PUT("/kategori/:id", categoryHandler.UpdateCategory)
-		secureV1.DELETE("/kategori/:id", categoryHandler.DeleteCategory)
+		secureV1.POST("/kategori/merge", writer, categoryHandler.MergeCategories)
+		secureV1.GET("/kategori/suggest", ruleHandler.SuggestCategory)
+
+		// Aturan Kategori Otomatis
+		secureV1.POST("/kategori/rules", writer, ruleHandler.CreateRule)
+		secureV1.GET("/kategori/rules", ruleHandler.GetRules)
+		secureV1.PUT("/kategori/rules/:id", writer, ruleHandler.UpdateRule)
+		secureV1.DELETE("/kategori/rules/:id", writer, ruleHandler.DeleteRule)
+		secureV1.PUT("/kategori/:id", writer, categoryHandler.UpdateCategory)
+		secureV1.DELETE("/kategori/:id", writer, categoryHandler.DeleteCategory)
+
+		// Template Daftar Belanja
+		secureV1.POST("/templates", writer, templateHandler.CreateTemplate)
+		secureV1.GET("/templates", templateHandler.GetTemplates)
+		secureV1.PUT("/templates/:id", writer, templateHandler.UpdateTemplate)
+		secureV1.DELETE("/templates/:id", writer, templateHandler.DeleteTemplate)
+		secureV1.POST("/templates/:id/apply", writer, templateHandler.ApplyTemplate)
 
 		// Budget
-		secureV1.POST("/budgets", budgetHandler.SetBudget)
+		secureV1.POST("/budgets", writer, budgetHandler.SetBudget)
+		secureV1.GET("/budgets", budgetHandler.GetBudgets)
+		secureV1.GET("/budgets/current", budgetHandler.GetCurrentBudget)
+		secureV1.GET("/budgets/:id", budgetHandler.GetBudget)
+		secureV1.PUT("/budgets/:id", writer, budgetHandler.UpdateBudget)
+		secureV1.DELETE("/budgets/:id", writer, budgetHandler.DeleteBudget)
+		secureV1.GET("/budgets/:id/allocations", budgetHandler.GetAllocations)
+		secureV1.PUT("/budgets/:id/allocations", writer, budgetHandler.SetAllocations)
+		secureV1.GET("/budgets/:id/alerts", budgetHandler.GetAlertThresholds)
+		secureV1.PUT("/budgets/:id/alerts", writer, budgetHandler.SetAlertThresholds)
+
+		// Notifikasi
+		secureV1.GET("/notifications", notificationHandler.GetNotifications)
+		secureV1.PUT("/notifications/read-all", notificationHandler.MarkAllRead)
+		secureV1.PUT("/notifications/:id/read", notificationHandler.MarkRead)
+
+		// Tujuan Tabungan
+		secureV1.POST("/savings-goals", writer, savingsHandler.CreateGoal)
+		secureV1.GET("/savings-goals", savingsHandler.GetGoals)
+		secureV1.GET("/savings-goals/:id", savingsHandler.GetGoal)
+		secureV1.GET("/savings-goals/:id/progress", savingsHandler.GetProgress)
+		secureV1.PUT("/savings-goals/:id", writer, savingsHandler.UpdateGoal)
+		secureV1.DELETE("/savings-goals/:id", writer, savingsHandler.DeleteGoal)
+		secureV1.POST("/savings-goals/:id/contributions", writer, savingsHandler.AddContribution)
+		secureV1.POST("/savings-goals/:id/withdrawals", writer, savingsHandler.WithdrawContribution)
+
+		// Trash
+		secureV1.GET("/trash", trashHandler.GetTrash)
+		secureV1.POST("/trash/:type/:id/restore", writer, trashHandler.RestoreTrash)
+
+		// Pengaturan User
+		secureV1.GET("/settings", settingsHandler.GetSettings)
+		secureV1.PUT("/settings", settingsHandler.UpdateSettings)
 
 		// Reports
 		secureV1.GET("/reports/download", reportHandler.GenerateReport)
+		secureV1.POST("/report-subscriptions", subscriptionHandler.CreateSubscription)
+		secureV1.GET("/report-subscriptions", subscriptionHandler.GetSubscriptions)
+		secureV1.GET("/report-subscriptions/:id", subscriptionHandler.GetSubscription)
+		secureV1.PUT("/report-subscriptions/:id", subscriptionHandler.UpdateSubscription)
+		secureV1.DELETE("/report-subscriptions/:id", subscriptionHandler.DeleteSubscription)
+
+		// Analytics
+		secureV1.GET("/analytics/items/frequent", analyticsHandler.GetFrequentItems)
+		secureV1.GET("/analytics/items/top-spend", analyticsHandler.GetTopSpendItems)
+		secureV1.GET("/analytics/items/pairs", analyticsHandler.GetItemPairs)
+		secureV1.GET("/analytics/baskets", analyticsHandler.GetBaskets)
+		secureV1.GET("/analytics/staples", analyticsHandler.GetStaples)
+		secureV1.GET("/analytics/inflation", analyticsHandler.GetInflation)
+
+		// Anomali Belanja
+		secureV1.GET("/anomalies", anomalyHandler.GetAnomalies)
+		secureV1.POST("/anomalies/:id/dismiss", writer, anomalyHandler.DismissAnomaly)
+		secureV1.POST("/anomalies/:id/confirm", writer, anomalyHandler.ConfirmAnomaly)
+
+		// Aktivitas Terakhir
+		secureV1.GET("/activity", activityHandler.GetActivity)
+
+		// Tiket untuk membuka stream live (SSE)
+		secureV1.POST("/stream/tickets", streamHandler.CreateTicket)
+
+		// Household & Undangan
+		secureV1.POST("/households", householdHandler.CreateHousehold)
+		secureV1.GET("/households", householdHandler.GetHouseholds)
+		secureV1.GET("/households/current", householdHandler.GetCurrentHousehold)
+		secureV1.PUT("/households/:id", householdHandler.RenameHousehold)
+		secureV1.POST("/households/:id/activate", householdHandler.ActivateHousehold)
+		secureV1.GET("/households/:id/members", householdHandler.GetMembers)
+		secureV1.PUT("/households/:id/members/:userId", householdHandler.UpdateMemberRole)
+		secureV1.DELETE("/households/:id/members/:userId", householdHandler.RemoveMember)
+		secureV1.POST("/households/:id/invitations", householdHandler.CreateInvitation)
+		secureV1.GET("/households/:id/invitations", householdHandler.GetInvitations)
+		secureV1.DELETE("/households/:id/invitations/:inviteId", householdHandler.RevokeInvitation)
+		secureV1.POST("/invitations/:token/accept", householdHandler.AcceptInvitation)
+	}
+
+	// --- RUTE STREAM (SSE) ---
+	// EventSource di browser tidak bisa mengirim header, jadi autentikasi memakai tiket
+	// sekali pakai dari POST /stream/tickets lewat ?ticket=, bukan JWT
+	streamV1 := r.Group("/api/v1")
+	streamV1.Use(middleware.StreamTicketMiddleware(streamTicketRepo), middleware.HouseholdMiddleware(householdRepo))
+	{
+		streamV1.GET("/stream", streamHandler.Stream)
+	}
+
+	// --- RUTE ADMIN (PERLU TOKEN DAN ROLE ADMIN) ---
+	adminV1 := secureV1.Group("/admin")
+	adminV1.Use(middleware.AdminMiddleware(userRepo))
+	{
+		adminV1.GET("/audit", activityHandler.GetAuditLog)
 	}
 }
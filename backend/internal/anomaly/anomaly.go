@@ -0,0 +1,137 @@
+// Package anomaly mendeteksi belanja yang tidak biasa dengan statistik robust
+// (median dan MAD) sehingga satu-dua transaksi ekstrem di riwayat tidak ikut
+// menggeser patokan. Riwayat harga dan total mingguan diambil AnomalyService;
+// fungsi di sini hanya menilai angka yang diterimanya.
+package anomaly
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Parameter deteksi
+const (
+	// Threshold adalah batas skor z robust; 3.5 adalah nilai yang umum dipakai
+	// untuk modified z-score (Iglewicz & Hoaglin).
+	Threshold = 3.5
+
+	// MinHistory adalah jumlah data riwayat minimal sebelum sebuah nilai bisa dinilai
+	MinHistory = 4
+
+	// MinRelativeIncrease membuat kenaikan kecil tidak ditandai walaupun riwayatnya
+	// sangat stabil (misal harga selalu sama lalu naik Rp500).
+	MinRelativeIncrease = 0.25
+
+	// PriceHistoryLimit adalah jumlah pembelian terakhir yang dipakai sebagai riwayat harga
+	PriceHistoryLimit = 20
+
+	// CategoryWeeks adalah panjang jendela bergulir (minggu) untuk total mingguan kategori
+	CategoryWeeks = 8
+
+	// DuplicateWindow adalah jarak waktu maksimal dua entri dianggap dobel
+	DuplicateWindow = 10 * time.Minute
+
+	// MaxScore adalah batas atas skor yang dilaporkan (riwayat tanpa variasi sama sekali
+	// menghasilkan skor tak terhingga, yang tidak bisa disimpan maupun dikirim sebagai JSON)
+	MaxScore = 99.0
+)
+
+// Faktor skala agar penyimpangan setara dengan simpangan baku pada data normal
+const (
+	madScale    = 1.4826   // Untuk MAD
+	meanADScale = 1.253314 // Untuk mean absolute deviation, dipakai jika MAD = 0
+)
+
+// Result adalah hasil penilaian satu nilai terhadap riwayatnya
+type Result struct {
+	Median  float64
+	MAD     float64
+	Score   float64 // Modified z-score, dibatasi MaxScore
+	Flagged bool
+}
+
+// Median mengembalikan nilai tengah. Slice masukan tidak diubah.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// MAD mengembalikan median of absolute deviations terhadap median
+func MAD(values []float64, median float64) float64 {
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return Median(deviations)
+}
+
+// meanAbsDev mengembalikan rata-rata penyimpangan absolut terhadap median
+func meanAbsDev(values []float64, median float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += math.Abs(v - median)
+	}
+	return sum / float64(len(values))
+}
+
+// Score menilai apakah 'value' jauh di atas riwayatnya. Hanya penyimpangan ke atas
+// yang ditandai; riwayat yang terlalu pendek tidak pernah menandai apa pun.
+func Score(history []float64, value float64) Result {
+	if len(history) < MinHistory {
+		return Result{}
+	}
+
+	med := Median(history)
+	mad := MAD(history, med)
+	res := Result{Median: med, MAD: mad}
+
+	switch {
+	case mad > 0:
+		res.Score = (value - med) / (madScale * mad)
+	case meanAbsDev(history, med) > 0:
+		// Lebih dari separuh riwayat bernilai sama persis sehingga MAD = 0
+		res.Score = (value - med) / (meanADScale * meanAbsDev(history, med))
+	case value > med:
+		// Riwayat tidak bervariasi sama sekali; yang menentukan adalah MinRelativeIncrease
+		res.Score = MaxScore
+	}
+	res.Score = math.Min(res.Score, MaxScore)
+
+	res.Flagged = res.Score > Threshold && value >= med*(1+MinRelativeIncrease) && value > 0
+	return res
+}
+
+// NormalizeName menyamakan nama item agar "Beras " dan "beras" dianggap sama
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Entry adalah data minimal satu item untuk pengecekan duplikat
+type Entry struct {
+	Name      string
+	UnitPrice float64
+	Time      time.Time
+}
+
+// IsDuplicate melaporkan apakah dua entri kemungkinan dicatat dobel: nama dan harga
+// satuan sama, dan waktu pencatatannya berdekatan (paling lama DuplicateWindow).
+func IsDuplicate(a, b Entry) bool {
+	if NormalizeName(a.Name) != NormalizeName(b.Name) || a.UnitPrice != b.UnitPrice {
+		return false
+	}
+	gap := a.Time.Sub(b.Time)
+	if gap < 0 {
+		gap = -gap
+	}
+	return gap <= DuplicateWindow
+}
@@ -0,0 +1,128 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		history     []float64
+		value       float64
+		wantScore   float64
+		wantMAD     float64
+		wantFlagged bool
+	}{
+		{
+			name:    "riwayat terlalu pendek tidak pernah ditandai",
+			history: []float64{10, 10, 10},
+			value:   1000,
+		},
+		{
+			name:        "jauh di atas median dengan MAD > 0",
+			history:     []float64{10, 11, 12, 13, 14},
+			value:       30,
+			wantScore:   18 / madScale,
+			wantMAD:     1,
+			wantFlagged: true,
+		},
+		{
+			name:      "masih dalam variasi biasa",
+			history:   []float64{10, 11, 12, 13, 14},
+			value:     13,
+			wantScore: 1 / madScale,
+			wantMAD:   1,
+		},
+		{
+			name:      "penyimpangan ke bawah tidak ditandai",
+			history:   []float64{10, 11, 12, 13, 14},
+			value:     2,
+			wantScore: -10 / madScale,
+			wantMAD:   1,
+		},
+		{
+			name:        "MAD = 0, memakai mean absolute deviation",
+			history:     []float64{10, 10, 10, 10, 20},
+			value:       30,
+			wantScore:   20 / (meanADScale * 2),
+			wantFlagged: true,
+		},
+		{
+			name:      "riwayat tanpa variasi, kenaikan di bawah MinRelativeIncrease",
+			history:   []float64{10, 10, 10, 10},
+			value:     12,
+			wantScore: MaxScore,
+		},
+		{
+			name:        "riwayat tanpa variasi, kenaikan cukup besar",
+			history:     []float64{10, 10, 10, 10},
+			value:       13,
+			wantScore:   MaxScore,
+			wantFlagged: true,
+		},
+		{
+			name:    "riwayat tanpa variasi, nilai sama",
+			history: []float64{10, 10, 10, 10},
+			value:   10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.history, tt.value)
+			if math.Abs(got.Score-tt.wantScore) > 1e-9 {
+				t.Errorf("Score = %v, want %v", got.Score, tt.wantScore)
+			}
+			if got.MAD != tt.wantMAD {
+				t.Errorf("MAD = %v, want %v", got.MAD, tt.wantMAD)
+			}
+			if got.Flagged != tt.wantFlagged {
+				t.Errorf("Flagged = %v, want %v", got.Flagged, tt.wantFlagged)
+			}
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{nil, 0},
+		{[]float64{3}, 3},
+		{[]float64{5, 1, 3}, 3},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+	for _, tt := range tests {
+		if got := Median(tt.values); got != tt.want {
+			t.Errorf("Median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	base := time.Date(2026, 10, 7, 9, 0, 0, 0, time.UTC)
+	entry := Entry{Name: "Beras 5kg", UnitPrice: 65000, Time: base}
+
+	tests := []struct {
+		name  string
+		other Entry
+		want  bool
+	}{
+		{"nama beda huruf dan spasi", Entry{Name: "  beras 5KG ", UnitPrice: 65000, Time: base.Add(5 * time.Minute)}, true},
+		{"tepat di batas jendela", Entry{Name: "Beras 5kg", UnitPrice: 65000, Time: base.Add(DuplicateWindow)}, true},
+		{"dicatat lebih dulu", Entry{Name: "Beras 5kg", UnitPrice: 65000, Time: base.Add(-3 * time.Minute)}, true},
+		{"di luar jendela", Entry{Name: "Beras 5kg", UnitPrice: 65000, Time: base.Add(DuplicateWindow + time.Second)}, false},
+		{"harga berbeda", Entry{Name: "Beras 5kg", UnitPrice: 64000, Time: base}, false},
+		{"nama berbeda", Entry{Name: "Beras 10kg", UnitPrice: 65000, Time: base}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDuplicate(entry, tt.other); got != tt.want {
+				t.Errorf("IsDuplicate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package audit menyusun isi log audit: perbandingan data sebelum dan sesudah perubahan
+// dalam bentuk JSON. Menyimpan event bukan tugasnya; itu dikerjakan EventRepository.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Diff membandingkan 'before' dan 'after' (struct yang bisa di-marshal ke objek JSON)
+// dan mengembalikan field yang berubah saja, masing-masing dengan nilai lama dan baru.
+// Jika salah satu nil (create atau delete), sisi lainnya dikembalikan utuh. Field yang
+// tidak berubah dibuang, sehingga update tanpa perubahan menghasilkan dua nilai nil.
+func Diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	oldFields, err := toFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	newFields, err := toFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case oldFields == nil && newFields == nil:
+		return nil, nil, nil
+	case oldFields == nil:
+		return nil, marshal(newFields), nil
+	case newFields == nil:
+		return marshal(oldFields), nil, nil
+	}
+
+	changedOld := make(map[string]json.RawMessage)
+	changedNew := make(map[string]json.RawMessage)
+	for key, value := range newFields {
+		old, ok := oldFields[key]
+		if ok && bytes.Equal(old, value) {
+			continue
+		}
+		if ok {
+			changedOld[key] = old
+		}
+		changedNew[key] = value
+	}
+	for key, old := range oldFields {
+		if _, ok := newFields[key]; !ok {
+			changedOld[key] = old
+		}
+	}
+	if len(changedOld) == 0 && len(changedNew) == 0 {
+		return nil, nil, nil
+	}
+	return marshal(changedOld), marshal(changedNew), nil
+}
+
+// toFields mengubah v menjadi field JSON tingkat teratas; nil jika v nil
+func toFields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("audit: failed to marshal snapshot: %w", err)
+	}
+	if bytes.Equal(raw, []byte("null")) {
+		return nil, nil // Pointer nil yang dibungkus interface
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("audit: snapshot is not a JSON object: %w", err)
+	}
+	return fields, nil
+}
+
+// marshal tidak pernah gagal untuk map berisi json.RawMessage yang valid
+func marshal(fields map[string]json.RawMessage) json.RawMessage {
+	raw, _ := json.Marshal(fields)
+	return raw
+}
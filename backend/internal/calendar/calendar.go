@@ -0,0 +1,154 @@
+// Package calendar adalah satu-satunya sumber perhitungan tanggal yang bergantung
+// pada pengaturan user: zona waktu dan hari pertama dalam seminggu. Periode budget,
+// label minggu, dan bucket laporan semuanya diturunkan dari Calendar milik user.
+package calendar
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Database zona waktu ikut di-embed agar LoadLocation berjalan di image minimal
+)
+
+// Nilai bawaan untuk user yang belum menyimpan pengaturan
+const (
+	DefaultTimezone  = "Asia/Jakarta"
+	DefaultWeekStart = time.Sunday
+)
+
+// Jenis periode budget
+const (
+	PeriodWeekly   = "weekly"
+	PeriodBiweekly = "biweekly"
+	PeriodMonthly  = "monthly"
+	PeriodCustom   = "custom"
+)
+
+// DateLayout adalah format tanggal pada query param dan body request
+const DateLayout = "2006-01-02"
+
+// Calendar menghitung hari, minggu, dan periode dalam zona waktu user
+type Calendar struct {
+	loc       *time.Location
+	weekStart time.Weekday
+}
+
+// New membuat Calendar dari nama zona waktu IANA (misal "Asia/Makassar")
+// dan hari pertama minggu (0 = Minggu, 1 = Senin, ...).
+func New(timezone string, weekStart time.Weekday) (Calendar, error) {
+	if weekStart < time.Sunday || weekStart > time.Saturday {
+		return Calendar{}, fmt.Errorf("week_start harus antara 0 (Minggu) dan 6 (Sabtu)")
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Calendar{}, fmt.Errorf("timezone tidak dikenal: %q", timezone)
+	}
+	return Calendar{loc: loc, weekStart: weekStart}, nil
+}
+
+// Default mengembalikan Calendar dengan zona waktu dan awal minggu bawaan
+func Default() Calendar {
+	cal, err := New(DefaultTimezone, DefaultWeekStart)
+	if err != nil {
+		// Tidak terjadi selama time/tzdata di-embed
+		return Calendar{loc: time.FixedZone("WIB", 7*60*60), weekStart: DefaultWeekStart}
+	}
+	return cal
+}
+
+// Location mengembalikan zona waktu user
+func (c Calendar) Location() *time.Location {
+	if c.loc == nil {
+		return Default().loc
+	}
+	return c.loc
+}
+
+// WeekStart mengembalikan hari pertama dalam seminggu
+func (c Calendar) WeekStart() time.Weekday {
+	return c.weekStart
+}
+
+// Now mengembalikan waktu sekarang di zona waktu user
+func (c Calendar) Now() time.Time {
+	return time.Now().In(c.Location())
+}
+
+// StartOfDay mengembalikan pukul 00:00:00 pada tanggal t di zona waktu user
+func (c Calendar) StartOfDay(t time.Time) time.Time {
+	t = t.In(c.Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.Location())
+}
+
+// EndOfDay mengembalikan pukul 23:59:59 pada tanggal t di zona waktu user
+func (c Calendar) EndOfDay(t time.Time) time.Time {
+	t = t.In(c.Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, c.Location())
+}
+
+// ParseDate membaca tanggal "YYYY-MM-DD" sebagai awal hari di zona waktu user
+func (c Calendar) ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, value, c.Location())
+}
+
+// WeekRange mengembalikan awal (00:00:00) dan akhir (23:59:59 hari ketujuh)
+// minggu yang memuat t, sesuai hari pertama minggu user.
+func (c Calendar) WeekRange(t time.Time) (time.Time, time.Time) {
+	start := c.WeekStartOf(t)
+	return start, c.EndOfDay(start.AddDate(0, 0, 6))
+}
+
+// WeekStartOf mengembalikan awal minggu yang memuat t
+func (c Calendar) WeekStartOf(t time.Time) time.Time {
+	day := c.StartOfDay(t)
+	offset := (int(day.Weekday()) - int(c.weekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// WeekLabel memberi label minggu yang memuat t, misal "2026-W42".
+// Minggu milik tahun tempat hari ke-4 minggu itu jatuh, sehingga untuk
+// awal minggu Senin hasilnya sama dengan nomor minggu ISO 8601.
+func (c Calendar) WeekLabel(t time.Time) string {
+	anchor := c.WeekStartOf(t).AddDate(0, 0, 3)
+	week := (anchor.YearDay()-1)/7 + 1
+	return fmt.Sprintf("%d-W%02d", anchor.Year(), week)
+}
+
+// PeriodRange menghitung awal dan akhir periode budget (inklusif, akhir = 23:59:59)
+// di zona waktu user. Untuk 'custom', customEnd wajib diisi dan tidak boleh sebelum start.
+func (c Calendar) PeriodRange(periodType string, start time.Time, customEnd time.Time) (time.Time, time.Time, error) {
+	start = c.StartOfDay(start)
+
+	var lastDay time.Time
+	switch periodType {
+	case PeriodWeekly:
+		lastDay = start.AddDate(0, 0, 6)
+	case PeriodBiweekly:
+		lastDay = start.AddDate(0, 0, 13)
+	case PeriodMonthly:
+		lastDay = AddMonthsClamped(start, 1).AddDate(0, 0, -1)
+	case PeriodCustom:
+		if customEnd.IsZero() {
+			return time.Time{}, time.Time{}, fmt.Errorf("end_date wajib diisi untuk periode custom")
+		}
+		lastDay = c.StartOfDay(customEnd)
+		if lastDay.Before(start) {
+			return time.Time{}, time.Time{}, fmt.Errorf("end_date tidak boleh sebelum start_date")
+		}
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("period_type tidak dikenal: %q", periodType)
+	}
+
+	return start, c.EndOfDay(lastDay), nil
+}
+
+// AddMonthsClamped menambah n bulan; tanggal dipotong ke akhir bulan
+// jika bulan tujuan lebih pendek (31 Jan + 1 bulan = 28/29 Feb).
+func AddMonthsClamped(t time.Time, n int) time.Time {
+	firstOfTarget := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
@@ -0,0 +1,200 @@
+// Package categorizer menebak kategori item dari namanya. Aturan user dikompilasi
+// sekali menjadi RuleSet (pemanggil boleh menyimpannya di cache); riwayat kategori
+// dimuat oleh pemanggil dan hanya dibutuhkan jika tidak ada aturan yang cocok.
+package categorizer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// Sumber saran kategori
+const (
+	SourceRule    = "rule"
+	SourceHistory = "history"
+)
+
+// RuleSet adalah aturan user yang sudah diurutkan menurut prioritas dan regex-nya
+// sudah dikompilasi, sehingga bisa dipakai berulang (misal dari cache) tanpa kompilasi ulang.
+type RuleSet struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	model.CategoryRule
+	re      *regexp.Regexp // Hanya untuk aturan regex
+	keyword string         // Pattern ternormalisasi untuk aturan keyword
+}
+
+// Compile menyiapkan RuleSet dari aturan user. Aturan regex yang tidak bisa
+// dikompilasi dilewati (pattern sudah divalidasi saat aturan disimpan).
+func Compile(rules []model.CategoryRule) *RuleSet {
+	sorted := make([]model.CategoryRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	rs := &RuleSet{rules: make([]compiledRule, 0, len(sorted))}
+	for _, rule := range sorted {
+		cr := compiledRule{CategoryRule: rule}
+		if rule.MatchType == model.RuleMatchRegex {
+			re, err := compilePattern(rule.Pattern)
+			if err != nil {
+				continue
+			}
+			cr.re = re
+		} else {
+			cr.keyword = Normalize(rule.Pattern)
+			if cr.keyword == "" {
+				continue
+			}
+		}
+		rs.rules = append(rs.rules, cr)
+	}
+	return rs
+}
+
+// Len mengembalikan jumlah aturan yang bisa dipakai
+func (rs *RuleSet) Len() int {
+	if rs == nil {
+		return 0
+	}
+	return len(rs.rules)
+}
+
+// Match mengembalikan saran dari aturan pertama (prioritas tertinggi) yang cocok
+// dengan itemName, atau nil jika tidak ada.
+func (rs *RuleSet) Match(itemName string) *model.CategorySuggestion {
+	name := Normalize(itemName)
+	if name == "" || rs == nil {
+		return nil
+	}
+	for _, rule := range rs.rules {
+		if rule.matches(name) {
+			ruleID := rule.ID
+			return &model.CategorySuggestion{
+				CategoryID: rule.CategoryID,
+				Source:     SourceRule,
+				RuleID:     &ruleID,
+				Confidence: 1,
+			}
+		}
+	}
+	return nil
+}
+
+func (rule compiledRule) matches(name string) bool {
+	if rule.re != nil {
+		return rule.re.MatchString(name)
+	}
+	return strings.Contains(name, rule.keyword)
+}
+
+// Suggest memilih kategori untuk 'itemName'.
+// Urutan: aturan user (prioritas tertinggi dulu), lalu nama item yang persis sama
+// di riwayat, lalu voting per kata dari riwayat. Mengembalikan nil jika tidak ada yang cocok.
+func Suggest(itemName string, rules *RuleSet, history []model.CategoryAssignment) *model.CategorySuggestion {
+	if s := rules.Match(itemName); s != nil {
+		return s
+	}
+	return FromHistory(itemName, history)
+}
+
+// FromHistory menebak kategori hanya dari riwayat. Pemanggil yang sudah mencoba
+// RuleSet.Match bisa memuat riwayat hanya jika tidak ada aturan yang cocok.
+func FromHistory(itemName string, history []model.CategoryAssignment) *model.CategorySuggestion {
+	name := Normalize(itemName)
+	if name == "" {
+		return nil
+	}
+	return learnFromHistory(name, history)
+}
+
+// Normalize menyeragamkan nama item (huruf kecil, spasi tunggal) untuk pencocokan
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// ValidatePattern memeriksa apakah pattern aturan bisa dipakai
+func ValidatePattern(matchType, pattern string) error {
+	if matchType == model.RuleMatchRegex {
+		_, err := compilePattern(pattern)
+		return err
+	}
+	return nil
+}
+
+// compilePattern mengompilasi pattern regex aturan; pencocokan tidak membedakan huruf besar/kecil
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// learnFromHistory memakai kategori yang paling sering dipilih user untuk nama yang sama.
+// Jika tidak ada yang persis sama, setiap kata pada nama "memilih" kategori dari
+// item lama yang memuat kata tersebut, berbobot jumlah pemakaian.
+func learnFromHistory(name string, history []model.CategoryAssignment) *model.CategorySuggestion {
+	exact := map[int]int{}
+	exactTotal := 0
+	for _, h := range history {
+		if Normalize(h.ItemName) == name {
+			exact[h.CategoryID] += h.Count
+			exactTotal += h.Count
+		}
+	}
+	if id, votes := best(exact); votes > 0 {
+		return &model.CategorySuggestion{
+			CategoryID: id,
+			Source:     SourceHistory,
+			Confidence: float64(votes) / float64(exactTotal),
+		}
+	}
+
+	words := strings.Fields(name)
+	tokenVotes := map[int]int{}
+	total := 0
+	for _, h := range history {
+		known := strings.Fields(Normalize(h.ItemName))
+		for _, w := range words {
+			if len(w) < 3 || !contains(known, w) {
+				continue
+			}
+			tokenVotes[h.CategoryID] += h.Count
+			total += h.Count
+		}
+	}
+	if id, votes := best(tokenVotes); votes > 0 {
+		return &model.CategorySuggestion{
+			CategoryID: id,
+			Source:     SourceHistory,
+			Confidence: float64(votes) / float64(total),
+		}
+	}
+	return nil
+}
+
+// best mengembalikan kategori dengan suara terbanyak (ID terkecil jika seri)
+func best(votes map[int]int) (int, int) {
+	bestID, bestVotes := 0, 0
+	for id, v := range votes {
+		if v > bestVotes || (v == bestVotes && id < bestID) {
+			bestID, bestVotes = id, v
+		}
+	}
+	return bestID, bestVotes
+}
+
+func contains(words []string, w string) bool {
+	for _, x := range words {
+		if x == w {
+			return true
+		}
+	}
+	return false
+}
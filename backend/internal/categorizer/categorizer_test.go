@@ -0,0 +1,141 @@
+package categorizer
+
+import (
+	"testing"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+func TestRuleSetMatch(t *testing.T) {
+	rules := Compile([]model.CategoryRule{
+		{ID: 1, CategoryID: 10, MatchType: model.RuleMatchKeyword, Pattern: "susu", Priority: 0},
+		{ID: 2, CategoryID: 20, MatchType: model.RuleMatchKeyword, Pattern: "Susu  Bayi", Priority: 5},
+		{ID: 3, CategoryID: 30, MatchType: model.RuleMatchRegex, Pattern: `^minyak (goreng|sayur)`, Priority: 0},
+		{ID: 4, CategoryID: 40, MatchType: model.RuleMatchRegex, Pattern: `([`, Priority: 9},      // Tidak valid, dilewati
+		{ID: 5, CategoryID: 50, MatchType: model.RuleMatchKeyword, Pattern: "   ", Priority: 9},   // Kosong, dilewati
+		{ID: 6, CategoryID: 60, MatchType: model.RuleMatchKeyword, Pattern: "sabun", Priority: 1}, // Seri prioritas: ID kecil menang
+		{ID: 7, CategoryID: 70, MatchType: model.RuleMatchKeyword, Pattern: "sabun", Priority: 1},
+	})
+
+	if got := rules.Len(); got != 5 {
+		t.Errorf("Len() = %d, want 5", got)
+	}
+
+	tests := []struct {
+		name     string
+		itemName string
+		wantRule int // 0 berarti tidak ada yang cocok
+		wantCat  int
+	}{
+		{"keyword", "Susu UHT", 1, 10},
+		{"prioritas lebih tinggi menang", "susu bayi formula", 2, 20},
+		{"regex tidak membedakan huruf besar", "MINYAK Goreng 2L", 3, 30},
+		{"regex berjangkar", "beli minyak goreng", 0, 0},
+		{"prioritas seri", "Sabun Cuci", 6, 60},
+		{"tidak cocok", "beras", 0, 0},
+		{"nama kosong", "   ", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.Match(tt.itemName)
+			if tt.wantRule == 0 {
+				if got != nil {
+					t.Errorf("Match(%q) = %+v, want nil", tt.itemName, got)
+				}
+				return
+			}
+			if got == nil || got.RuleID == nil || *got.RuleID != tt.wantRule || got.CategoryID != tt.wantCat {
+				t.Errorf("Match(%q) = %+v, want rule %d category %d", tt.itemName, got, tt.wantRule, tt.wantCat)
+				return
+			}
+			if got.Source != SourceRule || got.Confidence != 1 {
+				t.Errorf("Match(%q) source = %s confidence = %v", tt.itemName, got.Source, got.Confidence)
+			}
+		})
+	}
+
+	var empty *RuleSet
+	if empty.Len() != 0 || empty.Match("susu") != nil {
+		t.Errorf("nil RuleSet should match nothing")
+	}
+}
+
+func TestFromHistory(t *testing.T) {
+	history := []model.CategoryAssignment{
+		{ItemName: "Telur Ayam", CategoryID: 1, Count: 3},
+		{ItemName: "telur ayam", CategoryID: 2, Count: 1},
+		{ItemName: "Daging Ayam", CategoryID: 2, Count: 4},
+		{ItemName: "Es Teh", CategoryID: 3, Count: 5},
+	}
+
+	tests := []struct {
+		name     string
+		itemName string
+		wantCat  int
+		wantConf float64
+	}{
+		{"nama persis sama", "TELUR  ayam", 1, 0.75},
+		{"voting per kata", "ayam goreng", 2, 0.625},
+		{"kata pendek diabaikan", "es jeruk", 0, 0},
+		{"tidak dikenal", "sabun", 0, 0},
+		{"nama kosong", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromHistory(tt.itemName, history)
+			if tt.wantCat == 0 {
+				if got != nil {
+					t.Errorf("FromHistory(%q) = %+v, want nil", tt.itemName, got)
+				}
+				return
+			}
+			if got == nil || got.CategoryID != tt.wantCat || got.Confidence != tt.wantConf || got.Source != SourceHistory {
+				t.Errorf("FromHistory(%q) = %+v, want category %d confidence %v", tt.itemName, got, tt.wantCat, tt.wantConf)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	rules := Compile([]model.CategoryRule{
+		{ID: 1, CategoryID: 10, MatchType: model.RuleMatchKeyword, Pattern: "ayam"},
+	})
+	history := []model.CategoryAssignment{{ItemName: "Telur Ayam", CategoryID: 2, Count: 4}}
+
+	tests := []struct {
+		name       string
+		rules      *RuleSet
+		itemName   string
+		wantCat    int
+		wantSource string
+	}{
+		{"aturan didahulukan", rules, "telur ayam", 10, SourceRule},
+		{"tanpa aturan memakai riwayat", nil, "telur ayam", 2, SourceHistory},
+		{"aturan tidak cocok memakai riwayat", rules, "telur puyuh", 2, SourceHistory},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Suggest(tt.itemName, tt.rules, history)
+			if got == nil || got.CategoryID != tt.wantCat || got.Source != tt.wantSource {
+				t.Errorf("Suggest(%q) = %+v, want category %d from %s", tt.itemName, got, tt.wantCat, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		matchType string
+		pattern   string
+		wantErr   bool
+	}{
+		{model.RuleMatchRegex, `^susu\s+\w+`, false},
+		{model.RuleMatchRegex, `([`, true},
+		{model.RuleMatchKeyword, `([`, false},
+	}
+	for _, tt := range tests {
+		if err := ValidatePattern(tt.matchType, tt.pattern); (err != nil) != tt.wantErr {
+			t.Errorf("ValidatePattern(%s, %q) error = %v, wantErr %v", tt.matchType, tt.pattern, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package chart menggambar grafik sederhana (pie dan batang) ke image.RGBA
+// memakai pustaka standar saja, untuk disematkan ke laporan unduhan.
+// Gambar tidak berisi teks; label ditulis oleh pemanggil di atas/sekitar gambar.
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Palette adalah urutan warna potongan pie / batang; dipakai berulang jika data lebih banyak
+var Palette = []color.RGBA{
+	{54, 162, 235, 255},
+	{255, 99, 132, 255},
+	{255, 206, 86, 255},
+	{75, 192, 192, 255},
+	{153, 102, 255, 255},
+	{255, 159, 64, 255},
+	{46, 204, 113, 255},
+	{149, 165, 166, 255},
+}
+
+// ColorAt mengembalikan warna palet untuk indeks data ke-i
+func ColorAt(i int) color.RGBA {
+	return Palette[i%len(Palette)]
+}
+
+var (
+	background = color.RGBA{255, 255, 255, 255}
+	gridColor  = color.RGBA{225, 225, 225, 255}
+	axisColor  = color.RGBA{120, 120, 120, 255}
+)
+
+// Pie menggambar diagram lingkaran berukuran size x size piksel. Potongan dimulai dari
+// arah jam 12 searah jarum jam, berwarna sesuai ColorAt. Nilai <= 0 diabaikan.
+func Pie(values []float64, size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+
+	total := 0.0
+	for _, v := range values {
+		if v > 0 {
+			total += v
+		}
+	}
+	if total == 0 {
+		return img
+	}
+
+	// Batas sudut kumulatif tiap potongan (0..1 putaran)
+	bounds := make([]float64, len(values))
+	acc := 0.0
+	for i, v := range values {
+		if v > 0 {
+			acc += v / total
+		}
+		bounds[i] = acc
+	}
+
+	center := float64(size) / 2
+	radius := center - 1
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx := float64(x) + 0.5 - center
+			dy := float64(y) + 0.5 - center
+			if math.Hypot(dx, dy) > radius {
+				continue
+			}
+			// Sudut dari arah jam 12, searah jarum jam (sumbu y gambar mengarah ke bawah)
+			turn := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if turn < 0 {
+				turn++
+			}
+			for i, b := range bounds {
+				if turn <= b && values[i] > 0 {
+					img.SetRGBA(x, y, ColorAt(i))
+					break
+				}
+			}
+		}
+	}
+	return img
+}
+
+// Bar menggambar diagram batang berukuran width x height piksel. Setiap nilai menempati
+// satu slot selebar width/len(values) dengan batang di tengahnya; tinggi batang relatif
+// terhadap maxValue (nilai terbesar jika maxValue <= 0). Garis bantu dibuat tiap 1/4 tinggi.
+func Bar(values []float64, maxValue float64, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+
+	for i := 1; i <= 4; i++ {
+		y := height - 1 - (height-1)*i/4
+		fill(img, image.Rect(0, y, width, y+1), gridColor)
+	}
+	fill(img, image.Rect(0, height-2, width, height), axisColor)
+
+	if len(values) == 0 {
+		return img
+	}
+	if maxValue <= 0 {
+		for _, v := range values {
+			maxValue = math.Max(maxValue, v)
+		}
+	}
+	if maxValue <= 0 {
+		return img
+	}
+
+	slot := float64(width) / float64(len(values))
+	for i, v := range values {
+		if v <= 0 {
+			continue
+		}
+		barHeight := int(math.Round(math.Min(v/maxValue, 1) * float64(height-2)))
+		x0 := int(math.Round(slot*float64(i) + slot*0.2))
+		x1 := int(math.Round(slot*float64(i+1) - slot*0.2))
+		fill(img, image.Rect(x0, height-2-barHeight, x1, height-2), ColorAt(0))
+	}
+	return img
+}
+
+// NiceMax membulatkan nilai ke atas menjadi 1, 2, 2.5 atau 5 kali pangkat 10,
+// agar label sumbu grafik batang mudah dibaca
+func NiceMax(v float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if v <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
@@ -1,12 +1,85 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // JWTSecretKey adalah kunci rahasia global untuk JWT.
 // Diambil dari environment variable untuk keamanan,
 // dengan fallback ke nilai default jika tidak diset.
 var JWTSecretKey = getJWTSecret()
 
+// RecurringInterval adalah jeda antar pengecekan template berulang oleh scheduler.
+var RecurringInterval = getDuration("RECURRING_INTERVAL", time.Hour)
+
+// TrashRetention adalah lama data disimpan di trash sebelum dihapus permanen.
+var TrashRetention = getDuration("TRASH_RETENTION", 30*24*time.Hour)
+
+// TrashPurgeInterval adalah jeda antar eksekusi retention job trash.
+var TrashPurgeInterval = getDuration("TRASH_PURGE_INTERVAL", 24*time.Hour)
+
+// SavingsSweepInterval adalah jeda antar pengecekan budget yang sudah tutup periode
+// untuk disisihkan ke tujuan tabungan auto-sweep.
+var SavingsSweepInterval = getDuration("SAVINGS_SWEEP_INTERVAL", time.Hour)
+
+// AnomalyScanInterval adalah jeda antar batch deteksi anomali belanja (default sekali sehari).
+var AnomalyScanInterval = getDuration("ANOMALY_SCAN_INTERVAL", 24*time.Hour)
+
+// ReportMailInterval adalah jeda antar pengecekan jadwal laporan email. Jadwal
+// disimpan per menit, jadi interval yang lebih panjang membuat kiriman terlambat.
+var ReportMailInterval = getDuration("REPORT_MAIL_INTERVAL", time.Minute)
+
+// StreamHeartbeat adalah jeda antar heartbeat di koneksi /api/v1/stream agar proxy
+// tidak menutup koneksi yang sedang sepi.
+var StreamHeartbeat = getDuration("STREAM_HEARTBEAT", 15*time.Second)
+
+// StreamHistorySize adalah jumlah event terakhir yang disimpan untuk resume dengan Last-Event-ID.
+const StreamHistorySize = 1024
+
+// StreamPGBridge mengaktifkan bridge LISTEN/NOTIFY PostgreSQL untuk stream. Wajib
+// diaktifkan jika backend berjalan lebih dari satu replika.
+var StreamPGBridge = getBool("STREAM_PG_BRIDGE", false)
+
+// RuleCacheTTL adalah umur maksimal aturan kategori yang di-cache per household.
+// Perubahan aturan di replika ini langsung berlaku; TTL membatasi basinya cache
+// jika aturan diubah lewat replika lain.
+var RuleCacheTTL = getDuration("RULE_CACHE_TTL", 5*time.Minute)
+
+// InvitationTTL adalah masa berlaku bawaan link undangan household.
+var InvitationTTL = getDuration("INVITATION_TTL", 7*24*time.Hour)
+
+// InvitationMaxTTL adalah batas masa berlaku yang boleh diminta saat membuat undangan.
+const InvitationMaxTTL = 30 * 24 * time.Hour
+
+// InvitationBaseURL adalah alamat halaman frontend untuk menerima undangan;
+// token ditambahkan di belakangnya, misal https://bmg.example/undangan/<token>.
+var InvitationBaseURL = getString("INVITATION_BASE_URL", "http://localhost:3000/undangan/")
+
+// DefaultCategories adalah kategori yang dibuat otomatis saat user mendaftar.
+// Bisa diganti lewat DEFAULT_CATEGORIES (dipisah koma); isi "-" untuk menonaktifkan.
+var DefaultCategories = getList("DEFAULT_CATEGORIES", []string{
+	"Sayur & Buah",
+	"Daging & Ikan",
+	"Bumbu Dapur",
+	"Sembako",
+	"Minuman",
+	"Camilan",
+	"Kebutuhan Rumah Tangga",
+	"Lainnya",
+})
+
+// Konfigurasi SMTP untuk notifikasi email. Jika SMTP_ADDR (host:port) kosong,
+// email tidak dikirim ke luar dan hanya ditampung secara lokal.
+var (
+	SMTPAddr     = os.Getenv("SMTP_ADDR")
+	SMTPUsername = os.Getenv("SMTP_USERNAME")
+	SMTPPassword = os.Getenv("SMTP_PASSWORD")
+	MailFrom     = getString("MAIL_FROM", "no-reply@bmg.local")
+)
+
 func getJWTSecret() []byte {
 	// Best practice: Ambil secret dari environment variable
 	secret := os.Getenv("JWT_SECRET_KEY")
@@ -17,3 +90,54 @@ func getJWTSecret() []byte {
 	}
 	return []byte(secret)
 }
+
+// getString membaca string dari environment variable dengan fallback jika kosong.
+func getString(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration membaca durasi (misal "30m", "1h") dari environment variable,
+// dengan fallback jika tidak diset atau formatnya tidak valid.
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// getBool membaca boolean ("true", "1", "false", "0", ...) dari environment variable,
+// dengan fallback jika tidak diset atau formatnya tidak valid.
+func getBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getList membaca daftar nilai yang dipisah koma dari environment variable.
+// Nilai "-" menghasilkan daftar kosong.
+func getList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	if value == "-" {
+		return nil
+	}
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
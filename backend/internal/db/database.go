@@ -12,17 +12,22 @@ import (
 // DB adalah koneksi database global yang akan digunakan oleh repositories
 var DB *sql.DB
 
-// ConnectDB initializes the database connection
-func ConnectDB() error {
-
-	// Construct connection string
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+// ConnString menyusun connection string dari environment variable. Juga dipakai
+// untuk koneksi LISTEN/NOTIFY yang terpisah dari pool DB.
+func ConnString() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_PORT"),
 		os.Getenv("DB_USER"),
 		os.Getenv("DB_PASSWORD"),
 		os.Getenv("DB_NAME"),
 	)
+}
+
+// ConnectDB initializes the database connection
+func ConnectDB() error {
+
+	connStr := ConnString()
 
 	// NOTE: Pastikan di .env, DB_HOST diatur ke 'db' (nama service Docker Compose)
 	database, err := sql.Open("postgres", connStr)
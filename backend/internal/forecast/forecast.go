@@ -0,0 +1,153 @@
+// Package forecast memproyeksikan total belanja di akhir periode budget.
+// Project hanya bekerja dengan angka yang diberikan lewat Input; belanja periode
+// berjalan dan riwayatnya dimuat oleh handler dashboard.
+package forecast
+
+import (
+	"math"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// HistoryDays adalah panjang riwayat (sebelum periode berjalan) yang dipakai
+// untuk menghitung pola belanja per hari dalam seminggu.
+const HistoryDays = 56
+
+// Metode proyeksi sisa periode
+const (
+	MethodPace    = "pace"    // Hanya laju belanja periode berjalan
+	MethodBlended = "blended" // Rata-rata laju dan pola hari yang sama di riwayat
+)
+
+// Input adalah data yang dibutuhkan untuk proyeksi. Semua tanggal dibandingkan per hari kalender.
+type Input struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Today       time.Time
+	Budget      float64 // Budget efektif periode berjalan
+	SpentToDate float64 // Belanja 'purchased' sejak awal periode sampai hari ini
+	Planned     float64 // Item 'planned' yang belum dibeli dalam periode
+
+	// Belanja harian sebelum periode berjalan, dalam rentang [HistoryStart, PeriodStart).
+	// Hari tanpa belanja boleh tidak disertakan.
+	History      []model.SpendingByDay
+	HistoryStart time.Time
+}
+
+// Result adalah hasil proyeksi untuk periode berjalan
+type Result struct {
+	PeriodStart      time.Time `json:"start_date"`
+	PeriodEnd        time.Time `json:"end_date"`
+	Budget           float64   `json:"budget"`
+	SpentToDate      float64   `json:"total_belanja"`
+	Planned          float64   `json:"total_rencana"`
+	DaysElapsed      int       `json:"hari_berjalan"`
+	DaysRemaining    int       `json:"hari_tersisa"`         // Termasuk hari ini
+	PaceEstimate     float64   `json:"estimasi_laju"`        // Perkiraan belanja sisa periode dari laju saat ini
+	PatternEstimate  float64   `json:"estimasi_pola_harian"` // Perkiraan dari pola hari yang sama di riwayat
+	Method           string    `json:"metode"`
+	ProjectedTotal   float64   `json:"proyeksi_total"`
+	ProjectedOverrun float64   `json:"proyeksi_kelebihan"` // 0 jika diproyeksikan masih dalam budget
+	SafeToSpendDaily float64   `json:"aman_dibelanjakan_per_hari"`
+	OnTrack          bool      `json:"on_track"`
+}
+
+// Project menghitung proyeksi belanja akhir periode.
+//
+// Sisa periode (hari setelah hari ini) diperkirakan dengan dua cara: laju rata-rata
+// per hari sejak awal periode, dan rata-rata belanja per hari-dalam-seminggu dari riwayat.
+// Jika riwayat tersedia, keduanya dirata-rata. Item 'planned' ditambahkan di atasnya.
+// Aman dibelanjakan per hari = (budget - belanja - planned) dibagi hari tersisa termasuk hari ini.
+func Project(in Input) Result {
+	start := dateOnly(in.PeriodStart)
+	end := dateOnly(in.PeriodEnd)
+	today := dateOnly(in.Today)
+	if today.Before(start) {
+		today = start
+	}
+	if today.After(end) {
+		today = end
+	}
+
+	totalDays := daysBetween(start, end) + 1
+	elapsed := daysBetween(start, today) + 1 // Hari ini dihitung sudah berjalan
+	futureDays := totalDays - elapsed        // Hari setelah hari ini
+
+	res := Result{
+		PeriodStart:   start,
+		PeriodEnd:     end,
+		Budget:        in.Budget,
+		SpentToDate:   in.SpentToDate,
+		Planned:       in.Planned,
+		DaysElapsed:   elapsed,
+		DaysRemaining: futureDays + 1,
+		Method:        MethodPace,
+	}
+
+	res.PaceEstimate = in.SpentToDate / float64(elapsed) * float64(futureDays)
+	unplanned := res.PaceEstimate
+
+	if averages, ok := weekdayAverages(in.History, dateOnly(in.HistoryStart), start); ok {
+		for d := today.AddDate(0, 0, 1); !d.After(end); d = d.AddDate(0, 0, 1) {
+			res.PatternEstimate += averages[d.Weekday()]
+		}
+		unplanned = (res.PaceEstimate + res.PatternEstimate) / 2
+		res.Method = MethodBlended
+	}
+
+	res.ProjectedTotal = round2(in.SpentToDate + in.Planned + unplanned)
+	res.ProjectedOverrun = round2(math.Max(0, res.ProjectedTotal-in.Budget))
+	res.OnTrack = res.ProjectedOverrun == 0
+	res.SafeToSpendDaily = round2(math.Max(0, (in.Budget-in.SpentToDate-in.Planned)/float64(res.DaysRemaining)))
+	res.PaceEstimate = round2(res.PaceEstimate)
+	res.PatternEstimate = round2(res.PatternEstimate)
+	return res
+}
+
+// weekdayAverages menghitung rata-rata belanja per hari-dalam-seminggu pada [from, to).
+// Hari tanpa belanja dihitung sebagai 0. Mengembalikan false jika riwayat kosong.
+func weekdayAverages(history []model.SpendingByDay, from, to time.Time) ([7]float64, bool) {
+	var totals, counts [7]float64
+	if !from.Before(to) || len(history) == 0 {
+		return totals, false
+	}
+
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		counts[d.Weekday()]++
+	}
+	found := false
+	for _, h := range history {
+		day := dateOnly(h.Tanggal)
+		if day.Before(from) || !day.Before(to) {
+			continue
+		}
+		totals[day.Weekday()] += h.Total
+		found = true
+	}
+	if !found {
+		return totals, false
+	}
+
+	for i := range totals {
+		if counts[i] > 0 {
+			totals[i] /= counts[i]
+		}
+	}
+	return totals, true
+}
+
+// dateOnly mengambil tanggal kalender t (di zonanya sendiri) sebagai tengah malam UTC,
+// sehingga selisih hari tidak terpengaruh pergantian zona waktu
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween menghitung selisih hari kalender antara dua tanggal hasil dateOnly
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
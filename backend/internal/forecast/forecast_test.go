@@ -0,0 +1,100 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestProject(t *testing.T) {
+	// Periode Senin 5 Okt - Minggu 11 Okt 2026 (7 hari)
+	start, end := day("2026-10-05"), day("2026-10-11")
+
+	tests := []struct {
+		name string
+		in   Input
+		want Result
+	}{
+		{
+			name: "laju periode berjalan tanpa riwayat",
+			in:   Input{PeriodStart: start, PeriodEnd: end, Today: day("2026-10-07"), Budget: 1000, SpentToDate: 300},
+			want: Result{
+				DaysElapsed: 3, DaysRemaining: 5, PaceEstimate: 400, Method: MethodPace,
+				ProjectedTotal: 700, SafeToSpendDaily: 140, OnTrack: true,
+			},
+		},
+		{
+			name: "item planned menambah proyeksi dan mengurangi jatah harian",
+			in:   Input{PeriodStart: start, PeriodEnd: end, Today: day("2026-10-07"), Budget: 800, SpentToDate: 300, Planned: 200},
+			want: Result{
+				DaysElapsed: 3, DaysRemaining: 5, PaceEstimate: 400, Method: MethodPace,
+				ProjectedTotal: 900, ProjectedOverrun: 100, SafeToSpendDaily: 60,
+			},
+		},
+		{
+			name: "hari ini sebelum periode dihitung sebagai hari pertama",
+			in:   Input{PeriodStart: start, PeriodEnd: end, Today: day("2026-10-01"), Budget: 700},
+			want: Result{
+				DaysElapsed: 1, DaysRemaining: 7, Method: MethodPace,
+				SafeToSpendDaily: 100, OnTrack: true,
+			},
+		},
+		{
+			name: "periode sudah lewat",
+			in:   Input{PeriodStart: start, PeriodEnd: end, Today: day("2026-10-20"), Budget: 500, SpentToDate: 700},
+			want: Result{
+				DaysElapsed: 7, DaysRemaining: 1, Method: MethodPace,
+				ProjectedTotal: 700, ProjectedOverrun: 200,
+			},
+		},
+		{
+			name: "pola hari dari riwayat dirata-rata dengan laju",
+			in: Input{
+				PeriodStart: start, PeriodEnd: end, Today: day("2026-10-07"), Budget: 1000, SpentToDate: 300,
+				// Dua minggu riwayat; hanya Sabtu yang ada belanja (rata-rata 200)
+				HistoryStart: day("2026-09-21"),
+				History: []model.SpendingByDay{
+					{Tanggal: day("2026-09-26"), Total: 100},
+					{Tanggal: day("2026-10-03"), Total: 300},
+				},
+			},
+			want: Result{
+				DaysElapsed: 3, DaysRemaining: 5, PaceEstimate: 400, PatternEstimate: 200, Method: MethodBlended,
+				ProjectedTotal: 600, SafeToSpendDaily: 140, OnTrack: true,
+			},
+		},
+		{
+			name: "riwayat di luar rentang diabaikan",
+			in: Input{
+				PeriodStart: start, PeriodEnd: end, Today: day("2026-10-07"), Budget: 1000, SpentToDate: 300,
+				HistoryStart: day("2026-09-21"),
+				History:      []model.SpendingByDay{{Tanggal: day("2026-10-06"), Total: 500}},
+			},
+			want: Result{
+				DaysElapsed: 3, DaysRemaining: 5, PaceEstimate: 400, Method: MethodPace,
+				ProjectedTotal: 700, SafeToSpendDaily: 140, OnTrack: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Project(tt.in)
+			want := tt.want
+			want.PeriodStart, want.PeriodEnd = start, end
+			want.Budget, want.SpentToDate, want.Planned = tt.in.Budget, tt.in.SpentToDate, tt.in.Planned
+			if got != want {
+				t.Errorf("Project() =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
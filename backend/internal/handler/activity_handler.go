@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// ActivityHandler menangani feed aktivitas user dan kueri log audit untuk admin.
+type ActivityHandler struct {
+	repo *repository.EventRepository
+}
+
+// NewActivityHandler membuat instance ActivityHandler baru.
+func NewActivityHandler(repo *repository.EventRepository) *ActivityHandler {
+	return &ActivityHandler{repo: repo}
+}
+
+// ======================================================================
+// ACTIVITY FEED (GET /api/v1/activity?entitas=item&aksi=update&page=1&limit=10)
+// ======================================================================
+// GetActivity mengembalikan aktivitas user sendiri (terbaru lebih dulu), termasuk login.
+func (h *ActivityHandler) GetActivity(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit, ok := helper.GetPagination(c)
+	if !ok {
+		return
+	}
+
+	filter, errs := parseEventFilter(c, false)
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter aktivitas tidak valid", "details": errs})
+		return
+	}
+	filter.ActorID = userID
+
+	h.respondEvents(c, filter, page, limit)
+}
+
+// ======================================================================
+// AUDIT LOG (GET /api/v1/admin/audit?id_actor=1&entitas=budget&id_entitas=7&request_id=...)
+// ======================================================================
+// GetAuditLog mengembalikan log audit seluruh user untuk admin. Semua filter opsional;
+// from/to (YYYY-MM-DD) dibaca dengan kalender admin.
+func (h *ActivityHandler) GetAuditLog(c *gin.Context) {
+	page, limit, ok := helper.GetPagination(c)
+	if !ok {
+		return
+	}
+
+	filter, errs := parseEventFilter(c, true)
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter audit tidak valid", "details": errs})
+		return
+	}
+
+	h.respondEvents(c, filter, page, limit)
+}
+
+func (h *ActivityHandler) respondEvents(c *gin.Context, filter model.EventFilter, page, limit int) {
+	events, total, err := h.repo.GetEvents(c.Request.Context(), filter, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[ActivityHandler] Gagal mengambil log aktivitas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil log aktivitas"})
+		return
+	}
+
+	if events == nil {
+		events = []model.Event{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       events,
+		"pagination": model.Pagination{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// parseEventFilter membaca filter aksi, entitas, id_entitas dan from/to. Filter id_actor
+// dan request_id hanya dibaca untuk kueri admin.
+func parseEventFilter(c *gin.Context, admin bool) (model.EventFilter, []model.FieldError) {
+	var errs []model.FieldError
+	f := model.EventFilter{Action: c.Query("aksi"), Entity: c.Query("entitas")}
+
+	if f.Action != "" && !model.ValidEventAction(f.Action) {
+		errs = append(errs, model.FieldError{Field: "aksi", Message: "Harus salah satu dari: create, update, delete, login, login_failed"})
+	}
+	if f.Entity != "" && !model.ValidEventEntity(f.Entity) {
+		errs = append(errs, model.FieldError{Field: "entitas", Message: "Harus salah satu dari: item, category, budget, user"})
+	}
+
+	positiveInt := func(field string, dst *int) {
+		if v := c.Query(field); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				errs = append(errs, model.FieldError{Field: field, Message: "Harus bilangan bulat positif"})
+				return
+			}
+			*dst = n
+		}
+	}
+	positiveInt("id_entitas", &f.EntityID)
+	if admin {
+		positiveInt("id_actor", &f.ActorID)
+		f.RequestID = c.Query("request_id")
+	}
+
+	cal := helper.GetCalendar(c)
+	if v := c.Query("from"); v != "" {
+		from, err := cal.ParseDate(v)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "from", Message: "Format tanggal harus YYYY-MM-DD"})
+		}
+		f.From = from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := cal.ParseDate(v)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "to", Message: "Format tanggal harus YYYY-MM-DD"})
+		} else {
+			f.To = cal.EndOfDay(to)
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		errs = append(errs, model.FieldError{Field: "to", Message: "Tidak boleh sebelum from"})
+	}
+	return f, errs
+}
+
+// auditEvent menyiapkan event audit untuk request ini (actor, request ID dan IP klien).
+// entityID 0 berarti event tidak terkait satu entitas tertentu.
+func auditEvent(c *gin.Context, actorID int, action, entity string, entityID int) model.Event {
+	e := model.Event{
+		Action:    action,
+		Entity:    entity,
+		RequestID: helper.GetRequestID(c),
+		IPAddress: c.ClientIP(),
+	}
+	if actorID != 0 {
+		e.ActorID = &actorID
+	}
+	if entityID != 0 {
+		e.EntityID = &entityID
+	}
+	return e
+}
+
+// recordEvent menyimpan event ke log audit. Seperti peringatan budget, kegagalan
+// hanya dicatat ke log agar tidak menggagalkan operasi yang sudah berhasil.
+func recordEvent(c *gin.Context, audit *service.AuditService, e model.Event, before, after any) {
+	if err := audit.Record(c.Request.Context(), e, before, after); err != nil {
+		log.Printf("[Audit] Gagal mencatat event %s %s: %v", e.Action, e.Entity, err)
+	}
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/inflation"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// defaultAnalyticsDays adalah rentang bawaan analitik jika from/to tidak diisi
+const defaultAnalyticsDays = 90
+
+// AnalyticsHandler menangani endpoint analitik pola belanja
+type AnalyticsHandler struct {
+	repo      *repository.AnalyticsRepository
+	inflation *service.InflationService
+}
+
+// NewAnalyticsHandler membuat instance AnalyticsHandler baru
+func NewAnalyticsHandler(repo *repository.AnalyticsRepository, inflation *service.InflationService) *AnalyticsHandler {
+	return &AnalyticsHandler{repo: repo, inflation: inflation}
+}
+
+// analyticsParams adalah query param bersama untuk endpoint analitik
+type analyticsParams struct {
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// ======================================================================
+// FREQUENT ITEMS (GET /api/v1/analytics/items/frequent)
+// ======================================================================
+// GetFrequentItems mengembalikan item yang dibeli pada trip terbanyak.
+func (h *AnalyticsHandler) GetFrequentItems(c *gin.Context) {
+	h.topItems(c, model.TopItemsByFrequency)
+}
+
+// ======================================================================
+// TOP SPEND ITEMS (GET /api/v1/analytics/items/top-spend)
+// ======================================================================
+// GetTopSpendItems mengembalikan item dengan total belanja terbesar.
+func (h *AnalyticsHandler) GetTopSpendItems(c *gin.Context) {
+	h.topItems(c, model.TopItemsBySpend)
+}
+
+func (h *AnalyticsHandler) topItems(c *gin.Context, orderBy string) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	p, errs := parseAnalyticsParams(c, helper.GetCalendar(c))
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter analitik tidak valid", "details": errs})
+		return
+	}
+
+	items, err := h.repo.GetTopItems(c.Request.Context(), userID, p.From, p.To, orderBy, p.Limit)
+	if err != nil {
+		log.Printf("[AnalyticsHandler] Gagal mengambil item teratas: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil item teratas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items, "from": p.From, "to": p.To})
+}
+
+// ======================================================================
+// BASKETS (GET /api/v1/analytics/baskets)
+// ======================================================================
+// GetBaskets mengembalikan ukuran keranjang setiap trip dan rata-ratanya.
+func (h *AnalyticsHandler) GetBaskets(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	p, errs := parseAnalyticsParams(c, helper.GetCalendar(c))
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter analitik tidak valid", "details": errs})
+		return
+	}
+
+	summary, err := h.repo.GetBasketSummary(c.Request.Context(), userID, p.From, p.To)
+	if err != nil {
+		log.Printf("[AnalyticsHandler] Gagal menghitung keranjang belanja: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung keranjang belanja"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary, "from": p.From, "to": p.To})
+}
+
+// ======================================================================
+// ITEM PAIRS (GET /api/v1/analytics/items/pairs)
+// ======================================================================
+// GetItemPairs mengembalikan pasangan item yang sering dibeli pada hari yang sama.
+// ?min_count=N membatasi pasangan yang muncul bersama minimal N trip (default 2).
+func (h *AnalyticsHandler) GetItemPairs(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	p, errs := parseAnalyticsParams(c, helper.GetCalendar(c))
+	minCount, err := strconv.Atoi(c.DefaultQuery("min_count", "2"))
+	if err != nil || minCount < 1 {
+		errs = append(errs, model.FieldError{Field: "min_count", Message: "Harus bilangan bulat minimal 1"})
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter analitik tidak valid", "details": errs})
+		return
+	}
+
+	pairs, err := h.repo.GetItemPairs(c.Request.Context(), userID, p.From, p.To, minCount, p.Limit)
+	if err != nil {
+		log.Printf("[AnalyticsHandler] Gagal menghitung pasangan item: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung item yang dibeli bersamaan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pairs, "from": p.From, "to": p.To})
+}
+
+// ======================================================================
+// STAPLES (GET /api/v1/analytics/staples)
+// ======================================================================
+// GetStaples mengembalikan item rutin dan berapa hari sejak terakhir dibeli.
+// ?min_trips=N menentukan minimal jumlah trip agar item dianggap rutin (default 3).
+func (h *AnalyticsHandler) GetStaples(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	cal := helper.GetCalendar(c)
+	p, errs := parseAnalyticsParams(c, cal)
+	minTrips, err := strconv.Atoi(c.DefaultQuery("min_trips", "3"))
+	if err != nil || minTrips < 2 {
+		// Minimal dua trip agar jarak antar pembelian bisa dihitung
+		errs = append(errs, model.FieldError{Field: "min_trips", Message: "Harus bilangan bulat minimal 2"})
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter analitik tidak valid", "details": errs})
+		return
+	}
+
+	staples, err := h.repo.GetStaples(c.Request.Context(), userID, p.From, p.To, minTrips, cal.Now())
+	if err != nil {
+		log.Printf("[AnalyticsHandler] Gagal mengambil item rutin: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil item rutin"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": staples, "from": p.From, "to": p.To})
+}
+
+// ======================================================================
+// INFLATION (GET /api/v1/analytics/inflation?to=2026-10&months=24&base_months=12)
+// ======================================================================
+// GetInflation mengembalikan indeks harga pribadi bulanan (Laspeyres) beserta kontribusi
+// setiap kategori. 'to' adalah bulan terakhir (default bulan ini); 'base_months' bulan
+// pertama dari 'months' bulan menjadi periode dasar.
+func (h *AnalyticsHandler) GetInflation(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	cal := helper.GetCalendar(c)
+	var errs []model.FieldError
+	until := cal.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.ParseInLocation("2006-01", toStr, cal.Location())
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "to", Message: "Format bulan harus YYYY-MM"})
+		} else {
+			until = parsed
+		}
+	}
+	months, err := strconv.Atoi(c.DefaultQuery("months", strconv.Itoa(inflation.DefaultMonths)))
+	if err != nil || months < 2 || months > inflation.MaxMonths {
+		errs = append(errs, model.FieldError{Field: "months",
+			Message: fmt.Sprintf("Harus bilangan bulat antara 2 dan %d", inflation.MaxMonths)})
+	}
+	baseMonths, err := strconv.Atoi(c.DefaultQuery("base_months", strconv.Itoa(inflation.DefaultBaseMonths)))
+	if err != nil || baseMonths < 1 || (months >= 2 && baseMonths >= months) {
+		errs = append(errs, model.FieldError{Field: "base_months", Message: "Harus minimal 1 dan lebih kecil dari months"})
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter analitik tidak valid", "details": errs})
+		return
+	}
+
+	index, err := h.inflation.Index(c.Request.Context(), userID, cal, until, months, baseMonths)
+	if err != nil {
+		log.Printf("[AnalyticsHandler] Gagal menghitung indeks harga: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung indeks harga pribadi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": index})
+}
+
+// parseAnalyticsParams membaca from/to (default 90 hari terakhir) dan limit (default 10,
+// maksimal 100), lalu mengumpulkan semua kesalahan validasi
+func parseAnalyticsParams(c *gin.Context, cal calendar.Calendar) (analyticsParams, []model.FieldError) {
+	var errs []model.FieldError
+	now := cal.Now()
+	p := analyticsParams{
+		From: cal.StartOfDay(now).AddDate(0, 0, -(defaultAnalyticsDays - 1)),
+		To:   cal.EndOfDay(now),
+	}
+
+	fromStr, toStr := c.Query("from"), c.Query("to")
+	if fromStr != "" {
+		from, err := cal.ParseDate(fromStr)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "from", Message: "Format tanggal harus YYYY-MM-DD"})
+		} else {
+			p.From = from
+		}
+	}
+	if toStr != "" {
+		to, err := cal.ParseDate(toStr)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "to", Message: "Format tanggal harus YYYY-MM-DD"})
+		} else {
+			p.To = cal.EndOfDay(to)
+		}
+	}
+	if len(errs) == 0 && p.To.Before(p.From) {
+		errs = append(errs, model.FieldError{Field: "to", Message: "Tidak boleh sebelum from"})
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > 100 {
+		errs = append(errs, model.FieldError{Field: "limit", Message: "Harus bilangan bulat antara 1 dan 100"})
+	}
+	p.Limit = limit
+	return p, errs
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// AnomalyHandler menangani daftar dan tindak lanjut anomali belanja.
+type AnomalyHandler struct {
+	repo *repository.AnomalyRepository
+}
+
+// NewAnomalyHandler membuat instance AnomalyHandler baru.
+func NewAnomalyHandler(repo *repository.AnomalyRepository) *AnomalyHandler {
+	return &AnomalyHandler{repo: repo}
+}
+
+// ======================================================================
+// GET ANOMALIES (GET /api/v1/anomalies?status=open&page=1&limit=10)
+// ======================================================================
+// GetAnomalies mengembalikan anomali household aktif. Default hanya yang masih 'open';
+// status=all untuk semua status.
+func (h *AnomalyHandler) GetAnomalies(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit, ok := helper.GetPagination(c)
+	if !ok {
+		return
+	}
+
+	status := c.DefaultQuery("status", model.AnomalyOpen)
+	switch status {
+	case "all":
+		status = ""
+	case model.AnomalyOpen, model.AnomalyDismissed, model.AnomalyConfirmed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter status harus open, dismissed, confirmed atau all"})
+		return
+	}
+
+	anomalies, total, err := h.repo.GetAnomalies(c.Request.Context(), userID, status, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[AnomalyHandler] Gagal mengambil anomali: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anomali belanja"})
+		return
+	}
+
+	if anomalies == nil {
+		anomalies = []model.Anomaly{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       anomalies,
+		"pagination": model.Pagination{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// ======================================================================
+// DISMISS ANOMALY (POST /api/v1/anomalies/:id/dismiss)
+// ======================================================================
+// DismissAnomaly menandai anomali sebagai wajar; anomali yang sama tidak akan muncul lagi.
+func (h *AnomalyHandler) DismissAnomaly(c *gin.Context) {
+	h.resolve(c, model.AnomalyDismissed, "Anomali diabaikan")
+}
+
+// ======================================================================
+// CONFIRM ANOMALY (POST /api/v1/anomalies/:id/confirm)
+// ======================================================================
+// ConfirmAnomaly menandai anomali sebagai memang tidak wajar.
+func (h *AnomalyHandler) ConfirmAnomaly(c *gin.Context) {
+	h.resolve(c, model.AnomalyConfirmed, "Anomali dikonfirmasi")
+}
+
+func (h *AnomalyHandler) resolve(c *gin.Context, status string, message string) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	anomalyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anomali tidak valid"})
+		return
+	}
+
+	if err := h.repo.SetAnomalyStatus(c.Request.Context(), anomalyID, userID, status); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anomali tidak ditemukan"})
+			return
+		}
+		log.Printf("[AnomalyHandler] Gagal memperbarui status anomali: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui anomali"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
@@ -17,11 +17,12 @@ import (
 // AuthHandler holds the dependencies for authentication APIs
 type AuthHandler struct {
 	authService *service.AuthService
+	audit       *service.AuditService
 }
 
 // NewAuthHandler creates a new handler instance
-func NewAuthHandler() *AuthHandler {
-	return &AuthHandler{authService: service.NewAuthService()}
+func NewAuthHandler(audit *service.AuditService) *AuthHandler {
+	return &AuthHandler{authService: service.NewAuthService(), audit: audit}
 }
 
 // Register handles POST /v1/register
@@ -60,10 +61,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	if err != nil {
 		// Service akan mengembalikan error "username atau password salah"
 		log.Printf("Login gagal untuk user: %s, error: %v", req.Username, err)
+		recordEvent(c, h.audit, auditEvent(c, 0, model.EventLoginFailed, model.EntityUser, 0), nil, gin.H{"username": req.Username})
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
+	recordEvent(c, h.audit, auditEvent(c, loginResponse.UserID, model.EventLogin, model.EntityUser, loginResponse.UserID), nil, nil)
+
 	// 4. Kirim Token dari service sebagai Respons
 	// loginResponse sudah berisi Token dan Role
 	c.JSON(http.StatusOK, gin.H{
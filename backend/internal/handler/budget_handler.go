@@ -1,26 +1,38 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
 	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+	"github.com/gusti3111/TKBMG/backend/internal/stream"
 )
 
 // BudgetHandler menangani logika HTTP untuk Anggaran.
 type BudgetHandler struct {
-	repo *repository.BudgetRepository
+	repo             *repository.BudgetRepository
+	notificationRepo *repository.NotificationRepository
+	alerts           *service.AlertService
+	audit            *service.AuditService
+	hub              *stream.Hub
 }
 
 // NewBudgetHandler membuat instance BudgetHandler baru.
-func NewBudgetHandler(r *repository.BudgetRepository) *BudgetHandler {
-	return &BudgetHandler{repo: r}
+func NewBudgetHandler(r *repository.BudgetRepository, notificationRepo *repository.NotificationRepository, alerts *service.AlertService, audit *service.AuditService, hub *stream.Hub) *BudgetHandler {
+	return &BudgetHandler{repo: r, notificationRepo: notificationRepo, alerts: alerts, audit: audit, hub: hub}
 }
 
 // SetBudget menangani POST /api/v1/budgets
-// Ini akan memanggil 'UpsertBudgetForCurrentWeek' dari repository.
+// Jika hanya 'jumlah_anggaran' yang dikirim, budget minggu ini dibuat/diperbarui
+// (perilaku lama halaman "Set Budget"). Jika 'period_type' atau 'start_date' dikirim,
+// budget baru dibuat untuk periode tersebut, misal minggu atau bulan depan.
 func (h *BudgetHandler) SetBudget(c *gin.Context) {
 	// 1. Ambil UserID dari token
 	userID, ok := helper.GetUserID(c)
@@ -29,13 +41,166 @@ func (h *BudgetHandler) SetBudget(c *gin.Context) {
 	}
 
 	// 2. Bind JSON body
-	// Kita hanya perlu 'jumlah_anggaran' dari user.
-	// Repo Anda (UpsertBudgetForCurrentWeek) sudah pintar
-	// menghitung start_date dan end_date (minggu ini) secara otomatis.
-	var req struct {
-		Amount float64 `json:"jumlah_anggaran" binding:"required"`
+	var req model.BudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	if req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah anggaran harus lebih besar dari 0"})
+		return
+	}
+
+	// 3a. Perilaku lama: upsert budget minggu ini
+	if req.PeriodType == "" && req.StartDate == "" {
+		before, after, err := h.repo.UpsertBudgetForCurrentWeek(c.Request.Context(), userID, req.Amount, helper.GetCalendar(c))
+		if err != nil {
+			if errors.Is(err, repository.ErrOverlap) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Sudah ada budget lain yang mencakup minggu ini"})
+				return
+			}
+			if errors.Is(err, repository.ErrAllocationExceedsBudget) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Jumlah anggaran lebih kecil dari total alokasi kategori"})
+				return
+			}
+			log.Printf("[BudgetHandler] Gagal upsert budget: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan anggaran", "details": err.Error()})
+			return
+		}
+		action := model.EventUpdate
+		if before == nil {
+			action = model.EventCreate
+		}
+		recordEvent(c, h.audit, auditEvent(c, userID, action, model.EntityBudget, after.ID), before, after)
+		publishEvent(c, h.hub, userID, model.EntityBudget, action, after)
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":         "Anggaran untuk minggu ini berhasil disimpan/diperbarui",
+			"jumlah_anggaran": after.Amount,
+		})
+		return
+	}
+
+	// 3b. Budget dengan periode eksplisit
+	budget, ok := budgetFromRequest(c, req)
+	if !ok {
+		return
+	}
+	budget.UserID = userID
+
+	if err := h.repo.CreateBudget(c.Request.Context(), budget); err != nil {
+		if errors.Is(err, repository.ErrOverlap) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Periode budget bertabrakan dengan budget lain"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal membuat budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan anggaran"})
+		return
+	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventCreate, model.EntityBudget, budget.ID), nil, budget)
+	publishEvent(c, h.hub, userID, model.EntityBudget, model.EventCreate, budget)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Anggaran berhasil dibuat",
+		"data":    budget,
+	})
+}
+
+// GetBudgets menangani GET /api/v1/budgets?page=1&limit=10
+// Riwayat budget (terbaru lebih dulu) beserta realisasi pengeluaran tiap periode.
+func (h *BudgetHandler) GetBudgets(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit, ok := helper.GetPagination(c)
+	if !ok {
+		return
+	}
+
+	budgets, total, err := h.repo.GetBudgetHistory(c.Request.Context(), userID, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[BudgetHandler] Gagal mengambil riwayat budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar anggaran"})
+		return
+	}
+
+	if budgets == nil {
+		budgets = []model.BudgetStatus{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       budgets,
+		"pagination": model.Pagination{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// GetCurrentBudget menangani GET /api/v1/budgets/current
+// Budget yang aktif hari ini beserta realisasinya; 404 jika belum ada budget.
+func (h *BudgetHandler) GetCurrentBudget(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budget, err := h.repo.GetBudgetStatusByDate(c.Request.Context(), userID, helper.GetCalendar(c).Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Belum ada anggaran untuk periode ini"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal mengambil budget aktif: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anggaran"})
+		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"data": budget})
+}
+
+// GetBudget menangani GET /api/v1/budgets/:id
+func (h *BudgetHandler) GetBudget(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggaran tidak valid"})
+		return
+	}
+
+	budget, err := h.repo.GetBudgetStatusByID(c.Request.Context(), budgetID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran tidak ditemukan"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal mengambil budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anggaran"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": budget})
+}
+
+// UpdateBudget menangani PUT /api/v1/budgets/:id
+// Budget lampau juga boleh diubah, selama periodenya tidak bertabrakan.
+func (h *BudgetHandler) UpdateBudget(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggaran tidak valid"})
+		return
+	}
+
+	var req model.BudgetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
 		return
@@ -46,17 +211,297 @@ func (h *BudgetHandler) SetBudget(c *gin.Context) {
 		return
 	}
 
-	// 3. Panggil Repository (logika inti)
-	err := h.repo.UpsertBudgetForCurrentWeek(c.Request.Context(), userID, req.Amount)
+	// Data lama untuk log audit dan untuk melengkapi periode yang tidak dikirim
+	existing, err := h.repo.GetBudgetByID(c.Request.Context(), budgetID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil anggaran"})
+		return
+	}
+
+	// Periode tidak dikirim: pertahankan periode lama dan ubah jumlahnya saja
+	if req.PeriodType == "" || req.StartDate == "" {
+		if req.PeriodType == "" {
+			req.PeriodType = existing.PeriodType
+		}
+		if req.StartDate == "" {
+			req.StartDate = existing.StartDate.Format("2006-01-02")
+		}
+		if req.EndDate == "" {
+			req.EndDate = existing.EndDate.Format("2006-01-02")
+		}
+	}
+
+	budget, ok := budgetFromRequest(c, req)
+	if !ok {
+		return
+	}
+	budget.ID = budgetID
+	budget.UserID = userID
+
+	if err := h.repo.UpdateBudget(c.Request.Context(), budget); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran tidak ditemukan"})
+			return
+		}
+		if errors.Is(err, repository.ErrOverlap) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Periode budget bertabrakan dengan budget lain"})
+			return
+		}
+		if errors.Is(err, repository.ErrAllocationExceedsBudget) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Jumlah anggaran lebih kecil dari total alokasi kategori"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal memperbarui budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui anggaran"})
+		return
+	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventUpdate, model.EntityBudget, budgetID), existing, budget)
+	publishEvent(c, h.hub, userID, model.EntityBudget, model.EventUpdate, budget)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Anggaran berhasil diperbarui",
+		"data":    budget,
+	})
+}
+
+// DeleteBudget menangani DELETE /api/v1/budgets/:id
+// Budget dipindahkan ke trash dan bisa dipulihkan lewat /api/v1/trash.
+func (h *BudgetHandler) DeleteBudget(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		log.Printf("[BudgetHandler] Gagal upsert budget: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan anggaran", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggaran tidak valid"})
 		return
 	}
 
-	// 4. Kirim respons sukses
+	before, err := h.repo.GetBudgetByID(c.Request.Context(), budgetID, userID)
+	if err == nil {
+		err = h.repo.DeleteBudget(c.Request.Context(), budgetID, userID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran tidak ditemukan"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal menghapus budget: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus anggaran"})
+		return
+	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventDelete, model.EntityBudget, budgetID), before, nil)
+	publishEvent(c, h.hub, userID, model.EntityBudget, model.EventDelete, before)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Anggaran berhasil dihapus"})
+}
+
+// budgetFromRequest mengubah BudgetRequest menjadi model.Budget dengan periode yang sudah dihitung.
+// Mengirim respons 400 dan mengembalikan false jika input tidak valid.
+func budgetFromRequest(c *gin.Context, req model.BudgetRequest) (*model.Budget, bool) {
+	if req.PeriodType == "" {
+		req.PeriodType = model.PeriodWeekly
+	}
+
+	// Tanggal dibaca dan periode dihitung di zona waktu user
+	cal := helper.GetCalendar(c)
+	start := cal.Now()
+	if req.StartDate != "" {
+		parsed, err := cal.ParseDate(req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format start_date harus YYYY-MM-DD"})
+			return nil, false
+		}
+		start = parsed
+	}
+
+	var customEnd time.Time
+	if req.EndDate != "" && req.PeriodType == model.PeriodCustom {
+		parsed, err := cal.ParseDate(req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format end_date harus YYYY-MM-DD"})
+			return nil, false
+		}
+		customEnd = parsed
+	}
+
+	startDate, endDate, err := cal.PeriodRange(req.PeriodType, start, customEnd)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return &model.Budget{
+		PeriodType: req.PeriodType,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Amount:     req.Amount,
+	}, true
+}
+
+// GetAllocations menangani GET /api/v1/budgets/:id/allocations
+// Status setiap amplop kategori (dialokasikan, terpakai, sisa) termasuk pool "belum dialokasikan".
+func (h *BudgetHandler) GetAllocations(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggaran tidak valid"})
+		return
+	}
+
+	summary, err := h.repo.GetEnvelopeSummary(c.Request.Context(), budgetID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran tidak ditemukan"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal mengambil alokasi: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil alokasi anggaran"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// SetAllocations menangani PUT /api/v1/budgets/:id/allocations
+// Mengganti seluruh alokasi kategori untuk budget tersebut.
+func (h *BudgetHandler) SetAllocations(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggaran tidak valid"})
+		return
+	}
+
+	var req model.AllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = model.AllocationModeCap
+	}
+	if req.Mode != model.AllocationModeCap && req.Mode != model.AllocationModeSum {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "allocation_mode harus 'cap' atau 'sum'"})
+		return
+	}
+
+	seen := make(map[int]bool, len(req.Allocations))
+	for _, a := range req.Allocations {
+		if seen[a.CategoryID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Setiap kategori hanya boleh dialokasikan sekali"})
+			return
+		}
+		seen[a.CategoryID] = true
+	}
+
+	if err := h.repo.SetAllocations(c.Request.Context(), budgetID, userID, req.Mode, req.Allocations); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran atau kategori tidak ditemukan"})
+		case errors.Is(err, repository.ErrAllocationExceedsBudget):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Total alokasi melebihi jumlah anggaran"})
+		default:
+			log.Printf("[BudgetHandler] Gagal menyimpan alokasi: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan alokasi anggaran"})
+		}
+		return
+	}
+
+	summary, err := h.repo.GetEnvelopeSummary(c.Request.Context(), budgetID, userID)
+	if err != nil {
+		log.Printf("[BudgetHandler] Gagal mengambil alokasi: %v", err)
+		c.JSON(http.StatusOK, gin.H{"message": "Alokasi anggaran berhasil disimpan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alokasi anggaran berhasil disimpan", "data": summary})
+}
+
+// GetAlertThresholds menangani GET /api/v1/budgets/:id/alerts
+func (h *BudgetHandler) GetAlertThresholds(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggaran tidak valid"})
+		return
+	}
+
+	thresholds, err := h.notificationRepo.GetAlertThresholds(c.Request.Context(), budgetID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran tidak ditemukan"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal mengambil ambang peringatan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil ambang peringatan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": model.AlertThresholdRequest{Thresholds: thresholds}})
+}
+
+// SetAlertThresholds menangani PUT /api/v1/budgets/:id/alerts
+// Ambang dalam persen budget terpakai, misal [50, 80, 100]. Daftar kosong menonaktifkan peringatan.
+func (h *BudgetHandler) SetAlertThresholds(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	budgetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggaran tidak valid"})
+		return
+	}
+
+	var req model.AlertThresholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+
+	thresholds, valid := model.NormalizeThresholds(req.Thresholds)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ambang peringatan harus antara 1 dan 1000 persen"})
+		return
+	}
+
+	if err := h.notificationRepo.SetAlertThresholds(c.Request.Context(), budgetID, userID, thresholds); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Anggaran tidak ditemukan"})
+			return
+		}
+		log.Printf("[BudgetHandler] Gagal menyimpan ambang peringatan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan ambang peringatan"})
+		return
+	}
+
+	// Ambang baru mungkin sudah terlewati oleh belanja yang ada
+	if err := h.alerts.CheckBudget(c.Request.Context(), budgetID, userID); err != nil {
+		log.Printf("[BudgetHandler] Gagal memeriksa ambang peringatan: %v", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":         "Anggaran untuk minggu ini berhasil disimpan/diperbarui",
-		"jumlah_anggaran": req.Amount,
+		"message": "Ambang peringatan berhasil disimpan",
+		"data":    model.AlertThresholdRequest{Thresholds: thresholds},
 	})
 }
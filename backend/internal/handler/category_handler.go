@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -11,16 +12,21 @@ import (
 	"github.com/gusti3111/TKBMG/backend/internal/helper"
 	"github.com/gusti3111/TKBMG/backend/internal/model"
 	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+	"github.com/gusti3111/TKBMG/backend/internal/stream"
 )
 
 // CategoryHandler menangani logika HTTP untuk referensi_kategori.
 type CategoryHandler struct {
-	repo *repository.CategoryRepository
+	repo        *repository.CategoryRepository
+	categorizer *service.CategorizeService
+	audit       *service.AuditService
+	hub         *stream.Hub
 }
 
 // NewCategoryHandler membuat instance CategoryHandler baru.
-func NewCategoryHandler(r *repository.CategoryRepository) *CategoryHandler {
-	return &CategoryHandler{repo: r}
+func NewCategoryHandler(r *repository.CategoryRepository, categorizer *service.CategorizeService, audit *service.AuditService, hub *stream.Hub) *CategoryHandler {
+	return &CategoryHandler{repo: r, categorizer: categorizer, audit: audit, hub: hub}
 }
 
 // ======================================================================
@@ -50,10 +56,20 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 
 	// Simpan ke database via repository
 	if err := h.repo.CreateKategori(c.Request.Context(), &req); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Kategori dengan nama tersebut sudah ada"})
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidParent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent kategori tidak ditemukan"})
+			return
+		}
 		log.Printf("[CategoryHandler] Gagal membuat kategori: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan kategori", "details": err.Error()})
 		return
 	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventCreate, model.EntityCategory, req.ID), nil, req)
+	publishEvent(c, h.hub, userID, model.EntityCategory, model.EventCreate, req)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Kategori berhasil ditambahkan",
@@ -64,6 +80,8 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 // ======================================================================
 // GET ALL CATEGORY (GET /api/v1/kategori)
 // ======================================================================
+// Mengembalikan kategori dalam bentuk pohon (field 'children').
+// Gunakan ?flat=true untuk daftar datar (misal untuk dropdown).
 func (h *CategoryHandler) GetCategories(c *gin.Context) {
 	userID, ok := helper.GetUserID(c)
 	if !ok {
@@ -77,6 +95,10 @@ func (h *CategoryHandler) GetCategories(c *gin.Context) {
 		return
 	}
 
+	if c.Query("flat") != "true" {
+		kategoriList = model.BuildCategoryTree(kategoriList)
+	}
+
 	if kategoriList == nil {
 		kategoriList = []model.Category{}
 	}
@@ -110,14 +132,34 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	before, err := h.repo.GetKategoriByID(c.Request.Context(), kategoriID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kategori tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil kategori"})
+		return
+	}
+
 	req.ID = kategoriID
 	req.UserID = userID
 
 	if err := h.repo.UpdateKategori(c.Request.Context(), &req); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Kategori dengan nama tersebut sudah ada"})
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidParent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent kategori tidak valid (tidak ditemukan atau membentuk siklus)"})
+			return
+		}
 		log.Printf("[CategoryHandler] Error update kategori: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui kategori", "details": err.Error()})
 		return
 	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventUpdate, model.EntityCategory, kategoriID), before, req)
+	publishEvent(c, h.hub, userID, model.EntityCategory, model.EventUpdate, req)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Kategori berhasil diperbarui",
@@ -126,8 +168,10 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 }
 
 // ======================================================================
-// DELETE CATEGORY (DELETE /api/v1/kategori/:id)
+// DELETE CATEGORY (DELETE /api/v1/kategori/:id?reassign_to=ID | ?uncategorize=true)
 // ======================================================================
+// Item yang memakai kategori ini harus dipindahkan ke kategori lain (reassign_to)
+// atau secara eksplisit dibuat tanpa kategori (uncategorize=true).
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	userID, ok := helper.GetUserID(c)
 	if !ok {
@@ -140,13 +184,104 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	// TODO (opsional): Cek apakah kategori digunakan oleh tabel Items sebelum dihapus
+	var reassignTo *int
+	if target := c.Query("reassign_to"); target != "" {
+		targetID, err := strconv.Atoi(target)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID kategori tujuan tidak valid"})
+			return
+		}
+		if targetID == kategoriID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori tujuan tidak boleh sama dengan kategori yang dihapus"})
+			return
+		}
+		reassignTo = &targetID
+	} else if c.Query("uncategorize") != "true" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pilih kategori tujuan (reassign_to) atau uncategorize=true untuk item di kategori ini"})
+		return
+	}
 
-	if err := h.repo.DeleteKategori(c.Request.Context(), kategoriID, userID); err != nil {
+	before, err := h.repo.GetKategoriByID(c.Request.Context(), kategoriID, userID)
+	if err == nil {
+		err = h.repo.DeleteKategori(c.Request.Context(), kategoriID, userID, reassignTo)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kategori tidak ditemukan"})
+			return
+		}
 		log.Printf("[CategoryHandler] Error delete kategori: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus kategori", "details": err.Error()})
 		return
 	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventDelete, model.EntityCategory, kategoriID), before, nil)
+	publishEvent(c, h.hub, userID, model.EntityCategory, model.EventDelete, before)
+	invalidateRules(c, h.categorizer)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Kategori berhasil dihapus"})
 }
+
+// ======================================================================
+// MERGE CATEGORY (POST /api/v1/kategori/merge)
+// ======================================================================
+// MergeCategories memindahkan semua item dari kategori sumber ke kategori target,
+// lalu menghapus kategori sumber. Semua dilakukan secara atomik.
+func (h *CategoryHandler) MergeCategories(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CategoryMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Permintaan tidak valid", "details": err.Error()})
+		return
+	}
+
+	for _, id := range req.SourceIDs {
+		if id == req.TargetID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori target tidak boleh termasuk dalam kategori sumber"})
+			return
+		}
+	}
+
+	// Data kategori sumber untuk log audit; kategori yang tidak ditemukan akan ditolak oleh MergeKategori
+	sources := make([]*model.Category, 0, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		source, err := h.repo.GetKategoriByID(c.Request.Context(), id, userID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil kategori"})
+			return
+		}
+		if source != nil {
+			sources = append(sources, source)
+		}
+	}
+
+	if err := h.repo.MergeKategori(c.Request.Context(), userID, req.SourceIDs, req.TargetID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Satu atau lebih kategori tidak ditemukan"})
+			return
+		}
+		log.Printf("[CategoryHandler] Error merge kategori: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menggabungkan kategori", "details": err.Error()})
+		return
+	}
+	// Penggabungan tercatat sebagai penghapusan setiap kategori sumber
+	for _, source := range sources {
+		recordEvent(c, h.audit, auditEvent(c, userID, model.EventDelete, model.EntityCategory, source.ID), source, nil)
+		publishEvent(c, h.hub, userID, model.EntityCategory, model.EventDelete, source)
+	}
+	invalidateRules(c, h.categorizer)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kategori berhasil digabungkan"})
+}
+
+// invalidateRules membuang cache aturan kategori household aktif setelah aturannya
+// berubah, atau setelah kategorinya dihapus, digabung atau dipulihkan, karena aturan
+// ke kategori di trash tidak dipakai.
+func invalidateRules(c *gin.Context, categorizer *service.CategorizeService) {
+	if household, ok := helper.LookupHousehold(c); ok {
+		categorizer.InvalidateHousehold(household.ID)
+	}
+}
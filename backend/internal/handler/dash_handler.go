@@ -1,16 +1,21 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	// Pastikan path impor ini sesuai dengan struktur proyek Anda
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/forecast"
 	"github.com/gusti3111/TKBMG/backend/internal/helper" // <-- 1. IMPORT HELPER
 	"github.com/gusti3111/TKBMG/backend/internal/model"
 	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
 )
 
 // DashboardHandler menangani logika untuk endpoint dasbor
@@ -18,6 +23,7 @@ type DashboardHandler struct {
 	itemRepo   *repository.ItemRepository
 	budgetRepo *repository.BudgetRepository
 	reportRepo *repository.ReportRepository
+	dashboard  *service.DashboardService
 }
 
 // NewDashboardHandler membuat instance DashboardHandler baru
@@ -25,131 +31,242 @@ func NewDashboardHandler(
 	itemRepo *repository.ItemRepository,
 	budgetRepo *repository.BudgetRepository,
 	reportRepo *repository.ReportRepository,
+	dashboard *service.DashboardService,
 ) *DashboardHandler {
 	return &DashboardHandler{
 		itemRepo:   itemRepo,
 		budgetRepo: budgetRepo,
 		reportRepo: reportRepo,
+		dashboard:  dashboard,
 	}
 }
 
 // GetDashboardSummary
 // Ini adalah handler untuk endpoint: GET /api/v1/dashboard/summary
+// Default range=budget: periode budget aktif, atau minggu berjalan jika belum ada budget.
+// Lihat parseDashboardQuery untuk parameter rentang.
 func (h *DashboardHandler) GetDashboardSummary(c *gin.Context) {
-	// ======================================================
-	// =============== PERBAIKAN 1: GUNAKAN HELPER ==========
-	// ======================================================
 	userID, ok := helper.GetUserID(c)
 	if !ok {
 		// helper.GetUserID(c) sudah mengirim respons error 401
 		log.Println("[DashboardHandler] Gagal mengambil UserID dari helper")
 		return
 	}
-	// ======================================================
 
-	ctx := c.Request.Context()
-
-	// 2. Dapatkan Budget Mingguan Terakhir
-	now := time.Now()
-	budget, err := h.budgetRepo.GetBudgetByDate(ctx, userID, now)
-
-	var budgetAmount float64
-	var startDate, endDate time.Time
+	cal := helper.GetCalendar(c)
+	query, errs := parseDashboardQuery(c, cal, model.DashboardRangeBudget)
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter dasbor tidak valid", "details": errs})
+		return
+	}
 
-	// ======================================================
-	// =============== PERBAIKAN 2: SINKRONKAN TANGGAL ======
-	// ======================================================
-	if err != nil {
-		// Ini bukan error fatal, mungkin user belum set budget
-		log.Printf("Info: No budget found for user %d for this week: %v", userID, err)
-		budgetAmount = 0.0
-		// Jika tidak ada budget, hitung belanja 7 hari terakhir sebagai default
-		endDate = time.Now()
-		startDate = endDate.AddDate(0, 0, -7) // 7 hari ke belakang
-	} else if budget != nil {
-		budgetAmount = budget.Amount
-		// Gunakan rentang tanggal DARI BUDGET untuk menghitung belanja
-		startDate = budget.StartDate
-		endDate = budget.EndDate
-	}
-	// ======================================================
-
-	// 3. Dapatkan Total Belanja Mingguan
-	// (Memanggil fungsi dari item_repository.go)
-	totalBelanja, err := h.itemRepo.GetTotalSpendingByDateRange(ctx, userID, startDate, endDate)
+	ctx := c.Request.Context()
+	query, budget, err := h.dashboard.Resolve(ctx, userID, cal, query)
 	if err != nil {
-		log.Printf("Error getting total spending for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate spending"})
+		dashboardError(c, err, "Failed to get current budget")
 		return
 	}
 
-	// 4. Hitung Sisa Budget
-	sisaBudget := budgetAmount - totalBelanja
-
-	// 5. Siapkan Respons (Menggunakan model dari dashboard_models.go)
-	summary := model.SummaryResponse{
-		TotalBelanja: totalBelanja,
-		Budget:       budgetAmount,
-		SisaBudget:   sisaBudget,
+	summary, err := h.dashboard.Summary(ctx, userID, query, budget)
+	if err != nil {
+		dashboardError(c, err, "Failed to calculate spending")
+		return
 	}
 
-	// ======================================================
-	// =============== PERBAIKAN 3: (KONFIRMASI) ============
-	// ======================================================
-	// Respons "data" ini SUDAH BENAR. Jangan diubah.
-	// Frontend (SetBudget.jsx) harus membaca dari data.data.
-	c.JSON(http.StatusOK, gin.H{"data": summary})
+	// Frontend (SetBudget.jsx) membaca ringkasan dari data.data
+	c.JSON(http.StatusOK, gin.H{"data": summary, "periode": query})
 }
 
 // GetDashboardCharts
 // Ini adalah handler untuk endpoint: GET /api/v1/dashboard/charts
+// Default range=30d. ?depth=N menggabungkan sub-kategori pie chart sampai tingkat ke-N
+// (0 = tanpa rollup); bar chart mengikuti granularity.
 func (h *DashboardHandler) GetDashboardCharts(c *gin.Context) {
-	// ======================================================
-	// =============== PERBAIKAN 1: GUNAKAN HELPER ==========
-	// ======================================================
 	userID, ok := helper.GetUserID(c)
 	if !ok {
 		log.Println("[DashboardHandler] Gagal mengambil UserID dari helper")
 		return
 	}
-	// ======================================================
+
+	cal := helper.GetCalendar(c)
+	query, errs := parseDashboardQuery(c, cal, model.DashboardRange30d)
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if err != nil || depth < 0 {
+		errs = append(errs, model.FieldError{Field: "depth", Message: "Harus bilangan bulat minimal 0"})
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter dasbor tidak valid", "details": errs})
+		return
+	}
 
 	ctx := c.Request.Context()
+	query, _, err = h.dashboard.Resolve(ctx, userID, cal, query)
+	if err != nil {
+		dashboardError(c, err, "Failed to get current budget")
+		return
+	}
+
+	charts, err := h.dashboard.Charts(ctx, userID, cal, query, depth)
+	if err != nil {
+		dashboardError(c, err, "Failed to get chart data")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": charts, "periode": query})
+}
 
-	// 2. Tentukan rentang tanggal (misalnya, 30 hari terakhir untuk charts)
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, -1, 0) // 1 bulan ke belakang
+// ======================================================================
+// DASHBOARD (GET /api/v1/dashboard?range=budget&granularity=day&depth=0&recent=5)
+// ======================================================================
+// GetDashboard mengembalikan ringkasan, chart, aktivitas terakhir dan progres budget
+// dalam satu respons. Rentang berlaku untuk ringkasan dan chart; progres budget selalu
+// untuk budget yang aktif hari ini. ?recent=N jumlah aktivitas terakhir (default 5).
+func (h *DashboardHandler) GetDashboard(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
 
-	// 3. Dapatkan data Pie Chart (Pengeluaran per Kategori)
-	pieDataRepo, err := h.reportRepo.GetSpendingByCategory(ctx, userID, startDate, endDate)
+	cal := helper.GetCalendar(c)
+	query, errs := parseDashboardQuery(c, cal, model.DashboardRangeBudget)
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if err != nil || depth < 0 {
+		errs = append(errs, model.FieldError{Field: "depth", Message: "Harus bilangan bulat minimal 0"})
+	}
+	recent, err := strconv.Atoi(c.DefaultQuery("recent", "5"))
+	if err != nil || recent < 1 || recent > 50 {
+		errs = append(errs, model.FieldError{Field: "recent", Message: "Harus bilangan bulat antara 1 dan 50"})
+	}
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter dasbor tidak valid", "details": errs})
+		return
+	}
+
+	dashboard, err := h.dashboard.Dashboard(c.Request.Context(), userID, cal, query, depth, recent)
 	if err != nil {
-		log.Printf("Error getting pie chart data for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pie chart data"})
+		dashboardError(c, err, "Gagal memuat dasbor")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dashboard})
+}
+
+// parseDashboardQuery membaca rentang dasbor dan mengumpulkan semua kesalahan validasi:
+//
+//   - range: budget | week | month | year | 7d | 30d | 90d (default defaultRange).
+//     Rentang berjalan (week, month, year) dihitung sampai hari ini.
+//   - from, to: rentang custom YYYY-MM-DD (inklusif), diisi bersamaan dan tidak bersama range
+//   - granularity: day | week | month untuk bar chart; kosong = otomatis dari panjang rentang
+func parseDashboardQuery(c *gin.Context, cal calendar.Calendar, defaultRange string) (model.DashboardQuery, []model.FieldError) {
+	var errs []model.FieldError
+	q := model.DashboardQuery{Granularity: c.Query("granularity")}
+
+	rangeStr, fromStr, toStr := c.Query("range"), c.Query("from"), c.Query("to")
+	switch {
+	case fromStr == "" && toStr == "":
+		q.Range = defaultRange
+		if rangeStr != "" {
+			q.Range = rangeStr
+		}
+		if !model.ValidDashboardRange(q.Range) {
+			errs = append(errs, model.FieldError{Field: "range", Message: "Harus salah satu dari: budget, week, month, year, 7d, 30d, 90d"})
+		}
+	case fromStr == "" || toStr == "":
+		errs = append(errs, model.FieldError{Field: "from", Message: "from dan to harus diisi bersamaan"})
+	case rangeStr != "":
+		errs = append(errs, model.FieldError{Field: "range", Message: "Tidak boleh diisi bersama from/to"})
+	default:
+		q.Range = model.DashboardRangeCustom
+		from, err := cal.ParseDate(fromStr)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "from", Message: "Format tanggal harus YYYY-MM-DD"})
+		}
+		to, err2 := cal.ParseDate(toStr)
+		if err2 != nil {
+			errs = append(errs, model.FieldError{Field: "to", Message: "Format tanggal harus YYYY-MM-DD"})
+		}
+		if err == nil && err2 == nil {
+			if to.Before(from) {
+				errs = append(errs, model.FieldError{Field: "to", Message: "Tidak boleh sebelum from"})
+			}
+			q.From, q.To = from, cal.EndOfDay(to)
+		}
+	}
+
+	if q.Granularity != "" && !model.ValidGranularity(q.Granularity) {
+		errs = append(errs, model.FieldError{Field: "granularity", Message: "Harus salah satu dari: day, week, month"})
+	} else if q.Granularity != "" && len(errs) == 0 && q.Range == model.DashboardRangeCustom &&
+		service.CountPeriods(cal, q.From, q.To, q.Granularity) > service.MaxTrendPeriods {
+		errs = append(errs, model.FieldError{Field: "granularity",
+			Message: fmt.Sprintf("Rentang terlalu panjang untuk granularitas ini (maksimal %d titik tren)", service.MaxTrendPeriods)})
+	}
+	return q, errs
+}
+
+// dashboardError menjawab kegagalan memuat dasbor dengan 500. Jika request sudah dibatalkan
+// klien (koneksi ditutup), tidak ada yang perlu dikirim.
+func dashboardError(c *gin.Context, err error, message string) {
+	if c.Request.Context().Err() != nil {
+		log.Printf("[DashboardHandler] Request dibatalkan: %v", c.Request.Context().Err())
+		c.Abort()
 		return
 	}
-	pieData := make([]model.PieChartItem, len(pieDataRepo))
-	for i, item := range pieDataRepo {
-		pieData[i] = model.PieChartItem{Name: item.Kategori, Value: item.Total}
+	log.Printf("[DashboardHandler] %s: %v", message, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+// GetDashboardForecast
+// Ini adalah handler untuk endpoint: GET /api/v1/dashboard/forecast
+// Memproyeksikan total belanja di akhir periode budget aktif (lihat package forecast).
+func (h *DashboardHandler) GetDashboardForecast(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		log.Println("[DashboardHandler] Gagal mengambil UserID dari helper")
+		return
 	}
 
-	// 4. Dapatkan data Bar Chart (Pengeluaran per Minggu - misal 4 minggu terakhir)
-	barDataRepo, err := h.reportRepo.GetSpendingByWeek(ctx, userID, 4) // Ambil 4 minggu terakhir
+	ctx := c.Request.Context()
+	now := helper.GetCalendar(c).Now()
+
+	// 1. Budget aktif beserta belanja sampai hari ini (budget efektif, termasuk carry-over)
+	budget, err := h.budgetRepo.GetBudgetStatusByDate(ctx, userID, now)
 	if err != nil {
-		log.Printf("Error getting bar chart data for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get bar chart data"})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Belum ada budget untuk periode ini"})
+			return
+		}
+		log.Printf("Error getting current budget for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get current budget"})
 		return
 	}
-	barData := make([]model.BarChartItem, len(barDataRepo))
-	for i, item := range barDataRepo {
-		barData[i] = model.BarChartItem{Name: item.MingguKe, Pengeluaran: item.Total}
+
+	// 2. Item yang sudah direncanakan tapi belum dibeli
+	planned, err := h.itemRepo.GetPlannedTotalByDateRange(ctx, userID, budget.StartDate, budget.EndDate)
+	if err != nil {
+		log.Printf("Error getting planned items for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get planned items"})
+		return
 	}
 
-	// 5. Siapkan Respons
-	charts := model.ChartResponse{
-		PieChart: pieData,
-		BarChart: barData,
+	// 3. Riwayat belanja harian sebelum periode berjalan untuk pola per hari
+	historyStart := budget.StartDate.AddDate(0, 0, -forecast.HistoryDays)
+	history, err := h.reportRepo.GetSpendingByDay(ctx, userID, historyStart, budget.StartDate.AddDate(0, 0, -1))
+	if err != nil {
+		log.Printf("Error getting spending history for user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get spending history"})
+		return
 	}
 
-	// Respons "data" ini juga sudah benar
-	c.JSON(http.StatusOK, gin.H{"data": charts})
+	result := forecast.Project(forecast.Input{
+		PeriodStart:  budget.StartDate,
+		PeriodEnd:    budget.EndDate,
+		Today:        now,
+		Budget:       budget.EffectiveAmount,
+		SpentToDate:  budget.Spent,
+		Planned:      planned,
+		History:      history,
+		HistoryStart: historyStart,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
 }
@@ -0,0 +1,424 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/config"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/stream"
+)
+
+// HouseholdHandler menangani logika HTTP untuk household, anggota dan undangannya.
+type HouseholdHandler struct {
+	repo *repository.HouseholdRepository
+	hub  *stream.Hub
+}
+
+// NewHouseholdHandler membuat instance HouseholdHandler baru.
+func NewHouseholdHandler(repo *repository.HouseholdRepository, hub *stream.Hub) *HouseholdHandler {
+	return &HouseholdHandler{repo: repo, hub: hub}
+}
+
+// ======================================================================
+// CREATE HOUSEHOLD (POST /api/v1/households)
+// ======================================================================
+// CreateHousehold membuat household baru dengan user sebagai owner dan langsung mengaktifkannya.
+func (h *HouseholdHandler) CreateHousehold(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	name, ok := bindHouseholdName(c)
+	if !ok {
+		return
+	}
+
+	household, err := h.repo.CreateHousehold(c.Request.Context(), userID, name)
+	if err != nil {
+		log.Printf("[HouseholdHandler] Gagal membuat household: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat household"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Household berhasil dibuat",
+		"data":    household,
+	})
+}
+
+// ======================================================================
+// GET HOUSEHOLDS (GET /api/v1/households)
+// ======================================================================
+func (h *HouseholdHandler) GetHouseholds(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	households, err := h.repo.GetHouseholdsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[HouseholdHandler] Gagal mengambil household: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil household"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": households})
+}
+
+// ======================================================================
+// GET CURRENT HOUSEHOLD (GET /api/v1/households/current)
+// ======================================================================
+// GetCurrentHousehold mengembalikan household aktif beserta role user di dalamnya.
+func (h *HouseholdHandler) GetCurrentHousehold(c *gin.Context) {
+	household, ok := helper.GetHousehold(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": household})
+}
+
+// ======================================================================
+// RENAME HOUSEHOLD (PUT /api/v1/households/:id)
+// ======================================================================
+func (h *HouseholdHandler) RenameHousehold(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+	name, ok := bindHouseholdName(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RenameHousehold(c.Request.Context(), householdID, userID, name); err != nil {
+		respondHouseholdError(c, err, "Gagal mengganti nama household")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Nama household berhasil diperbarui"})
+}
+
+// ======================================================================
+// ACTIVATE HOUSEHOLD (POST /api/v1/households/:id/activate)
+// ======================================================================
+// ActivateHousehold memindahkan household aktif user. Semua data item, kategori,
+// budget dan template berikutnya dibaca dari household ini.
+func (h *HouseholdHandler) ActivateHousehold(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.SetActiveHousehold(c.Request.Context(), userID, householdID); err != nil {
+		respondHouseholdError(c, err, "Gagal mengaktifkan household")
+		return
+	}
+
+	household, err := h.repo.GetActiveHousehold(c.Request.Context(), userID)
+	if err != nil {
+		respondHouseholdError(c, err, "Gagal mengambil household")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Household aktif berhasil diganti",
+		"data":    household,
+	})
+}
+
+// ======================================================================
+// GET MEMBERS (GET /api/v1/households/:id/members)
+// ======================================================================
+func (h *HouseholdHandler) GetMembers(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.GetMembers(c.Request.Context(), householdID, userID)
+	if err != nil {
+		respondHouseholdError(c, err, "Gagal mengambil anggota household")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// ======================================================================
+// UPDATE MEMBER ROLE (PUT /api/v1/households/:id/members/:userId)
+// ======================================================================
+// UpdateMemberRole mengubah role anggota. Hanya owner yang boleh; owner terakhir tidak bisa diturunkan.
+func (h *HouseholdHandler) UpdateMemberRole(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggota tidak valid"})
+		return
+	}
+
+	var req model.MemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid: " + err.Error()})
+		return
+	}
+	if !model.ValidHouseholdRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role harus 'owner', 'editor' atau 'viewer'"})
+		return
+	}
+
+	if err := h.repo.UpdateMemberRole(c.Request.Context(), householdID, userID, memberID, req.Role); err != nil {
+		respondHouseholdError(c, err, "Gagal mengubah role anggota")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role anggota berhasil diperbarui"})
+}
+
+// ======================================================================
+// REMOVE MEMBER (DELETE /api/v1/households/:id/members/:userId)
+// ======================================================================
+// RemoveMember mengeluarkan anggota. Owner boleh mengeluarkan siapa pun, anggota lain
+// hanya boleh keluar sendiri (userId = ID dirinya).
+func (h *HouseholdHandler) RemoveMember(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID anggota tidak valid"})
+		return
+	}
+
+	if err := h.repo.RemoveMember(c.Request.Context(), householdID, userID, memberID); err != nil {
+		respondHouseholdError(c, err, "Gagal mengeluarkan anggota")
+		return
+	}
+	// Putus stream live anggota tersebut agar tidak lagi menerima event household ini
+	if err := h.hub.RemoveMember(context.WithoutCancel(c.Request.Context()), householdID, userID, memberID); err != nil {
+		log.Printf("[HouseholdHandler] Gagal memutus stream anggota %d: %v", memberID, err)
+	}
+
+	message := "Anggota berhasil dikeluarkan"
+	if memberID == userID {
+		message = "Anda berhasil keluar dari household"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// ======================================================================
+// CREATE INVITATION (POST /api/v1/households/:id/invitations)
+// ======================================================================
+// CreateInvitation membuat link undangan sekali pakai. Token hanya ditampilkan di respons
+// ini; simpan atau bagikan link-nya langsung.
+func (h *HouseholdHandler) CreateInvitation(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+
+	var req model.InvitationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid: " + err.Error()})
+			return
+		}
+	}
+	if req.Role == "" {
+		req.Role = model.HouseholdRoleEditor
+	}
+	if req.Role != model.HouseholdRoleEditor && req.Role != model.HouseholdRoleViewer {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role undangan harus 'editor' atau 'viewer'"})
+		return
+	}
+	ttl := config.InvitationTTL
+	if req.ExpiresInH != 0 {
+		ttl = time.Duration(req.ExpiresInH) * time.Hour
+	}
+	if ttl <= 0 || ttl > config.InvitationMaxTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Masa berlaku undangan harus antara 1 jam dan 30 hari"})
+		return
+	}
+
+	inv, err := h.repo.CreateInvitation(c.Request.Context(), householdID, userID, req.Role, time.Now().Add(ttl))
+	if err != nil {
+		respondHouseholdError(c, err, "Gagal membuat undangan")
+		return
+	}
+	inv.Link = config.InvitationBaseURL + inv.Token
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Undangan berhasil dibuat",
+		"data":    inv,
+	})
+}
+
+// ======================================================================
+// GET INVITATIONS (GET /api/v1/households/:id/invitations)
+// ======================================================================
+func (h *HouseholdHandler) GetInvitations(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.repo.GetInvitations(c.Request.Context(), householdID, userID)
+	if err != nil {
+		respondHouseholdError(c, err, "Gagal mengambil undangan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+// ======================================================================
+// REVOKE INVITATION (DELETE /api/v1/households/:id/invitations/:inviteId)
+// ======================================================================
+func (h *HouseholdHandler) RevokeInvitation(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	householdID, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+	invitationID, err := strconv.Atoi(c.Param("inviteId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID undangan tidak valid"})
+		return
+	}
+
+	if err := h.repo.RevokeInvitation(c.Request.Context(), householdID, invitationID, userID); err != nil {
+		respondHouseholdError(c, err, "Gagal mencabut undangan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Undangan berhasil dicabut"})
+}
+
+// ======================================================================
+// ACCEPT INVITATION (POST /api/v1/invitations/:token/accept)
+// ======================================================================
+// AcceptInvitation memasukkan user ke household pengundang dan menjadikannya household aktif.
+func (h *HouseholdHandler) AcceptInvitation(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	token := strings.TrimSpace(c.Param("token"))
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token undangan tidak valid"})
+		return
+	}
+
+	household, err := h.repo.AcceptInvitation(c.Request.Context(), token, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Undangan tidak ditemukan"})
+		case errors.Is(err, repository.ErrInvitationInvalid):
+			c.JSON(http.StatusGone, gin.H{"error": "Undangan sudah kedaluwarsa, dicabut atau sudah dipakai"})
+		case errors.Is(err, repository.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Anda sudah menjadi anggota household ini"})
+		default:
+			log.Printf("[HouseholdHandler] Gagal menerima undangan: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menerima undangan"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Berhasil bergabung ke household",
+		"data":    household,
+	})
+}
+
+func parseHouseholdID(c *gin.Context) (int, bool) {
+	householdID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID household tidak valid"})
+		return 0, false
+	}
+	return householdID, true
+}
+
+func bindHouseholdName(c *gin.Context) (string, bool) {
+	var req model.HouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid: " + err.Error()})
+		return "", false
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nama household wajib diisi (maksimal 100 karakter)"})
+		return "", false
+	}
+	return name, true
+}
+
+// respondHouseholdError memetakan error repository household ke respons HTTP
+func respondHouseholdError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household atau data tidak ditemukan"})
+	case errors.Is(err, repository.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Hanya owner household yang dapat melakukan aksi ini"})
+	case errors.Is(err, repository.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "Household harus memiliki minimal satu owner"})
+	default:
+		log.Printf("[HouseholdHandler] %s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
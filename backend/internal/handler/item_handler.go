@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -10,19 +11,31 @@ import (
 	"github.com/gusti3111/TKBMG/backend/internal/helper"
 	"github.com/gusti3111/TKBMG/backend/internal/model"
 	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+	"github.com/gusti3111/TKBMG/backend/internal/stream"
 )
 
 // ItemHandler menangani operasi HTTP untuk tabel items
 type ItemHandler struct {
 	repo         *repository.ItemRepository
 	categoryRepo *repository.CategoryRepository
+	categorizer  *service.CategorizeService
+	alerts       *service.AlertService
+	anomalies    *service.AnomalyService
+	audit        *service.AuditService
+	hub          *stream.Hub
 }
 
 // NewItemHandler membuat handler baru
-func NewItemHandler(repo *repository.ItemRepository, categoryRepo *repository.CategoryRepository) *ItemHandler {
+func NewItemHandler(repo *repository.ItemRepository, categoryRepo *repository.CategoryRepository, categorizer *service.CategorizeService, alerts *service.AlertService, anomalies *service.AnomalyService, audit *service.AuditService, hub *stream.Hub) *ItemHandler {
 	return &ItemHandler{
 		repo:         repo,
 		categoryRepo: categoryRepo,
+		categorizer:  categorizer,
+		alerts:       alerts,
+		anomalies:    anomalies,
+		audit:        audit,
+		hub:          hub,
 	}
 }
 
@@ -60,22 +73,50 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 		}
 	}
 
+	if !validItemStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status item harus 'planned' atau 'purchased'"})
+		return
+	}
+
+	household, ok := helper.GetHousehold(c)
+	if !ok {
+		return
+	}
+
 	req.UserID = userID
-	req.PurchasedDate = time.Now()
+	req.HouseholdID = household.ID
+	req.PurchasedDate = helper.GetCalendar(c).Now()
+	if req.Status == "" {
+		// Detektor anomali membaca status dari req, jadi default-nya diisi di sini
+		req.Status = model.ItemStatusPurchased
+	}
 	req.TotalCost = float64(req.Quantity) * req.UnitPrice
 
-	// NOTE: Repository hanya menyimpan nama_item, jumlah_item, harga_satuan
-	// Kolom lain (userID, kategori, total_harga, purchased_date) belum disimpan di DB
+	// Isi kategori otomatis dari aturan/riwayat user jika id_kategori tidak dikirim
+	suggestion, err := h.categorizer.Apply(c.Request.Context(), household.ID, &req)
+	if err != nil {
+		// Bukan error fatal, item tetap disimpan tanpa kategori
+		log.Printf("[ItemHandler] Gagal menebak kategori: %v", err)
+	}
+
 	if err := h.repo.CreateItem(c.Request.Context(), &req); err != nil {
 		log.Printf("[ItemHandler] Error saving item: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan item"})
 		return
 	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventCreate, model.EntityItem, req.ID), nil, req)
+	publishEvent(c, h.hub, userID, model.EntityItem, model.EventCreate, req)
+	h.checkBudgetAlerts(c, userID, req.PurchasedDate)
+	h.checkAnomalies(c, &req)
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"message": "Item berhasil ditambahkan",
 		"data":    req,
-	})
+	}
+	if suggestion != nil {
+		response["kategori_otomatis"] = suggestion
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
 // ======================================================================
@@ -122,16 +163,66 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 		return
 	}
 
+	if !validItemStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status item harus 'planned' atau 'purchased'"})
+		return
+	}
+
+	// Pastikan kategori valid jika ada, sama seperti saat membuat item
+	if req.CategoryID != 0 {
+		exists, err := h.repo.CategoryExists(c.Request.Context(), req.CategoryID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa kategori"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori tidak ditemukan"})
+			return
+		}
+	}
+
+	household, ok := helper.GetHousehold(c)
+	if !ok {
+		return
+	}
+
+	// Data lama untuk log audit; sekaligus memastikan item ada dan milik user
+	before, err := h.repo.GetItemByID(c.Request.Context(), itemID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil item"})
+		return
+	}
+
 	req.ID = itemID
 	req.UserID = userID
+	req.HouseholdID = household.ID
 	req.TotalCost = float64(req.Quantity) * req.UnitPrice
-	req.PurchasedDate = time.Now()
+	if req.PurchasedDate.IsZero() {
+		// Tanggal tidak dikirim: item tetap di periode budget aslinya
+		req.PurchasedDate = before.PurchasedDate
+	}
+	if req.Status == "" {
+		// Status tidak dikirim: pertahankan status lama (misal item 'planned' dari template)
+		req.Status = before.Status
+	}
 
 	if err := h.repo.UpdateItem(c.Request.Context(), &req); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item tidak ditemukan"})
+			return
+		}
 		log.Printf("[ItemHandler] Error updating item: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui item"})
 		return
 	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventUpdate, model.EntityItem, itemID), before, req)
+	publishEvent(c, h.hub, userID, model.EntityItem, model.EventUpdate, req)
+	h.checkBudgetAlerts(c, userID, before.PurchasedDate, req.PurchasedDate)
+	h.checkAnomalies(c, &req)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Item berhasil diperbarui", "data": req})
 }
@@ -151,11 +242,54 @@ func (h *ItemHandler) DeleteItem(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.DeleteItem(c.Request.Context(), itemID, userID); err != nil {
+	before, err := h.repo.GetItemByID(c.Request.Context(), itemID, userID)
+	if err == nil {
+		err = h.repo.DeleteItem(c.Request.Context(), itemID, userID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item tidak ditemukan"})
+			return
+		}
 		log.Printf("[ItemHandler] Error deleting item: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus item"})
 		return
 	}
+	recordEvent(c, h.audit, auditEvent(c, userID, model.EventDelete, model.EntityItem, itemID), before, nil)
+	publishEvent(c, h.hub, userID, model.EntityItem, model.EventDelete, before)
+	h.checkBudgetAlerts(c, userID, before.PurchasedDate)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Item berhasil dihapus"})
 }
+
+// validItemStatus memeriksa status item dari request. Status kosong diperbolehkan:
+// item baru dianggap 'purchased', item yang diubah mempertahankan status lamanya.
+func validItemStatus(status string) bool {
+	return status == "" || status == model.ItemStatusPlanned || status == model.ItemStatusPurchased
+}
+
+// checkBudgetAlerts mengevaluasi ambang peringatan budget pada periode tanggal-tanggal item
+// yang berubah, ditambah periode saat ini karena carry-over dari periode lampau ikut bergeser.
+// Kegagalan hanya dicatat ke log agar tidak menggagalkan operasi item.
+func (h *ItemHandler) checkBudgetAlerts(c *gin.Context, userID int, dates ...time.Time) {
+	now := helper.GetCalendar(c).Now()
+	checked := make(map[string]bool, len(dates)+1)
+	for _, date := range append(dates, now) {
+		day := date.Format("2006-01-02")
+		if checked[day] {
+			continue
+		}
+		checked[day] = true
+		if err := h.alerts.CheckBudgetAt(c.Request.Context(), userID, date); err != nil {
+			log.Printf("[ItemHandler] Gagal memeriksa ambang budget %s: %v", day, err)
+		}
+	}
+}
+
+// checkAnomalies menjalankan detektor anomali terhadap item yang baru ditulis.
+// Sama seperti peringatan budget, kegagalan hanya dicatat ke log.
+func (h *ItemHandler) checkAnomalies(c *gin.Context, item *model.Item) {
+	if _, err := h.anomalies.CheckItem(c.Request.Context(), helper.GetCalendar(c), item); err != nil {
+		log.Printf("[ItemHandler] Gagal memeriksa anomali item %d: %v", item.ID, err)
+	}
+}
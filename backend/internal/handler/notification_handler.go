@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// NotificationHandler menangani logika HTTP untuk pusat notifikasi.
+type NotificationHandler struct {
+	repo *repository.NotificationRepository
+}
+
+// NewNotificationHandler membuat instance NotificationHandler baru.
+func NewNotificationHandler(repo *repository.NotificationRepository) *NotificationHandler {
+	return &NotificationHandler{repo: repo}
+}
+
+// ======================================================================
+// GET NOTIFICATIONS (GET /api/v1/notifications?unread=true&page=1&limit=10)
+// ======================================================================
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit, ok := helper.GetPagination(c)
+	if !ok {
+		return
+	}
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, total, err := h.repo.GetNotifications(c.Request.Context(), userID, unreadOnly, limit, (page-1)*limit)
+	if err != nil {
+		log.Printf("[NotificationHandler] Gagal mengambil notifikasi: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil notifikasi"})
+		return
+	}
+	unread, err := h.repo.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[NotificationHandler] Gagal menghitung notifikasi belum dibaca: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil notifikasi"})
+		return
+	}
+
+	if notifications == nil {
+		notifications = []model.Notification{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         notifications,
+		"unread_count": unread,
+		"pagination":   model.Pagination{Page: page, Limit: limit, Total: total},
+	})
+}
+
+// ======================================================================
+// MARK READ (PUT /api/v1/notifications/:id/read)
+// ======================================================================
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID notifikasi tidak valid"})
+		return
+	}
+
+	if err := h.repo.MarkRead(c.Request.Context(), notificationID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notifikasi tidak ditemukan"})
+			return
+		}
+		log.Printf("[NotificationHandler] Gagal menandai notifikasi: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui notifikasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifikasi ditandai sudah dibaca"})
+}
+
+// ======================================================================
+// MARK ALL READ (PUT /api/v1/notifications/read-all)
+// ======================================================================
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.repo.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[NotificationHandler] Gagal menandai semua notifikasi: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui notifikasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Semua notifikasi ditandai sudah dibaca", "updated": updated})
+}
@@ -1,31 +1,39 @@
 package handler
 
 import (
-	"bytes"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
 	"github.com/gusti3111/TKBMG/backend/internal/helper"
 	"github.com/gusti3111/TKBMG/backend/internal/model"
-	"github.com/gusti3111/TKBMG/backend/internal/repository"
-	"github.com/xuri/excelize/v2" // <-- 1. Import excelize
+	"github.com/gusti3111/TKBMG/backend/internal/report"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
 )
 
 // ReportHandler menangani logika HTTP untuk Laporan.
 type ReportHandler struct {
-	reportRepo *repository.ReportRepository
+	reportService *service.ReportService
 }
 
 // NewReportHandler membuat instance ReportHandler baru.
-func NewReportHandler(r *repository.ReportRepository) *ReportHandler {
-	return &ReportHandler{reportRepo: r}
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
 }
 
 // GenerateReport menangani GET /api/v1/reports/download
+// Contoh: /api/v1/reports/download?type=csv&from=2026-09-01&to=2026-09-30&granularity=day&compare=previous
+//
+//   - type: excel (default) | pdf | csv | json | ods; bom=true menambahkan UTF-8 BOM pada CSV
+//   - from, to: rentang tanggal YYYY-MM-DD (inklusif). Jika keduanya kosong dipakai weeks=N
+//     minggu terakhir (default 4) termasuk minggu berjalan.
+//   - granularity: day | week (default) | month, untuk tabel dan grafik tren
+//   - compare: none (default) | previous | last_year
+//
+// Parameter yang tidak valid dijawab 400 dengan daftar kesalahan per field.
 func (h *ReportHandler) GenerateReport(c *gin.Context) {
 	// 1. Ambil UserID dari token
 	userID, ok := helper.GetUserID(c)
@@ -33,84 +41,101 @@ func (h *ReportHandler) GenerateReport(c *gin.Context) {
 		return // Helper sudah mengirim respons 401
 	}
 
-	// 2. (Opsional) Ambil query param
-	// Contoh: /api/v1/reports/download?type=excel&weeks=4
-	reportType := c.DefaultQuery("type", "excel") // Default ke excel
-	numWeeksStr := c.DefaultQuery("weeks", "4")
-	numWeeks, _ := strconv.Atoi(numWeeksStr)
+	// 2. Validasi query param
+	cal := helper.GetCalendar(c)
+	query, errs := parseReportQuery(c, cal)
 
-	// 3. Ambil data dari Repository
-	// Kita panggil fungsi yang sama dengan yang dipakai dashboard
-	barData, err := h.reportRepo.GetSpendingByWeek(c.Request.Context(), userID, numWeeks)
+	reportType := c.DefaultQuery("type", report.TypeExcel)
+	bom, err := strconv.ParseBool(c.DefaultQuery("bom", "false"))
 	if err != nil {
+		errs = append(errs, model.FieldError{Field: "bom", Message: "Harus true atau false"})
+	}
+	writer, err := report.NewWriter(reportType, report.Options{CSVBOM: bom})
+	if err != nil {
+		errs = append(errs, model.FieldError{Field: "type", Message: "Harus salah satu dari: excel, pdf, csv, json, ods"})
+	}
+
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter laporan tidak valid", "details": errs})
+		return
+	}
+
+	// 3. Ambil data ringkasan; item dibaca belakangan saat ditulis ke response
+	ctx := c.Request.Context()
+	data, err := h.reportService.BuildReport(ctx, userID, cal, query)
+	if err != nil {
+		log.Printf("[ReportHandler] Gagal menyusun data laporan: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data laporan"})
 		return
 	}
 
-	// 4. Cek tipe laporan yang diminta
-	if reportType == "excel" {
-		// Panggil fungsi helper untuk membuat file Excel
-		buffer, err := h.createExcelReport(barData)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat file Excel"})
-			return
+	// 4. Tulis laporan langsung ke response. Header baru terkirim saat byte pertama
+	// ditulis, jadi error sebelum itu masih bisa dijawab dengan JSON.
+	fileName := fmt.Sprintf("Laporan_Belanja_%s_%s%s",
+		query.From.Format(calendar.DateLayout), query.To.Format(calendar.DateLayout), writer.Extension())
+	c.Header("Content-Description", "File Transfer")
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Header("Content-Type", writer.ContentType())
+	c.Status(http.StatusOK)
+
+	if err := writer.WriteReport(c.Writer, data, h.reportService.Items(ctx, userID, data)); err != nil {
+		log.Printf("[ReportHandler] Gagal menulis laporan %s: %v", reportType, err)
+		if !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			c.Header("Content-Description", "")
+			c.Header("Content-Type", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat file laporan"})
 		}
-
-		// 5. Kirim file ke user
-		fileName := fmt.Sprintf("Laporan_Mingguan_%d.xlsx", time.Now().Unix())
-
-		// Set Header HTTP agar browser men-download file
-		c.Header("Content-Description", "File Transfer")
-		c.Header("Content-Disposition", "attachment; filename="+fileName)
-		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buffer.Bytes())
-
-	} else if reportType == "pdf" {
-		// Logika untuk PDF (menggunakan gofpdf) akan ada di sini
-		c.JSON(http.StatusNotImplemented, gin.H{"error": "Download PDF belum diimplementasikan"})
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Tipe laporan tidak didukung"})
 	}
 }
 
-// createExcelReport adalah helper untuk men-generate file Excel
-func (h *ReportHandler) createExcelReport(data []model.SpendingByWeek) (*bytes.Buffer, error) {
-	f := excelize.NewFile()
-	sheetName := "Laporan Mingguan"
-	index, _ := f.NewSheet(sheetName) // Buat sheet baru
-
-	// Set Header Tabel
-	f.SetCellValue(sheetName, "A1", "Minggu Ke")
-	f.SetCellValue(sheetName, "B1", "Total Pengeluaran")
-
-	// Set Style untuk Header
-	style, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true},
-		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
-	})
-	f.SetCellStyle(sheetName, "A1", "B1", style)
-
-	// Isi data laporan
-	for i, item := range data {
-		row := i + 2 // Mulai dari baris 2
-		f.SetCellValue(sheetName, "A"+strconv.Itoa(row), item.MingguKe)
-		f.SetCellValue(sheetName, "B"+strconv.Itoa(row), item.Total)
-
-		// Set format mata uang (Contoh: Rp 123.456)
-		// Anda bisa buat ini lebih kompleks
-		f.SetCellStyle(sheetName, "B"+strconv.Itoa(row), "B"+strconv.Itoa(row),
-			style,
-		)
+// parseReportQuery membaca from/to/weeks/granularity/compare dan mengumpulkan
+// semua kesalahan validasi (bukan hanya yang pertama)
+func parseReportQuery(c *gin.Context, cal calendar.Calendar) (model.ReportQuery, []model.FieldError) {
+	var errs []model.FieldError
+	q := model.ReportQuery{
+		Granularity: c.DefaultQuery("granularity", model.GranularityWeek),
+		Compare:     c.DefaultQuery("compare", model.CompareNone),
 	}
 
-	f.SetActiveSheet(index)
-	f.DeleteSheet("Sheet1") // Hapus sheet default
-
-	// Simpan ke buffer (memory)
-	buffer, err := f.WriteToBuffer()
-	if err != nil {
-		log.Printf("Gagal menulis excel ke buffer: %v", err)
-		return nil, err
+	fromStr, toStr := c.Query("from"), c.Query("to")
+	switch {
+	case fromStr == "" && toStr == "":
+		weeks, err := strconv.Atoi(c.DefaultQuery("weeks", "4"))
+		if err != nil || weeks < 1 || weeks > 520 {
+			errs = append(errs, model.FieldError{Field: "weeks", Message: "Harus bilangan bulat antara 1 dan 520"})
+			break
+		}
+		now := cal.Now()
+		q.From = cal.WeekStartOf(now).AddDate(0, 0, -7*(weeks-1))
+		q.To = cal.EndOfDay(now)
+	case fromStr == "" || toStr == "":
+		errs = append(errs, model.FieldError{Field: "from", Message: "from dan to harus diisi bersamaan"})
+	default:
+		from, err := cal.ParseDate(fromStr)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "from", Message: "Format tanggal harus YYYY-MM-DD"})
+		}
+		to, err2 := cal.ParseDate(toStr)
+		if err2 != nil {
+			errs = append(errs, model.FieldError{Field: "to", Message: "Format tanggal harus YYYY-MM-DD"})
+		}
+		if err == nil && err2 == nil {
+			if to.Before(from) {
+				errs = append(errs, model.FieldError{Field: "to", Message: "Tidak boleh sebelum from"})
+			}
+			q.From, q.To = from, cal.EndOfDay(to)
+		}
 	}
 
-	return buffer, nil
+	if !model.ValidGranularity(q.Granularity) {
+		errs = append(errs, model.FieldError{Field: "granularity", Message: "Harus salah satu dari: day, week, month"})
+	} else if len(errs) == 0 && service.CountPeriods(cal, q.From, q.To, q.Granularity) > service.MaxTrendPeriods {
+		errs = append(errs, model.FieldError{Field: "granularity",
+			Message: fmt.Sprintf("Rentang terlalu panjang untuk granularitas ini (maksimal %d titik tren)", service.MaxTrendPeriods)})
+	}
+	if !model.ValidCompare(q.Compare) {
+		errs = append(errs, model.FieldError{Field: "compare", Message: "Harus salah satu dari: none, previous, last_year"})
+	}
+	return q, errs
 }
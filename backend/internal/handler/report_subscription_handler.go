@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/report"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// ReportSubscriptionHandler menangani langganan laporan berkala via email.
+type ReportSubscriptionHandler struct {
+	repo *repository.SubscriptionRepository
+}
+
+// NewReportSubscriptionHandler membuat instance ReportSubscriptionHandler baru.
+func NewReportSubscriptionHandler(repo *repository.SubscriptionRepository) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{repo: repo}
+}
+
+// ======================================================================
+// CREATE SUBSCRIPTION (POST /api/v1/report-subscriptions)
+// ======================================================================
+func (h *ReportSubscriptionHandler) CreateSubscription(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	sub, ok := bindReportSubscription(c)
+	if !ok {
+		return
+	}
+	sub.UserID = userID
+
+	if err := h.repo.CreateSubscription(c.Request.Context(), sub); err != nil {
+		log.Printf("[ReportSubscriptionHandler] Gagal membuat langganan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan langganan laporan"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Langganan laporan berhasil ditambahkan",
+		"data":    sub,
+	})
+}
+
+// ======================================================================
+// GET SUBSCRIPTIONS (GET /api/v1/report-subscriptions)
+// ======================================================================
+func (h *ReportSubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	subs, err := h.repo.GetSubscriptionsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[ReportSubscriptionHandler] Gagal mengambil langganan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil langganan laporan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": subs})
+}
+
+// ======================================================================
+// GET SUBSCRIPTION (GET /api/v1/report-subscriptions/:id)
+// ======================================================================
+func (h *ReportSubscriptionHandler) GetSubscription(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	subID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID langganan tidak valid"})
+		return
+	}
+
+	sub, err := h.repo.GetSubscriptionByID(c.Request.Context(), subID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Langganan laporan tidak ditemukan"})
+			return
+		}
+		log.Printf("[ReportSubscriptionHandler] Gagal mengambil langganan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil langganan laporan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sub})
+}
+
+// ======================================================================
+// UPDATE SUBSCRIPTION (PUT /api/v1/report-subscriptions/:id)
+// ======================================================================
+// UpdateSubscription mengganti pengaturan langganan; jadwal berikutnya dihitung ulang.
+func (h *ReportSubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	subID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID langganan tidak valid"})
+		return
+	}
+
+	sub, ok := bindReportSubscription(c)
+	if !ok {
+		return
+	}
+	sub.ID = subID
+	sub.UserID = userID
+
+	if err := h.repo.UpdateSubscription(c.Request.Context(), sub); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Langganan laporan tidak ditemukan"})
+			return
+		}
+		log.Printf("[ReportSubscriptionHandler] Gagal memperbarui langganan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui langganan laporan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Langganan laporan berhasil diperbarui",
+		"data":    sub,
+	})
+}
+
+// ======================================================================
+// DELETE SUBSCRIPTION (DELETE /api/v1/report-subscriptions/:id)
+// ======================================================================
+func (h *ReportSubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	subID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID langganan tidak valid"})
+		return
+	}
+
+	if err := h.repo.DeleteSubscription(c.Request.Context(), subID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Langganan laporan tidak ditemukan"})
+			return
+		}
+		log.Printf("[ReportSubscriptionHandler] Gagal menghapus langganan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus langganan laporan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Langganan laporan berhasil dihapus"})
+}
+
+// bindReportSubscription membaca dan memvalidasi body langganan, mengisi nilai default,
+// lalu menghitung jadwal kirim berikutnya di zona waktu user.
+func bindReportSubscription(c *gin.Context) (*model.ReportSubscription, bool) {
+	var req model.ReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return nil, false
+	}
+
+	sub := &model.ReportSubscription{
+		Format:    req.Format,
+		Frequency: req.Frequency,
+		Day:       model.DefaultReportDay,
+		Clock:     req.Clock,
+		Active:    true,
+	}
+	if sub.Format == "" {
+		sub.Format = report.TypePDF
+	}
+	if sub.Clock == "" {
+		sub.Clock = model.DefaultReportClock
+	}
+	if req.Day != nil {
+		sub.Day = *req.Day
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+
+	if _, err := report.NewWriter(sub.Format, report.Options{}); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format harus salah satu dari: excel, pdf, csv, json, ods"})
+		return nil, false
+	}
+	if err := sub.ValidateSchedule(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	sub.NextRunAt = sub.NextRun(helper.GetCalendar(c), time.Now())
+	return sub, true
+}
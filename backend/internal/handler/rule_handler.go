@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/categorizer"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// RuleHandler menangani logika HTTP untuk aturan kategorisasi otomatis.
+type RuleHandler struct {
+	repo        *repository.RuleRepository
+	itemRepo    *repository.ItemRepository
+	categorizer *service.CategorizeService
+}
+
+// NewRuleHandler membuat instance RuleHandler baru.
+func NewRuleHandler(repo *repository.RuleRepository, itemRepo *repository.ItemRepository, categorizer *service.CategorizeService) *RuleHandler {
+	return &RuleHandler{repo: repo, itemRepo: itemRepo, categorizer: categorizer}
+}
+
+// ======================================================================
+// CREATE RULE (POST /api/v1/kategori/rules)
+// ======================================================================
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	rule, ok := h.bindRule(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.CreateRule(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori tidak ditemukan"})
+			return
+		}
+		log.Printf("[RuleHandler] Gagal membuat aturan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan aturan"})
+		return
+	}
+	invalidateRules(c, h.categorizer)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Aturan berhasil ditambahkan", "data": rule})
+}
+
+// ======================================================================
+// GET ALL RULE (GET /api/v1/kategori/rules)
+// ======================================================================
+func (h *RuleHandler) GetRules(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.repo.GetRules(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[RuleHandler] Gagal mengambil aturan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar aturan"})
+		return
+	}
+
+	if rules == nil {
+		rules = []model.CategoryRule{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// ======================================================================
+// UPDATE RULE (PUT /api/v1/kategori/rules/:id)
+// ======================================================================
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID aturan tidak valid"})
+		return
+	}
+
+	rule, ok := h.bindRule(c, userID)
+	if !ok {
+		return
+	}
+	rule.ID = ruleID
+
+	if err := h.repo.UpdateRule(c.Request.Context(), rule); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Aturan tidak ditemukan"})
+			return
+		}
+		log.Printf("[RuleHandler] Gagal memperbarui aturan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui aturan"})
+		return
+	}
+	invalidateRules(c, h.categorizer)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Aturan berhasil diperbarui", "data": rule})
+}
+
+// ======================================================================
+// DELETE RULE (DELETE /api/v1/kategori/rules/:id)
+// ======================================================================
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID aturan tidak valid"})
+		return
+	}
+
+	if err := h.repo.DeleteRule(c.Request.Context(), ruleID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Aturan tidak ditemukan"})
+			return
+		}
+		log.Printf("[RuleHandler] Gagal menghapus aturan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus aturan"})
+		return
+	}
+	invalidateRules(c, h.categorizer)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Aturan berhasil dihapus"})
+}
+
+// ======================================================================
+// SUGGEST CATEGORY (GET /api/v1/kategori/suggest?nama_item=...)
+// ======================================================================
+func (h *RuleHandler) SuggestCategory(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	itemName := c.Query("nama_item")
+	if itemName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter nama_item wajib diisi"})
+		return
+	}
+
+	household, ok := helper.GetHousehold(c)
+	if !ok {
+		return
+	}
+
+	suggestion, err := h.categorizer.Suggest(c.Request.Context(), household.ID, userID, itemName)
+	if err != nil {
+		log.Printf("[RuleHandler] Gagal menebak kategori: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menebak kategori"})
+		return
+	}
+
+	// data bernilai null jika tidak ada aturan atau riwayat yang cocok
+	c.JSON(http.StatusOK, gin.H{"data": suggestion})
+}
+
+// bindRule membaca dan memvalidasi body request aturan.
+// Mengirim respons 400 dan mengembalikan false jika input tidak valid.
+func (h *RuleHandler) bindRule(c *gin.Context, userID int) (*model.CategoryRule, bool) {
+	var rule model.CategoryRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return nil, false
+	}
+
+	if rule.MatchType == "" {
+		rule.MatchType = model.RuleMatchKeyword
+	}
+	if rule.MatchType != model.RuleMatchKeyword && rule.MatchType != model.RuleMatchRegex {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "match_type harus 'keyword' atau 'regex'"})
+		return nil, false
+	}
+	if err := categorizer.ValidatePattern(rule.MatchType, rule.Pattern); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pola regex tidak valid", "details": err.Error()})
+		return nil, false
+	}
+
+	exists, err := h.itemRepo.CategoryExists(c.Request.Context(), rule.CategoryID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa kategori"})
+		return nil, false
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori tidak ditemukan"})
+		return nil, false
+	}
+
+	rule.UserID = userID
+	return &rule, true
+}
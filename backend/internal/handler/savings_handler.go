@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// SavingsHandler menangani logika HTTP untuk tujuan tabungan.
+type SavingsHandler struct {
+	repo    *repository.SavingsRepository
+	savings *service.SavingsService
+}
+
+// NewSavingsHandler membuat instance SavingsHandler baru.
+func NewSavingsHandler(repo *repository.SavingsRepository, savings *service.SavingsService) *SavingsHandler {
+	return &SavingsHandler{repo: repo, savings: savings}
+}
+
+// ======================================================================
+// CREATE GOAL (POST /api/v1/savings-goals)
+// ======================================================================
+func (h *SavingsHandler) CreateGoal(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	goal, ok := bindSavingsGoal(c)
+	if !ok {
+		return
+	}
+	goal.UserID = userID
+
+	if err := h.repo.CreateGoal(c.Request.Context(), goal); err != nil {
+		log.Printf("[SavingsHandler] Gagal membuat goal: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan tujuan tabungan"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tujuan tabungan berhasil ditambahkan",
+		"data":    goal,
+	})
+}
+
+// ======================================================================
+// GET GOALS (GET /api/v1/savings-goals)
+// ======================================================================
+// GetGoals mengembalikan semua goal beserta kemajuan dan proyeksi tanggal tercapainya.
+func (h *SavingsHandler) GetGoals(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	goals, err := h.repo.GetGoalsByUserID(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[SavingsHandler] Gagal mengambil goal: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tujuan tabungan"})
+		return
+	}
+
+	progress, err := h.savings.Progress(c.Request.Context(), userID, helper.GetCalendar(c), goals)
+	if err != nil {
+		log.Printf("[SavingsHandler] Gagal menghitung kemajuan goal: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung kemajuan tabungan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
+
+// ======================================================================
+// GET GOAL (GET /api/v1/savings-goals/:id)
+// ======================================================================
+// GetGoal mengembalikan kemajuan satu goal beserta riwayat setorannya.
+func (h *SavingsHandler) GetGoal(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	progress, ok := h.goalProgress(c, userID)
+	if !ok {
+		return
+	}
+
+	contributions, err := h.repo.GetContributions(c.Request.Context(), progress.ID)
+	if err != nil {
+		log.Printf("[SavingsHandler] Gagal mengambil setoran: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat setoran"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress, "kontribusi": contributions})
+}
+
+// ======================================================================
+// GET PROGRESS (GET /api/v1/savings-goals/:id/progress)
+// ======================================================================
+func (h *SavingsHandler) GetProgress(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	progress, ok := h.goalProgress(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": progress})
+}
+
+// ======================================================================
+// UPDATE GOAL (PUT /api/v1/savings-goals/:id)
+// ======================================================================
+func (h *SavingsHandler) UpdateGoal(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tujuan tabungan tidak valid"})
+		return
+	}
+
+	goal, ok := bindSavingsGoal(c)
+	if !ok {
+		return
+	}
+	goal.ID = goalID
+	goal.UserID = userID
+
+	if err := h.repo.UpdateGoal(c.Request.Context(), goal); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tujuan tabungan tidak ditemukan"})
+			return
+		}
+		log.Printf("[SavingsHandler] Gagal memperbarui goal: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui tujuan tabungan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tujuan tabungan berhasil diperbarui",
+		"data":    goal,
+	})
+}
+
+// ======================================================================
+// DELETE GOAL (DELETE /api/v1/savings-goals/:id)
+// ======================================================================
+func (h *SavingsHandler) DeleteGoal(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tujuan tabungan tidak valid"})
+		return
+	}
+
+	if err := h.repo.DeleteGoal(c.Request.Context(), goalID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tujuan tabungan tidak ditemukan"})
+			return
+		}
+		log.Printf("[SavingsHandler] Gagal menghapus goal: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus tujuan tabungan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tujuan tabungan berhasil dihapus"})
+}
+
+// ======================================================================
+// ADD CONTRIBUTION (POST /api/v1/savings-goals/:id/contributions)
+// ======================================================================
+// AddContribution mencatat setoran manual. Penarikan memakai WithdrawContribution.
+func (h *SavingsHandler) AddContribution(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	contribution, ok := bindContribution(c)
+	if !ok {
+		return
+	}
+	contribution.Source = model.ContributionManual
+
+	if err := h.repo.AddContribution(c.Request.Context(), userID, contribution); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tujuan tabungan tidak ditemukan"})
+			return
+		}
+		log.Printf("[SavingsHandler] Gagal menyimpan setoran: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan setoran"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Setoran berhasil dicatat",
+		"data":    contribution,
+	})
+}
+
+// ======================================================================
+// WITHDRAW (POST /api/v1/savings-goals/:id/withdrawals)
+// ======================================================================
+// WithdrawContribution mencatat penarikan dari goal. Saldo goal tidak boleh menjadi negatif.
+func (h *SavingsHandler) WithdrawContribution(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	withdrawal, ok := bindContribution(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Withdraw(c.Request.Context(), userID, withdrawal); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tujuan tabungan tidak ditemukan"})
+		case errors.Is(err, repository.ErrInsufficientSavings):
+			c.JSON(http.StatusConflict, gin.H{"error": "Jumlah penarikan melebihi saldo tabungan"})
+		default:
+			log.Printf("[SavingsHandler] Gagal menyimpan penarikan: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan penarikan"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Penarikan berhasil dicatat",
+		"data":    withdrawal,
+	})
+}
+
+// bindContribution membaca :id dan body setoran/penarikan. Jumlah harus lebih besar dari 0.
+// Mengirim respons 400 dan mengembalikan false jika input tidak valid.
+func bindContribution(c *gin.Context) (*model.SavingsContribution, bool) {
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tujuan tabungan tidak valid"})
+		return nil, false
+	}
+
+	var req model.ContributionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return nil, false
+	}
+	if req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah harus lebih besar dari 0"})
+		return nil, false
+	}
+
+	return &model.SavingsContribution{GoalID: goalID, Amount: req.Amount, Note: req.Note}, true
+}
+
+// goalProgress mengambil goal dari parameter :id lalu menghitung kemajuannya.
+// Mengirim respons error dan mengembalikan false jika gagal.
+func (h *SavingsHandler) goalProgress(c *gin.Context, userID int) (*model.SavingsProgress, bool) {
+	goalID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tujuan tabungan tidak valid"})
+		return nil, false
+	}
+
+	goal, err := h.repo.GetGoalByID(c.Request.Context(), goalID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tujuan tabungan tidak ditemukan"})
+			return nil, false
+		}
+		log.Printf("[SavingsHandler] Gagal mengambil goal: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tujuan tabungan"})
+		return nil, false
+	}
+
+	progress, err := h.savings.Progress(c.Request.Context(), userID, helper.GetCalendar(c), []model.SavingsGoal{*goal})
+	if err != nil {
+		log.Printf("[SavingsHandler] Gagal menghitung kemajuan goal: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung kemajuan tabungan"})
+		return nil, false
+	}
+	return &progress[0], true
+}
+
+// bindSavingsGoal membaca dan memvalidasi body request tujuan tabungan.
+// Mengirim respons 400 dan mengembalikan false jika input tidak valid.
+func bindSavingsGoal(c *gin.Context) (*model.SavingsGoal, bool) {
+	var req model.SavingsGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return nil, false
+	}
+	if req.Target <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target tabungan harus lebih besar dari 0"})
+		return nil, false
+	}
+
+	var deadline *time.Time
+	if req.Deadline != "" {
+		parsed, err := helper.GetCalendar(c).ParseDate(req.Deadline)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format deadline harus YYYY-MM-DD"})
+			return nil, false
+		}
+		deadline = &parsed
+	}
+
+	return &model.SavingsGoal{
+		Name:      req.Name,
+		Target:    req.Target,
+		Deadline:  deadline,
+		AutoSweep: req.AutoSweep,
+	}, true
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// SettingsHandler menangani logika HTTP untuk pengaturan user.
+type SettingsHandler struct {
+	repo *repository.SettingsRepository
+}
+
+// NewSettingsHandler membuat instance SettingsHandler baru.
+func NewSettingsHandler(repo *repository.SettingsRepository) *SettingsHandler {
+	return &SettingsHandler{repo: repo}
+}
+
+// ======================================================================
+// GET SETTINGS (GET /api/v1/settings)
+// ======================================================================
+func (h *SettingsHandler) GetSettings(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.repo.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[SettingsHandler] Gagal mengambil pengaturan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": settings})
+}
+
+// ======================================================================
+// UPDATE SETTINGS (PUT /api/v1/settings)
+// ======================================================================
+// UpdateSettings hanya mengubah field yang dikirim; field lain tetap.
+// Rollover, zona waktu dan awal minggu untuk budget bersama mengikuti pengaturan
+// owner household, jadi perubahan dari anggota lain hanya berlaku di household miliknya.
+func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.repo.GetSettings(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[SettingsHandler] Gagal mengambil pengaturan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pengaturan"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return
+	}
+	settings.UserID = userID
+
+	if !model.ValidRolloverPolicy(settings.RolloverPolicy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rollover_policy harus salah satu dari: none, full, capped, deficit_only"})
+		return
+	}
+	if settings.RolloverCap < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rollover_cap tidak boleh negatif"})
+		return
+	}
+	if _, err := calendar.New(settings.Timezone, time.Weekday(settings.WeekStart)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.SaveSettings(c.Request.Context(), settings); err != nil {
+		log.Printf("[SettingsHandler] Gagal menyimpan pengaturan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan pengaturan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pengaturan berhasil disimpan",
+		"data":    settings,
+	})
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/stream"
+)
+
+// streamRetry adalah jeda (ms) yang disarankan ke EventSource sebelum menyambung ulang
+const streamRetry = 3000
+
+// streamTicketTTL adalah masa berlaku tiket stream; cukup untuk membuka EventSource
+// segera setelah tiket diminta
+const streamTicketTTL = 30 * time.Second
+
+// StreamHandler menangani koneksi Server-Sent Events untuk update live.
+type StreamHandler struct {
+	hub       *stream.Hub
+	tickets   *repository.StreamTicketRepository
+	heartbeat time.Duration
+}
+
+// NewStreamHandler membuat instance StreamHandler baru.
+func NewStreamHandler(hub *stream.Hub, tickets *repository.StreamTicketRepository, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{hub: hub, tickets: tickets, heartbeat: heartbeat}
+}
+
+// ======================================================================
+// CREATE TICKET (POST /api/v1/stream/tickets)
+// ======================================================================
+// CreateTicket membuat tiket sekali pakai untuk membuka /api/v1/stream?ticket=...
+// EventSource tidak bisa mengirim header Authorization, jadi klien meminta tiket
+// dengan JWT-nya lalu memakai tiket itu di query string. Tiket baru diperlukan
+// setiap kali menyambung ulang.
+func (h *StreamHandler) CreateTicket(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	ticket, expiresAt, err := h.tickets.CreateTicket(c.Request.Context(), userID, streamTicketTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat tiket stream"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{"ticket": ticket, "expires_at": expiresAt}})
+}
+
+// ======================================================================
+// STREAM (GET /api/v1/stream)
+// ======================================================================
+// Stream mengirim event perubahan item, budget dan kategori di household aktif user
+// secara live, termasuk perubahan yang dilakukan anggota lain.
+// Event bernama <entitas>.<aksi> (misal "item.create") dengan id berurutan; klien yang
+// menyambung ulang dengan header Last-Event-ID menerima event yang terlewat. Jika ID itu
+// sudah tidak ada di riwayat, event "reset" dikirim dan klien perlu memuat ulang data.
+// Event "heartbeat" dikirim berkala selama tidak ada perubahan.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+	household, ok := helper.GetHousehold(c)
+	if !ok {
+		return
+	}
+
+	lastEventID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	sub, backlog, resumed := h.hub.Subscribe(household.ID, userID, lastEventID)
+	defer h.hub.Unsubscribe(sub)
+
+	// Koneksi stream berumur panjang, jadi WriteTimeout server tidak berlaku di sini
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("[StreamHandler] Gagal melepas write deadline: %v", err)
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Matikan buffering di proxy nginx
+	c.Status(http.StatusOK)
+
+	if !resumed {
+		c.Render(-1, sse.Event{Event: "reset", Retry: streamRetry, Data: gin.H{"last_event_id": lastEventID}})
+	} else {
+		c.Render(-1, sse.Event{Event: "ready", Retry: streamRetry, Data: gin.H{"backlog": len(backlog)}})
+	}
+	for _, e := range backlog {
+		renderStreamEvent(c, e)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// Langganan diputus hub (termasuk jika user dikeluarkan dari household);
+				// klien akan menyambung ulang dengan Last-Event-ID
+				return
+			}
+			renderStreamEvent(c, e)
+			c.Writer.Flush()
+		case t := <-ticker.C:
+			c.Render(-1, sse.Event{Event: "heartbeat", Data: t.Unix()})
+			c.Writer.Flush()
+		}
+	}
+}
+
+func renderStreamEvent(c *gin.Context, e stream.Event) {
+	c.Render(-1, sse.Event{Id: strconv.FormatInt(e.ID, 10), Event: e.Type, Data: e.Data})
+}
+
+// publishEvent mengirim perubahan entitas oleh userID ke stream household aktifnya.
+// Sama seperti log audit, kegagalan hanya dicatat ke log agar tidak menggagalkan
+// operasi yang sudah berhasil.
+func publishEvent(c *gin.Context, hub *stream.Hub, userID int, entity, action string, data any) {
+	h, ok := helper.LookupHousehold(c)
+	if !ok {
+		log.Printf("[Stream] Household tidak ada di context, event %s.%s tidak di-publish", entity, action)
+		return
+	}
+	if err := hub.Publish(context.WithoutCancel(c.Request.Context()), h.ID, userID, entity+"."+action, data); err != nil {
+		log.Printf("[Stream] Gagal mem-publish event %s.%s: %v", entity, action, err)
+	}
+}
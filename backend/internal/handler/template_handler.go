@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// TemplateHandler menangani logika HTTP untuk template daftar belanja.
+type TemplateHandler struct {
+	repo        *repository.TemplateRepository
+	itemRepo    *repository.ItemRepository
+	categorizer *service.CategorizeService
+}
+
+// NewTemplateHandler membuat instance TemplateHandler baru.
+func NewTemplateHandler(repo *repository.TemplateRepository, itemRepo *repository.ItemRepository, categorizer *service.CategorizeService) *TemplateHandler {
+	return &TemplateHandler{repo: repo, itemRepo: itemRepo, categorizer: categorizer}
+}
+
+// ======================================================================
+// CREATE TEMPLATE (POST /api/v1/templates)
+// ======================================================================
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	tmpl, ok := h.bindTemplate(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.CreateTemplate(c.Request.Context(), tmpl); err != nil {
+		log.Printf("[TemplateHandler] Gagal membuat template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan template", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Template berhasil ditambahkan",
+		"data":    tmpl,
+	})
+}
+
+// ======================================================================
+// GET ALL TEMPLATE (GET /api/v1/templates)
+// ======================================================================
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	templates, err := h.repo.GetTemplatesByUserID(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[TemplateHandler] Gagal mengambil daftar template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil daftar template"})
+		return
+	}
+
+	if templates == nil {
+		templates = []model.ItemTemplate{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": templates})
+}
+
+// ======================================================================
+// UPDATE TEMPLATE (PUT /api/v1/templates/:id)
+// ======================================================================
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID template tidak valid"})
+		return
+	}
+
+	tmpl, ok := h.bindTemplate(c, userID)
+	if !ok {
+		return
+	}
+	tmpl.ID = templateID
+
+	if err := h.repo.UpdateTemplate(c.Request.Context(), tmpl); err != nil {
+		log.Printf("[TemplateHandler] Error update template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui template", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Template berhasil diperbarui",
+		"data":    tmpl,
+	})
+}
+
+// ======================================================================
+// DELETE TEMPLATE (DELETE /api/v1/templates/:id)
+// ======================================================================
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID template tidak valid"})
+		return
+	}
+
+	if err := h.repo.DeleteTemplate(c.Request.Context(), templateID, userID); err != nil {
+		log.Printf("[TemplateHandler] Error delete template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus template", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template berhasil dihapus"})
+}
+
+// ======================================================================
+// APPLY TEMPLATE (POST /api/v1/templates/:id/apply)
+// ======================================================================
+// ApplyTemplate mengisi daftar belanja minggu ini dengan item 'planned' dari template.
+// Template yang sudah diterapkan untuk minggu ini tidak akan diterapkan dua kali.
+func (h *TemplateHandler) ApplyTemplate(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID template tidak valid"})
+		return
+	}
+
+	tmpl, err := h.repo.GetTemplateByID(c.Request.Context(), templateID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template tidak ditemukan"})
+		return
+	}
+
+	cal := helper.GetCalendar(c)
+	created, err := h.repo.ApplyTemplate(c.Request.Context(), tmpl, cal, cal.Now())
+	if err != nil {
+		log.Printf("[TemplateHandler] Gagal menerapkan template: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menerapkan template"})
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, gin.H{"message": "Template sudah diterapkan untuk minggu ini", "created": false})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Template berhasil diterapkan untuk minggu ini", "created": true})
+}
+
+// bindTemplate membaca dan memvalidasi body request template.
+// Mengirim respons 400 dan mengembalikan false jika input tidak valid.
+func (h *TemplateHandler) bindTemplate(c *gin.Context, userID int) (*model.ItemTemplate, bool) {
+	var req model.TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "details": err.Error()})
+		return nil, false
+	}
+
+	if req.Recurrence == "" {
+		req.Recurrence = model.RecurrenceNone
+	}
+	if !model.ValidRecurrence(req.Recurrence) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Recurrence harus salah satu dari: none, weekly, biweekly, monthly"})
+		return nil, false
+	}
+
+	cal := helper.GetCalendar(c)
+	startDate := cal.Now()
+	if req.StartDate != "" {
+		parsed, err := cal.ParseDate(req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format start_date harus YYYY-MM-DD"})
+			return nil, false
+		}
+		startDate = parsed
+	}
+
+	for i := range req.Entries {
+		e := &req.Entries[i]
+		if e.Quantity <= 0 || e.UnitPrice < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah item harus lebih dari 0 dan harga tidak boleh negatif"})
+			return nil, false
+		}
+		if e.CategoryID == nil {
+			// Tebak kategori dari aturan/riwayat user; entry tetap tanpa kategori jika tidak ada saran
+			household, ok := helper.GetHousehold(c)
+			if !ok {
+				return nil, false
+			}
+			suggestion, err := h.categorizer.Suggest(c.Request.Context(), household.ID, userID, e.ItemName)
+			if err != nil {
+				log.Printf("[TemplateHandler] Gagal menebak kategori: %v", err)
+			} else if suggestion != nil {
+				e.CategoryID = &suggestion.CategoryID
+			}
+			continue
+		}
+		exists, err := h.itemRepo.CategoryExists(c.Request.Context(), *e.CategoryID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa kategori"})
+			return nil, false
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori tidak ditemukan"})
+			return nil, false
+		}
+	}
+
+	return &model.ItemTemplate{
+		UserID:     userID,
+		Name:       req.Name,
+		Recurrence: req.Recurrence,
+		StartDate:  startDate,
+		Entries:    req.Entries,
+	}, true
+}
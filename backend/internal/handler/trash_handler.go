@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// TrashHandler menangani logika HTTP untuk trash (data yang sudah dihapus).
+type TrashHandler struct {
+	repo        *repository.TrashRepository
+	categorizer *service.CategorizeService
+}
+
+// NewTrashHandler membuat instance TrashHandler baru.
+func NewTrashHandler(r *repository.TrashRepository, categorizer *service.CategorizeService) *TrashHandler {
+	return &TrashHandler{repo: r, categorizer: categorizer}
+}
+
+// ======================================================================
+// GET TRASH (GET /api/v1/trash)
+// ======================================================================
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.repo.GetTrashByUserID(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[TrashHandler] Gagal mengambil trash: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data trash"})
+		return
+	}
+
+	if entries == nil {
+		entries = []model.TrashEntry{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
+
+// ======================================================================
+// RESTORE (POST /api/v1/trash/:type/:id/restore)
+// ======================================================================
+func (h *TrashHandler) RestoreTrash(c *gin.Context) {
+	userID, ok := helper.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	entryType := c.Param("type")
+	if entryType != model.TrashTypeItem && entryType != model.TrashTypeCategory && entryType != model.TrashTypeBudget {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tipe harus salah satu dari: item, kategori, budget"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+		return
+	}
+
+	if err := h.repo.Restore(c.Request.Context(), entryType, id, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data tidak ditemukan di trash"})
+			return
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Sudah ada kategori aktif dengan nama yang sama"})
+			return
+		}
+		if errors.Is(err, repository.ErrOverlap) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Periode budget bertabrakan dengan budget aktif lain"})
+			return
+		}
+		log.Printf("[TrashHandler] Gagal memulihkan %s %d: %v", entryType, id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan data"})
+		return
+	}
+	if entryType == model.TrashTypeCategory {
+		invalidateRules(c, h.categorizer)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Data berhasil dipulihkan"})
+}
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+)
+
+const calendarKey = "calendar"
+
+// SetCalendar menyimpan kalender user ke context (dipanggil oleh CalendarMiddleware)
+func SetCalendar(c *gin.Context, cal calendar.Calendar) {
+	c.Set(calendarKey, cal)
+}
+
+// GetCalendar mengambil kalender user dari context.
+// Jika belum dimuat, kalender bawaan (Asia/Jakarta, minggu dimulai hari Minggu) dipakai.
+func GetCalendar(c *gin.Context) calendar.Calendar {
+	if value, exists := c.Get(calendarKey); exists {
+		if cal, ok := value.(calendar.Calendar); ok {
+			return cal
+		}
+	}
+	return calendar.Default()
+}
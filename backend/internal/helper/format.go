@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var namaBulan = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// FormatRupiah memformat angka dengan pemisah ribuan titik, misal "Rp 1.250.000".
+// Nilai dibulatkan ke rupiah terdekat.
+func FormatRupiah(amount float64) string {
+	return "Rp " + FormatNumber(amount)
+}
+
+// FormatNumber memformat bilangan bulat gaya Indonesia (pemisah ribuan titik), misal "-1.250"
+func FormatNumber(amount float64) string {
+	n := int64(math.Round(amount))
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	digits := strconv.FormatInt(n, 10)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// FormatTanggal memformat tanggal gaya Indonesia, misal "7 Oktober 2026"
+func FormatTanggal(t time.Time) string {
+	return strconv.Itoa(t.Day()) + " " + namaBulan[t.Month()-1] + " " + strconv.Itoa(t.Year())
+}
+
+// FormatPeriode memformat rentang tanggal, misal "1 Oktober 2026 - 7 Oktober 2026"
+func FormatPeriode(start, end time.Time) string {
+	return FormatTanggal(start) + " - " + FormatTanggal(end)
+}
+
+// FormatPersen memformat persentase dengan satu angka desimal dan koma, misal "12,5%"
+func FormatPersen(v float64) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', 1, 64), ".", ",", 1) + "%"
+}
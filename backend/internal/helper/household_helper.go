@@ -0,0 +1,37 @@
+package helper
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+const householdKey = "household"
+
+// SetHousehold menyimpan household aktif user ke context (dipanggil oleh HouseholdMiddleware)
+func SetHousehold(c *gin.Context, h *model.Household) {
+	c.Set(householdKey, h)
+}
+
+// LookupHousehold mengambil household aktif user dari context tanpa mengirim respons
+func LookupHousehold(c *gin.Context) (*model.Household, bool) {
+	if value, exists := c.Get(householdKey); exists {
+		if h, ok := value.(*model.Household); ok {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// GetHousehold mengambil household aktif user beserta role-nya dari context.
+// Jika belum dimuat (HouseholdMiddleware tidak terpasang), respons 500 dikirim.
+func GetHousehold(c *gin.Context) (*model.Household, bool) {
+	if h, ok := LookupHousehold(c); ok {
+		return h, true
+	}
+	log.Println("[Helper] Household tidak ditemukan di context (HouseholdMiddleware belum dipasang?)")
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Household aktif tidak ditemukan"})
+	return nil, false
+}
@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// GetPagination membaca query param ?page=N&limit=M (page dimulai dari 1).
+// Mengirim respons 400 dan mengembalikan false jika nilainya tidak valid.
+func GetPagination(c *gin.Context) (page int, limit int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter page tidak valid"})
+		return 0, 0, false
+	}
+
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter limit tidak valid"})
+		return 0, 0, false
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return page, limit, true
+}
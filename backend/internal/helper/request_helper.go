@@ -0,0 +1,15 @@
+package helper
+
+import "github.com/gin-gonic/gin"
+
+const requestIDKey = "request_id"
+
+// SetRequestID menyimpan ID request ke context (dipanggil oleh RequestIDMiddleware)
+func SetRequestID(c *gin.Context, id string) {
+	c.Set(requestIDKey, id)
+}
+
+// GetRequestID mengambil ID request dari context; kosong jika middleware tidak terpasang
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
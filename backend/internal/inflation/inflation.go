@@ -0,0 +1,156 @@
+// Package inflation menghitung indeks harga pribadi bergaya Laspeyres dari riwayat
+// belanja user sendiri. Keranjang (produk dan kuantitasnya) ditetapkan dari periode
+// dasar, lalu dinilai ulang dengan harga setiap bulan sehingga yang terukur adalah
+// perubahan harga, bukan perubahan pola belanja. Pembelian bulanan per produk
+// disiapkan oleh InflationService sebelum Compute dipanggil.
+package inflation
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// Default rentang indeks: 24 bulan dengan 12 bulan pertama sebagai periode dasar,
+// sehingga 12 bulan terakhir punya perbandingan tahunan dan musim ikut terwakili di bobot.
+const (
+	DefaultMonths     = 24
+	DefaultBaseMonths = 12
+	MaxMonths         = 120
+)
+
+// Input adalah data yang dibutuhkan untuk menghitung indeks
+type Input struct {
+	Start      time.Time // Tanggal 1 bulan pertama (awal periode dasar)
+	Months     int       // Jumlah bulan yang dihitung, termasuk periode dasar
+	BaseMonths int       // Jumlah bulan pertama yang menjadi periode dasar
+
+	// Pembelian per produk per bulan dalam rentang Start .. Start+Months. Bulan
+	// dicocokkan berdasarkan tahun dan bulan saja, zona waktu diabaikan.
+	Purchases []model.ProductMonth
+}
+
+// basketItem adalah satu produk di keranjang periode dasar
+type basketItem struct {
+	category  string
+	quantity  float64 // q0: total kuantitas di periode dasar
+	basePrice float64 // p0: harga satuan rata-rata tertimbang di periode dasar
+	price     float64 // Harga terakhir yang teramati (dibawa maju jika bulan ini tidak dibeli)
+}
+
+// Compute menghitung indeks setiap bulan dari Start. Harga dasar p0 adalah harga rata-rata
+// tertimbang selama periode dasar, sehingga indeks = 100 berarti harga sama dengan rata-rata
+// periode dasar:
+//
+//	I(t) = Σ p(t)·q0 / Σ p0·q0 × 100
+//
+// Produk yang tidak dibeli pada suatu bulan memakai harga terakhirnya (carry forward).
+// Produk yang baru muncul setelah periode dasar tidak masuk keranjang. Jika periode
+// dasar kosong, Months juga kosong.
+func Compute(in Input) *model.InflationIndex {
+	start := monthStart(in.Start)
+	baseEnd := start.AddDate(0, in.BaseMonths, 0)
+	result := &model.InflationIndex{
+		BaseStart: start,
+		BaseEnd:   baseEnd.AddDate(0, 0, -1),
+		Bobot:     []model.InflationWeight{},
+		Months:    []model.InflationMonth{},
+	}
+
+	// Harga bulanan per produk, dan keranjang dari periode dasar
+	prices := make(map[string]map[string]float64) // bulan -> produk -> harga
+	basket := make(map[string]*basketItem)
+	baseSpend := make(map[string]float64)
+	for _, p := range in.Purchases {
+		if p.Kuantitas <= 0 || p.Total <= 0 {
+			continue
+		}
+		// Bulan dari database tidak membawa zona waktu; baca ulang di zona waktu Start
+		month := time.Date(p.Bulan.Year(), p.Bulan.Month(), 1, 0, 0, 0, 0, start.Location())
+		key := month.Format("2006-01")
+		if prices[key] == nil {
+			prices[key] = make(map[string]float64)
+		}
+		prices[key][p.Produk] = p.Total / p.Kuantitas
+
+		if !month.Before(start) && month.Before(baseEnd) {
+			b := basket[p.Produk]
+			if b == nil {
+				b = &basketItem{category: p.Kategori}
+				basket[p.Produk] = b
+			}
+			b.quantity += p.Kuantitas
+			baseSpend[p.Produk] += p.Total
+		}
+	}
+
+	var total float64
+	weights := make(map[string]float64)
+	for product, b := range basket {
+		b.basePrice = baseSpend[product] / b.quantity
+		b.price = b.basePrice
+		total += baseSpend[product]
+		weights[b.category] += baseSpend[product]
+	}
+	if total == 0 {
+		return result
+	}
+	result.JumlahProduk = len(basket)
+	result.BelanjaDasar = total
+
+	categories := make([]string, 0, len(weights))
+	for c := range weights {
+		categories = append(categories, c)
+	}
+	// Kategori berbobot terbesar lebih dulu agar urutan kontribusi stabil di setiap bulan
+	sort.Slice(categories, func(i, j int) bool {
+		if weights[categories[i]] != weights[categories[j]] {
+			return weights[categories[i]] > weights[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+	for _, c := range categories {
+		result.Bobot = append(result.Bobot, model.InflationWeight{Kategori: c, Bobot: weights[c] / total * 100})
+	}
+
+	for i := 0; i < in.Months; i++ {
+		month := start.AddDate(0, i, 0)
+		observed := prices[month.Format("2006-01")]
+
+		var value, covered float64
+		delta := make(map[string]float64)
+		for product, b := range basket {
+			if price, ok := observed[product]; ok {
+				b.price = price
+				covered += b.basePrice * b.quantity
+			}
+			value += b.price * b.quantity
+			delta[b.category] += (b.price - b.basePrice) * b.quantity
+		}
+
+		m := model.InflationMonth{
+			Bulan:      month,
+			Label:      month.Format("2006-01"),
+			Indeks:     value / total * 100,
+			Cakupan:    covered / total * 100,
+			Kontribusi: make([]model.InflationContribution, 0, len(categories)),
+		}
+		for _, c := range categories {
+			m.Kontribusi = append(m.Kontribusi, model.InflationContribution{Kategori: c, Kontribusi: delta[c] / total * 100})
+		}
+		if i >= 1 {
+			m.PerubahanBulanan = model.PercentChange(m.Indeks, result.Months[i-1].Indeks)
+		}
+		if i >= 12 {
+			m.PerubahanTahunan = model.PercentChange(m.Indeks, result.Months[i-12].Indeks)
+		}
+		result.Months = append(result.Months, m)
+	}
+	return result
+}
+
+// monthStart mengembalikan tanggal 1 bulan t pada zona waktu t
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
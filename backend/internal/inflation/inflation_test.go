@@ -0,0 +1,100 @@
+package inflation
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestComputeEmptyBase(t *testing.T) {
+	start := month(2026, time.January)
+
+	tests := []struct {
+		name      string
+		purchases []model.ProductMonth
+	}{
+		{"tanpa pembelian", nil},
+		{"hanya pembelian setelah periode dasar", []model.ProductMonth{
+			{Produk: "beras", Kategori: "Pokok", Bulan: month(2026, time.March), Kuantitas: 1, Total: 65000},
+		}},
+		{"kuantitas atau total nol diabaikan", []model.ProductMonth{
+			{Produk: "beras", Kategori: "Pokok", Bulan: start, Kuantitas: 0, Total: 65000},
+			{Produk: "gula", Kategori: "Pokok", Bulan: start, Kuantitas: 2, Total: 0},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compute(Input{Start: start, Months: 3, BaseMonths: 1, Purchases: tt.purchases})
+			if len(got.Months) != 0 || len(got.Bobot) != 0 {
+				t.Errorf("expected empty index, got %d months and %d weights", len(got.Months), len(got.Bobot))
+			}
+			if got.JumlahProduk != 0 || got.BelanjaDasar != 0 {
+				t.Errorf("JumlahProduk = %d, BelanjaDasar = %v, want 0", got.JumlahProduk, got.BelanjaDasar)
+			}
+			if want := month(2026, time.January).AddDate(0, 1, -1); !got.BaseEnd.Equal(want) {
+				t.Errorf("BaseEnd = %v, want %v", got.BaseEnd, want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	start := month(2026, time.January)
+	purchases := []model.ProductMonth{
+		// Periode dasar (Januari): bobot Pokok 80%, Minuman 20%
+		{Produk: "beras", Kategori: "Pokok", Bulan: start, Kuantitas: 2, Total: 80000},
+		{Produk: "teh", Kategori: "Minuman", Bulan: start, Kuantitas: 4, Total: 20000},
+		// Februari: beras naik 10%, teh tidak dibeli (harga dibawa maju)
+		{Produk: "beras", Kategori: "Pokok", Bulan: month(2026, time.February), Kuantitas: 1, Total: 44000},
+		// Maret: produk baru tidak masuk keranjang
+		{Produk: "kopi", Kategori: "Minuman", Bulan: month(2026, time.March), Kuantitas: 1, Total: 30000},
+	}
+
+	got := Compute(Input{Start: start, Months: 3, BaseMonths: 1, Purchases: purchases})
+
+	if got.JumlahProduk != 2 || got.BelanjaDasar != 100000 {
+		t.Fatalf("JumlahProduk = %d, BelanjaDasar = %v, want 2 and 100000", got.JumlahProduk, got.BelanjaDasar)
+	}
+	if len(got.Bobot) != 2 || got.Bobot[0].Kategori != "Pokok" || got.Bobot[0].Bobot != 80 {
+		t.Errorf("Bobot = %+v, want Pokok 80%% first", got.Bobot)
+	}
+
+	tests := []struct {
+		label   string
+		indeks  float64
+		cakupan float64
+	}{
+		{"2026-01", 100, 100},
+		{"2026-02", 108, 80},
+		{"2026-03", 108, 0},
+	}
+	if len(got.Months) != len(tests) {
+		t.Fatalf("got %d months, want %d", len(got.Months), len(tests))
+	}
+	for i, tt := range tests {
+		m := got.Months[i]
+		if m.Label != tt.label {
+			t.Errorf("month %d label = %s, want %s", i, m.Label, tt.label)
+		}
+		if math.Abs(m.Indeks-tt.indeks) > 1e-9 {
+			t.Errorf("%s Indeks = %v, want %v", tt.label, m.Indeks, tt.indeks)
+		}
+		if math.Abs(m.Cakupan-tt.cakupan) > 1e-9 {
+			t.Errorf("%s Cakupan = %v, want %v", tt.label, m.Cakupan, tt.cakupan)
+		}
+	}
+
+	if got.Months[0].PerubahanBulanan != nil {
+		t.Errorf("first month should have no monthly change")
+	}
+	if c := got.Months[1].PerubahanBulanan; c == nil || math.Abs(*c-8) > 1e-9 {
+		t.Errorf("February PerubahanBulanan = %v, want 8", c)
+	}
+}
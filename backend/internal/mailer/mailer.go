@@ -0,0 +1,232 @@
+// Package mailer menyediakan antarmuka pengiriman email beserta implementasi
+// SMTP, implementasi lokal yang hanya mencatat pesan ke log (untuk development), dan
+// implementasi yang menampung pesan di memori (untuk test).
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attachment adalah lampiran email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message adalah satu email yang akan dikirim
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string // Teks biasa (UTF-8)
+	Attachments []Attachment
+}
+
+// Mailer mengirim email. Implementasi harus aman dipakai dari banyak goroutine.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New memilih implementasi mailer: SMTP jika addr diisi, selain itu LogMailer.
+func New(addr, username, password, from string) Mailer {
+	if addr == "" {
+		log.Println("[Mailer] SMTP_ADDR tidak diset, email hanya dicatat ke log")
+		return NewLogMailer()
+	}
+	return &SMTPMailer{addr: addr, username: username, password: password, from: from}
+}
+
+// ======================================================================
+// LOG MAILER
+// ======================================================================
+
+// LogMailer tidak mengirim email ke luar; pesan hanya dicatat ke log.
+type LogMailer struct{}
+
+// NewLogMailer membuat LogMailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send mencatat pesan ke log
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("[Mailer] Email tidak dikirim untuk %s: %q (%d lampiran)", strings.Join(msg.To, ", "), msg.Subject, len(msg.Attachments))
+	return nil
+}
+
+// ======================================================================
+// CAPTURE MAILER
+// ======================================================================
+
+// CaptureMailer tidak mengirim email ke luar; pesan disimpan di memori agar bisa
+// diperiksa oleh test. Jangan dipakai di server karena pesan tidak pernah dibuang.
+type CaptureMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewCaptureMailer membuat CaptureMailer kosong
+func NewCaptureMailer() *CaptureMailer {
+	return &CaptureMailer{}
+}
+
+// Send menampung pesan
+func (m *CaptureMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages mengembalikan salinan semua pesan yang sudah ditampung
+func (m *CaptureMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// ======================================================================
+// SMTP MAILER
+// ======================================================================
+
+// SMTPMailer mengirim email lewat server SMTP (STARTTLS jika didukung server)
+type SMTPMailer struct {
+	addr     string
+	username string
+	password string
+	from     string
+}
+
+// Send mengirim pesan lewat SMTP. Pembatalan ctx menghentikan koneksi.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mailer: message has no recipients")
+	}
+	body, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(m.addr)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid SMTP address: %w", err)
+	}
+
+	dialer := net.Dialer{Timeout: 15 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return fmt.Errorf("mailer: failed to connect: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mailer: failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			return fmt.Errorf("mailer: STARTTLS failed: %w", err)
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, host)); err != nil {
+			return fmt.Errorf("mailer: authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(m.from); err != nil {
+		return fmt.Errorf("mailer: MAIL FROM failed: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("mailer: RCPT TO %s failed: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mailer: DATA failed: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("mailer: failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mailer: failed to send message: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMIME menyusun pesan MIME: teks biasa, atau multipart/mixed jika ada lampiran
+func buildMIME(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(msg.Attachments) == 0 {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		writeBase64(&buf, []byte(msg.Body))
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mailer: failed to build body: %w", err)
+	}
+	writeBase64(part, []byte(msg.Body))
+
+	for _, a := range msg.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mailer: failed to build attachment: %w", err)
+		}
+		writeBase64(part, a.Data)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("mailer: failed to close message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 menulis data base64 dengan baris maksimal 76 karakter (RFC 2045)
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
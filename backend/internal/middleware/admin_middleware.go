@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// AdminMiddleware hanya meneruskan request dari user ber-role admin. Harus dipasang
+// setelah AuthMiddleware. Role dibaca dari database, bukan dari klaim token, agar
+// pencabutan akses admin langsung berlaku tanpa menunggu token kedaluwarsa.
+func AdminMiddleware(userRepo *repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := helper.GetUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.GetUserByID(c.Request.Context(), userID)
+		if err != nil {
+			log.Printf("[AdminMiddleware] Gagal memuat user %d: %v", userID, err)
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+			c.Abort()
+			return
+		}
+		if user.Role != model.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Hanya admin yang dapat mengakses endpoint ini"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
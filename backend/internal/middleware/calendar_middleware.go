@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// CalendarMiddleware memuat kalender (zona waktu dan awal minggu) ke context. Jika
+// HouseholdMiddleware sudah dipasang sebelumnya, kalender household (pengaturan owner)
+// yang dipakai agar semua anggota melihat periode budget yang sama; selain itu
+// kalender user sendiri. Harus dipasang setelah AuthMiddleware. Handler membacanya
+// lewat helper.GetCalendar.
+func CalendarMiddleware(settingsRepo *repository.SettingsRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if id, ok := userID.(int); exists && ok {
+			var cal calendar.Calendar
+			var err error
+			if h, ok := helper.LookupHousehold(c); ok {
+				cal, err = settingsRepo.GetHouseholdCalendar(c.Request.Context(), h.ID)
+			} else {
+				cal, err = settingsRepo.GetCalendar(c.Request.Context(), id)
+			}
+			if err != nil {
+				// Bukan error fatal, handler akan memakai kalender bawaan
+				log.Printf("[CalendarMiddleware] Gagal memuat kalender user %d: %v", id, err)
+			} else {
+				helper.SetCalendar(c, cal)
+			}
+		}
+		c.Next()
+	}
+}
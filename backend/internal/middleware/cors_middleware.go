@@ -16,7 +16,10 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 
 		// Header yang diizinkan untuk dikirim oleh client (sangat penting untuk Authorization)
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID")
+
+		// Header respons yang boleh dibaca oleh frontend
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		// Mengizinkan credentials (seperti cookies/session)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
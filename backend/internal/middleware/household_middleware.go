@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// HouseholdMiddleware memuat household aktif user beserta role-nya ke context. Harus
+// dipasang setelah AuthMiddleware. Jika user sudah dikeluarkan dari household aktifnya,
+// household lain diaktifkan (atau household pribadi baru dibuat) agar request tetap jalan.
+// Handler membacanya lewat helper.GetHousehold.
+func HouseholdMiddleware(householdRepo *repository.HouseholdRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := helper.GetUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		h, err := householdRepo.EnsureActiveHousehold(c.Request.Context(), userID)
+		if err != nil {
+			log.Printf("[HouseholdMiddleware] Gagal memuat household user %d: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memuat household"})
+			c.Abort()
+			return
+		}
+		helper.SetHousehold(c, h)
+		c.Next()
+	}
+}
+
+// HouseholdWriterMiddleware menolak request dari anggota ber-role viewer. Pasang pada
+// route yang mengubah data bersama (item, kategori, budget, template), setelah
+// HouseholdMiddleware. Query repository tetap memeriksa role sebagai lapisan kedua.
+func HouseholdWriterMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h, ok := helper.GetHousehold(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+		if !model.CanWrite(h.Role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Role viewer hanya dapat melihat data household"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedQueryParams adalah parameter query berisi kredensial yang tidak boleh
+// tercatat utuh di access log
+var redactedQueryParams = []string{"ticket", "access_token"}
+
+// LoggerMiddleware adalah pengganti gin.Logger dengan format yang sama, tetapi nilai
+// kredensial di query string (misal ?ticket= pada /api/v1/stream) disamarkan.
+func LoggerMiddleware() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactQuery(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery menyamarkan nilai redactedQueryParams pada path beserta query-nya
+func redactQuery(path string) string {
+	i := strings.IndexByte(path, '?')
+	if i < 0 {
+		return path
+	}
+	query, err := url.ParseQuery(path[i+1:])
+	if err != nil {
+		// Query tidak bisa diurai: buang seluruhnya daripada mencatat kredensial
+		return path[:i]
+	}
+	changed := false
+	for _, name := range redactedQueryParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			changed = true
+		}
+	}
+	if !changed {
+		return path
+	}
+	return path[:i+1] + query.Encode()
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+)
+
+// RequestIDHeader adalah header untuk meneruskan dan mengembalikan ID request
+const RequestIDHeader = "X-Request-ID"
+
+// ID dari klien atau proxy hanya diterima jika pendek dan aman dicatat di log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestIDMiddleware memberi setiap request sebuah ID: diambil dari header X-Request-ID
+// jika valid, atau dibuat baru. ID disimpan ke context (helper.GetRequestID) dan dikirim
+// balik di header respons agar log audit bisa dicocokkan dengan log klien/proxy.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		helper.SetRequestID(c, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
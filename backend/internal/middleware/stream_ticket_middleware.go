@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// StreamTicketMiddleware mengautentikasi request lewat tiket sekali pakai di query
+// ?ticket=... (lihat POST /api/v1/stream/tickets), pengganti AuthMiddleware untuk
+// endpoint yang dibuka lewat EventSource browser, yang tidak bisa mengirim header sendiri.
+// JWT tidak diterima di query string agar tidak tercatat di access log.
+func StreamTicketMiddleware(tickets *repository.StreamTicketRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Tiket stream diperlukan"})
+			c.Abort()
+			return
+		}
+
+		userID, err := tickets.RedeemTicket(c.Request.Context(), ticket)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Tiket stream tidak valid atau kedaluwarsa"})
+			} else {
+				log.Printf("[StreamTicketMiddleware] Gagal memakai tiket stream: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa tiket stream"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", userID)
+		c.Next()
+	}
+}
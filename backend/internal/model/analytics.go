@@ -0,0 +1,114 @@
+package model
+
+import "time"
+
+// Urutan daftar item teratas pada analitik
+const (
+	TopItemsByFrequency = "frequency" // Paling sering dibeli (jumlah trip)
+	TopItemsBySpend     = "spend"     // Total belanja terbesar
+)
+
+// Satu trip belanja adalah semua item 'purchased' pada tanggal yang sama. Aplikasi
+// belum punya entitas daftar belanja terpisah; item dari template yang diterapkan
+// bersamaan sudah tercatat di tanggal yang sama sehingga ikut satu trip.
+// Nama item dinormalisasi (huruf kecil, tanpa spasi di ujung) agar "Beras" dan
+// "beras " dihitung sebagai item yang sama.
+
+// ItemStat adalah statistik satu item (nama ternormalisasi) dalam rentang tanggal
+type ItemStat struct {
+	Peringkat      int       `json:"peringkat"`
+	NamaItem       string    `json:"nama_item"` // Ejaan terakhir yang dipakai user
+	Kategori       string    `json:"kategori"`
+	JumlahTrip     int       `json:"jumlah_trip"` // Berapa hari berbeda item ini dibeli
+	TotalKuantitas int       `json:"total_kuantitas"`
+	TotalBelanja   float64   `json:"total_belanja"`
+	HargaRataRata  float64   `json:"harga_rata_rata"` // Rata-rata harga satuan tertimbang kuantitas
+	PersenBelanja  float64   `json:"persen_belanja"`  // Porsi dari total belanja rentang ini
+	TerakhirDibeli time.Time `json:"terakhir_dibeli"`
+}
+
+// BasketTrip adalah ringkasan satu trip belanja
+type BasketTrip struct {
+	Tanggal        time.Time `json:"tanggal"`
+	JumlahItem     int       `json:"jumlah_item"` // Jenis item berbeda
+	TotalKuantitas int       `json:"total_kuantitas"`
+	Total          float64   `json:"total"`
+	RataRata4Trip  float64   `json:"rata_rata_4_trip"` // Rata-rata bergerak total 4 trip terakhir (termasuk trip ini)
+}
+
+// BasketSummary adalah rata-rata ukuran keranjang per trip dalam rentang tanggal
+type BasketSummary struct {
+	JumlahTrip        int          `json:"jumlah_trip"`
+	RataRataItem      float64      `json:"rata_rata_item"`
+	RataRataKuantitas float64      `json:"rata_rata_kuantitas"`
+	RataRataBelanja   float64      `json:"rata_rata_belanja"`
+	MedianBelanja     float64      `json:"median_belanja"`
+	TripTerbesar      float64      `json:"trip_terbesar"`
+	Trips             []BasketTrip `json:"trips"` // Terbaru lebih dulu
+}
+
+// ItemPair adalah dua item yang sering dibeli pada trip yang sama
+type ItemPair struct {
+	ItemA         string  `json:"item_a"`
+	ItemB         string  `json:"item_b"`
+	JumlahBersama int     `json:"jumlah_bersama"` // Jumlah trip yang memuat keduanya
+	Support       float64 `json:"support"`        // JumlahBersama / seluruh trip
+	ConfidenceAB  float64 `json:"confidence_ab"`  // Peluang B dibeli jika A dibeli
+	ConfidenceBA  float64 `json:"confidence_ba"`  // Peluang A dibeli jika B dibeli
+	Lift          float64 `json:"lift"`           // > 1 berarti lebih sering bersama daripada kebetulan
+}
+
+// StapleItem adalah item rutin beserta jarak sejak terakhir dibeli
+type StapleItem struct {
+	NamaItem          string    `json:"nama_item"`
+	Kategori          string    `json:"kategori"`
+	JumlahTrip        int       `json:"jumlah_trip"`
+	TerakhirDibeli    time.Time `json:"terakhir_dibeli"`
+	HariSejakTerakhir int       `json:"hari_sejak_terakhir"`
+	RataRataJarakHari float64   `json:"rata_rata_jarak_hari"` // Rata-rata jarak antar pembelian
+	Terlambat         bool      `json:"terlambat"`            // Sudah lewat dari rata-rata jaraknya
+}
+
+// ProductMonth adalah pembelian satu produk (nama ternormalisasi) dalam satu bulan
+type ProductMonth struct {
+	Produk    string    // Nama ternormalisasi
+	Kategori  string    // Kategori terakhir produk ini
+	Bulan     time.Time // Tanggal 1 bulan tersebut
+	Kuantitas float64
+	Total     float64
+}
+
+// InflationIndex adalah indeks harga pribadi (Laspeyres): harga bulanan keranjang
+// belanja periode dasar dibandingkan dengan harganya di periode dasar (= 100)
+type InflationIndex struct {
+	BaseStart    time.Time         `json:"periode_dasar_mulai"`
+	BaseEnd      time.Time         `json:"periode_dasar_selesai"`
+	JumlahProduk int               `json:"jumlah_produk"` // Produk di keranjang periode dasar
+	BelanjaDasar float64           `json:"belanja_dasar"` // Nilai keranjang dengan harga periode dasar
+	Bobot        []InflationWeight `json:"bobot_kategori"`
+	Months       []InflationMonth  `json:"bulan"` // Urut dari bulan terlama
+}
+
+// InflationWeight adalah porsi kategori dalam keranjang periode dasar
+type InflationWeight struct {
+	Kategori string  `json:"kategori"`
+	Bobot    float64 `json:"bobot"` // Persen dari BelanjaDasar
+}
+
+// InflationMonth adalah nilai indeks satu bulan
+type InflationMonth struct {
+	Bulan            time.Time               `json:"bulan"`
+	Label            string                  `json:"label"` // Contoh: "2026-10"
+	Indeks           float64                 `json:"indeks"`
+	PerubahanBulanan *float64                `json:"perubahan_bulanan"` // Persen terhadap bulan sebelumnya
+	PerubahanTahunan *float64                `json:"perubahan_tahunan"` // Persen terhadap bulan yang sama tahun lalu
+	Cakupan          float64                 `json:"cakupan"`           // Persen bobot keranjang yang harganya teramati bulan ini
+	Kontribusi       []InflationContribution `json:"kontribusi"`
+}
+
+// InflationContribution adalah sumbangan satu kategori terhadap selisih indeks dari 100.
+// Jumlah kontribusi semua kategori sama dengan Indeks - 100.
+type InflationContribution struct {
+	Kategori   string  `json:"kategori"`
+	Kontribusi float64 `json:"kontribusi"` // Poin indeks
+}
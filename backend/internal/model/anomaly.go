@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Jenis anomali belanja
+const (
+	AnomalyPrice        = "price"         // Harga satuan jauh di atas riwayat item itu sendiri
+	AnomalyCategoryWeek = "category_week" // Total mingguan kategori jauh di atas rata-rata bergulirnya
+	AnomalyDuplicate    = "duplicate"     // Nama dan harga sama dicatat dalam selang beberapa menit
+)
+
+// Status tindak lanjut anomali oleh user
+const (
+	AnomalyOpen      = "open"
+	AnomalyDismissed = "dismissed" // Bukan masalah; tidak akan ditandai ulang
+	AnomalyConfirmed = "confirmed" // Memang tidak wajar
+)
+
+// Anomaly adalah satu tanda belanja tidak biasa milik household
+type Anomaly struct {
+	ID            int        `json:"id_anomali"`
+	UserID        int        `json:"id_user"` // User yang item-nya memicu deteksi
+	HouseholdID   int        `json:"id_household"`
+	Type          string     `json:"jenis"`
+	Status        string     `json:"status"`
+	ItemID        *int       `json:"id_item,omitempty"`
+	RelatedItemID *int       `json:"id_item_terkait,omitempty"` // Entri pasangan pada duplikat
+	CategoryID    *int       `json:"id_kategori,omitempty"`
+	WeekStart     *time.Time `json:"awal_minggu,omitempty"` // Untuk category_week
+	Value         float64    `json:"nilai"`                 // Harga satuan atau total minggu yang ditandai
+	Median        float64    `json:"median"`                // Patokan dari riwayat
+	Score         float64    `json:"skor"`                  // Modified z-score (0 untuk duplikat)
+	Message       string     `json:"pesan"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+
+	// Diisi saat membaca daftar anomali (join ke items dan referensi_kategori)
+	ItemName     string `json:"nama_item,omitempty"`
+	CategoryName string `json:"nama_kategori,omitempty"`
+
+	// Key membuat setiap anomali hanya dicatat sekali walaupun deteksi diulang
+	// (saat item diubah atau oleh batch malam), termasuk setelah di-dismiss.
+	Key string `json:"-"`
+}
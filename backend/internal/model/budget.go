@@ -0,0 +1,164 @@
+package model
+
+import (
+	"math"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+)
+
+// Jenis periode budget (perhitungan rentangnya ada di calendar.PeriodRange)
+const (
+	PeriodWeekly   = calendar.PeriodWeekly
+	PeriodBiweekly = calendar.PeriodBiweekly
+	PeriodMonthly  = calendar.PeriodMonthly
+	PeriodCustom   = calendar.PeriodCustom
+)
+
+// BudgetRequest adalah body untuk membuat/mengubah budget.
+// start_date dan end_date berformat YYYY-MM-DD; end_date hanya dipakai untuk period_type 'custom'.
+type BudgetRequest struct {
+	Amount     float64 `json:"jumlah_anggaran" binding:"required"`
+	PeriodType string  `json:"period_type"`
+	StartDate  string  `json:"start_date"`
+	EndDate    string  `json:"end_date"`
+}
+
+// BudgetStatus adalah budget beserta realisasi pengeluarannya dalam periode tersebut
+type BudgetStatus struct {
+	Budget
+	Spent       float64 `json:"total_belanja"`
+	Swept       float64 `json:"disisihkan"` // Surplus yang sudah dipindah ke tujuan tabungan
+	Remaining   float64 `json:"sisa_budget"`
+	PercentUsed float64 `json:"persen_terpakai"`
+	OverBudget  bool    `json:"over_budget"`
+}
+
+// Pagination adalah metadata halaman untuk respons daftar
+type Pagination struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
+
+// Mode alokasi budget per kategori
+const (
+	AllocationModeCap = "cap" // jumlah_anggaran adalah batas di atas total alokasi
+	AllocationModeSum = "sum" // jumlah_anggaran = total alokasi
+)
+
+// UnallocatedPoolName adalah label pool budget yang tidak dialokasikan ke kategori mana pun
+const UnallocatedPoolName = "Belum Dialokasikan"
+
+// BudgetAllocation adalah alokasi budget untuk satu kategori
+type BudgetAllocation struct {
+	CategoryID   int     `json:"id_kategori" binding:"required"`
+	Amount       float64 `json:"jumlah" binding:"min=0"`
+	DrawFromPool bool    `json:"draw_from_pool"`
+}
+
+// AllocationRequest adalah body untuk PUT /api/v1/budgets/:id/allocations
+type AllocationRequest struct {
+	Mode        string             `json:"allocation_mode"`
+	Allocations []BudgetAllocation `json:"allocations" binding:"dive"`
+}
+
+// AllocationSpending adalah alokasi beserta total belanja yang masuk ke amplop tersebut.
+// CategoryID bernilai nil untuk belanja yang tidak masuk amplop mana pun.
+type AllocationSpending struct {
+	CategoryID   *int
+	CategoryName string
+	Amount       float64
+	DrawFromPool bool
+	Spent        float64
+}
+
+// CategoryEnvelope adalah status satu amplop kategori dalam periode budget
+type CategoryEnvelope struct {
+	CategoryID    *int    `json:"id_kategori"` // nil untuk pool "belum dialokasikan"
+	CategoryName  string  `json:"nama_kategori"`
+	Allocated     float64 `json:"dialokasikan"`
+	Spent         float64 `json:"terpakai"`
+	FromPool      float64 `json:"dari_pool"` // bagian belanja yang diambil dari pool
+	Remaining     float64 `json:"sisa"`
+	OverAllocated bool    `json:"over_budget"`
+}
+
+// EnvelopeSummary adalah ringkasan seluruh amplop dalam satu budget
+type EnvelopeSummary struct {
+	BudgetID  int                `json:"id_anggaran"`
+	Mode      string             `json:"allocation_mode"`
+	Total     float64            `json:"jumlah_anggaran"`
+	CarryOver float64            `json:"carry_over"` // Masuk ke pool "belum dialokasikan"
+	Envelopes []CategoryEnvelope `json:"alokasi"`
+}
+
+// BuildEnvelopes menghitung status setiap amplop dan pool "belum dialokasikan".
+// Alokasi selalu dibatasi jumlah dasar (total); carry-over dari periode sebelumnya
+// tidak dialokasikan dan seluruhnya masuk ke pool. Pool berisi
+// (total - jumlah alokasi + carry-over) pada mode 'cap', dan carry-over saja pada mode 'sum'.
+// Belanja tanpa amplop selalu diambil dari pool; kelebihan belanja pada amplop
+// dengan DrawFromPool juga diambil dari pool.
+func BuildEnvelopes(budgetID int, mode string, total float64, carryOver float64, rows []AllocationSpending) EnvelopeSummary {
+	summary := EnvelopeSummary{BudgetID: budgetID, Mode: mode, Total: total, CarryOver: carryOver, Envelopes: []CategoryEnvelope{}}
+
+	var allocatedSum, poolSpent float64
+	for _, r := range rows {
+		if r.CategoryID == nil {
+			poolSpent += r.Spent
+			continue
+		}
+		allocatedSum += r.Amount
+
+		env := CategoryEnvelope{
+			CategoryID:   r.CategoryID,
+			CategoryName: r.CategoryName,
+			Allocated:    r.Amount,
+			Spent:        r.Spent,
+		}
+		if r.DrawFromPool && r.Spent > r.Amount {
+			env.FromPool = r.Spent - r.Amount
+			poolSpent += env.FromPool
+		}
+		env.Remaining = env.Allocated - env.Spent + env.FromPool
+		env.OverAllocated = env.Remaining < 0
+		summary.Envelopes = append(summary.Envelopes, env)
+	}
+
+	poolAllocated := carryOver
+	if mode != AllocationModeSum {
+		poolAllocated += total - allocatedSum
+	}
+	summary.Envelopes = append(summary.Envelopes, CategoryEnvelope{
+		CategoryName:  UnallocatedPoolName,
+		Allocated:     poolAllocated,
+		Spent:         poolSpent,
+		Remaining:     poolAllocated - poolSpent,
+		OverAllocated: poolSpent > poolAllocated,
+	})
+	return summary
+}
+
+// ApplyRollover menghitung carry-over sepanjang rantai budget (urut start_date naik)
+// sesuai pengaturan user. Sisa efektif tiap budget menjadi dasar carry-over budget berikutnya.
+// Remaining, PercentUsed, dan OverBudget dihitung ulang terhadap jumlah efektif.
+func ApplyRollover(settings UserSettings, chain []BudgetStatus) {
+	var previous *BudgetStatus
+	for i := range chain {
+		b := &chain[i]
+		b.CarryOver = 0
+		b.PreviousBudgetID = nil
+		if previous != nil {
+			b.CarryOver = settings.CarryOver(previous.Remaining)
+			prevID := previous.ID
+			b.PreviousBudgetID = &prevID
+		}
+		b.EffectiveAmount = b.Amount + b.CarryOver
+		b.Remaining = b.EffectiveAmount - b.Spent - b.Swept
+		b.PercentUsed = 0
+		if b.EffectiveAmount > 0 {
+			b.PercentUsed = math.Round(b.Spent/b.EffectiveAmount*10000) / 100
+		}
+		b.OverBudget = b.Spent > b.EffectiveAmount
+		previous = b
+	}
+}
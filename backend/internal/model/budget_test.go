@@ -0,0 +1,113 @@
+package model
+
+import "testing"
+
+func TestCarryOver(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		cap       float64
+		remaining float64
+		want      float64
+	}{
+		{"none surplus", RolloverNone, 0, 50, 0},
+		{"none defisit", RolloverNone, 0, -50, 0},
+		{"full surplus", RolloverFull, 0, 50, 50},
+		{"full defisit", RolloverFull, 0, -50, -50},
+		{"capped di bawah batas", RolloverCapped, 100, 50, 50},
+		{"capped di atas batas", RolloverCapped, 100, 150, 100},
+		{"capped defisit dibawa penuh", RolloverCapped, 100, -150, -150},
+		{"deficit_only surplus", RolloverDeficitOnly, 0, 50, 0},
+		{"deficit_only defisit", RolloverDeficitOnly, 0, -50, -50},
+		{"kebijakan tidak dikenal", "monthly", 0, 50, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := UserSettings{RolloverPolicy: tt.policy, RolloverCap: tt.cap}
+			if got := s.CarryOver(tt.remaining); got != tt.want {
+				t.Errorf("CarryOver(%v) = %v, want %v", tt.remaining, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRollover(t *testing.T) {
+	// Tiga periode berurutan: sisa 200, lalu defisit, lalu periode berjalan
+	newChain := func() []BudgetStatus {
+		return []BudgetStatus{
+			{Budget: Budget{ID: 1, Amount: 1000}, Spent: 800},
+			{Budget: Budget{ID: 2, Amount: 1000}, Spent: 1300},
+			{Budget: Budget{ID: 3, Amount: 1000}, Spent: 500},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		settings      UserSettings
+		wantCarry     []float64
+		wantRemaining []float64
+	}{
+		{"none", UserSettings{RolloverPolicy: RolloverNone}, []float64{0, 0, 0}, []float64{200, -300, 500}},
+		{"full", UserSettings{RolloverPolicy: RolloverFull}, []float64{0, 200, -100}, []float64{200, -100, 400}},
+		{"capped", UserSettings{RolloverPolicy: RolloverCapped, RolloverCap: 150}, []float64{0, 150, -150}, []float64{200, -150, 350}},
+		{"deficit_only", UserSettings{RolloverPolicy: RolloverDeficitOnly}, []float64{0, 0, -300}, []float64{200, -300, 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newChain()
+			ApplyRollover(tt.settings, chain)
+			for i, b := range chain {
+				if b.CarryOver != tt.wantCarry[i] || b.Remaining != tt.wantRemaining[i] {
+					t.Errorf("budget %d: carry = %v remaining = %v, want %v and %v",
+						b.ID, b.CarryOver, b.Remaining, tt.wantCarry[i], tt.wantRemaining[i])
+				}
+				if b.EffectiveAmount != b.Amount+b.CarryOver {
+					t.Errorf("budget %d: EffectiveAmount = %v, want %v", b.ID, b.EffectiveAmount, b.Amount+b.CarryOver)
+				}
+				if (i == 0) != (b.PreviousBudgetID == nil) {
+					t.Errorf("budget %d: PreviousBudgetID = %v", b.ID, b.PreviousBudgetID)
+				}
+			}
+		})
+	}
+
+	// Persentase dan status over budget dihitung terhadap jumlah efektif
+	chain := newChain()
+	ApplyRollover(UserSettings{RolloverPolicy: RolloverFull}, chain)
+	if got := chain[1]; got.PercentUsed != 108.33 || !got.OverBudget {
+		t.Errorf("budget 2: PercentUsed = %v OverBudget = %v, want 108.33 and true", got.PercentUsed, got.OverBudget)
+	}
+}
+
+func TestBuildEnvelopesPool(t *testing.T) {
+	food := 1
+	rows := []AllocationSpending{
+		{CategoryID: &food, CategoryName: "Makanan", Amount: 600, Spent: 700, DrawFromPool: true},
+		{CategoryName: "", Spent: 50},
+	}
+
+	tests := []struct {
+		name      string
+		mode      string
+		carryOver float64
+		wantPool  float64
+	}{
+		{"cap tanpa carry-over", AllocationModeCap, 0, 400},
+		{"cap dengan surplus periode lalu", AllocationModeCap, 100, 500},
+		{"cap dengan defisit periode lalu", AllocationModeCap, -300, 100},
+		{"sum hanya carry-over", AllocationModeSum, 100, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := BuildEnvelopes(7, tt.mode, 1000, tt.carryOver, rows)
+			pool := summary.Envelopes[len(summary.Envelopes)-1]
+			if pool.CategoryID != nil || pool.Allocated != tt.wantPool {
+				t.Fatalf("pool allocated = %v, want %v", pool.Allocated, tt.wantPool)
+			}
+			// 50 tanpa amplop + 100 kelebihan amplop Makanan
+			if pool.Spent != 150 || pool.Remaining != tt.wantPool-150 {
+				t.Errorf("pool spent = %v remaining = %v", pool.Spent, pool.Remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import "time"
+
+// Rentang waktu dasbor (query param 'range'). Rentang berjalan (week, month, year)
+// dihitung dari awal periode sampai hari ini.
+const (
+	DashboardRangeBudget = "budget" // Periode budget aktif; minggu berjalan jika belum ada budget
+	DashboardRangeWeek   = "week"
+	DashboardRangeMonth  = "month"
+	DashboardRangeYear   = "year"
+	DashboardRange7d     = "7d"
+	DashboardRange30d    = "30d"
+	DashboardRange90d    = "90d"
+	DashboardRangeCustom = "custom" // Diisi otomatis jika from/to dikirim
+)
+
+// ValidDashboardRange memeriksa apakah rentang dasbor dikenali (custom hanya lewat from/to)
+func ValidDashboardRange(r string) bool {
+	switch r {
+	case DashboardRangeBudget, DashboardRangeWeek, DashboardRangeMonth, DashboardRangeYear,
+		DashboardRange7d, DashboardRange30d, DashboardRange90d:
+		return true
+	}
+	return false
+}
+
+// Status progres budget dibanding waktu yang sudah berjalan
+const (
+	BudgetOnTrack = "on_track"    // Persentase terpakai tidak melebihi persentase waktu
+	BudgetAtRisk  = "at_risk"     // Belanja lebih cepat dari berjalannya periode
+	BudgetOver    = "over_budget" // Belanja sudah melebihi budget efektif
+)
+
+// DashboardQuery adalah rentang dasbor yang sudah divalidasi. Untuk range 'budget',
+// From/To diisi oleh service dari budget aktif. From dan To inklusif (To = 23:59:59).
+type DashboardQuery struct {
+	Range       string    `json:"range"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Granularity string    `json:"granularity"`
+}
+
+// BudgetProgress adalah budget aktif beserta laju belanjanya terhadap waktu
+type BudgetProgress struct {
+	BudgetStatus
+	HariBerjalan int     `json:"hari_berjalan"` // Termasuk hari ini
+	HariTersisa  int     `json:"hari_tersisa"`  // Tidak termasuk hari ini
+	PersenWaktu  float64 `json:"persen_waktu"`
+	SisaPerHari  float64 `json:"sisa_per_hari"` // Sisa budget dibagi hari tersisa (termasuk hari ini)
+	Status       string  `json:"status"`
+}
+
+// NewBudgetProgress menghitung progres budget pada tanggal 'today'. Tanggal periode
+// dan 'today' dibandingkan sebagai tanggal kalender saja.
+func NewBudgetProgress(status BudgetStatus, today time.Time) BudgetProgress {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	start, end, now := day(status.StartDate), day(status.EndDate), day(today)
+
+	total := int(end.Sub(start).Hours()/24) + 1
+	elapsed := int(now.Sub(start).Hours()/24) + 1
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if elapsed > total {
+		elapsed = total
+	}
+
+	p := BudgetProgress{
+		BudgetStatus: status,
+		HariBerjalan: elapsed,
+		HariTersisa:  total - elapsed,
+		PersenWaktu:  float64(elapsed) / float64(total) * 100,
+		Status:       BudgetOnTrack,
+	}
+	daysLeft := total - elapsed + 1 // Termasuk hari ini
+	if elapsed == 0 {
+		daysLeft = total // Periode belum dimulai
+	}
+	if status.Remaining > 0 {
+		p.SisaPerHari = status.Remaining / float64(daysLeft)
+	}
+	switch {
+	case status.OverBudget || status.Remaining < 0:
+		p.Status = BudgetOver
+	case status.PercentUsed > p.PersenWaktu:
+		p.Status = BudgetAtRisk
+	}
+	return p
+}
+
+// DashboardResponse adalah isi dasbor lengkap dalam satu respons
+type DashboardResponse struct {
+	Periode           DashboardQuery  `json:"periode"`
+	Summary           SummaryResponse `json:"summary"`
+	Charts            ChartResponse   `json:"charts"`
+	AktivitasTerakhir []Item          `json:"aktivitas_terakhir"`
+	ProgresBudget     *BudgetProgress `json:"progres_budget"` // null jika belum ada budget aktif
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aksi pada log audit
+const (
+	EventCreate      = "create"
+	EventUpdate      = "update"
+	EventDelete      = "delete"
+	EventLogin       = "login"
+	EventLoginFailed = "login_failed" // Actor kosong; username yang dicoba ada di 'sesudah'
+)
+
+// Entitas yang dicatat pada log audit
+const (
+	EntityItem     = "item"
+	EntityCategory = "category"
+	EntityBudget   = "budget"
+	EntityUser     = "user"
+)
+
+// ValidEventAction memeriksa apakah aksi log audit dikenali
+func ValidEventAction(a string) bool {
+	switch a {
+	case EventCreate, EventUpdate, EventDelete, EventLogin, EventLoginFailed:
+		return true
+	}
+	return false
+}
+
+// ValidEventEntity memeriksa apakah entitas log audit dikenali
+func ValidEventEntity(e string) bool {
+	return e == EntityItem || e == EntityCategory || e == EntityBudget || e == EntityUser
+}
+
+// Event adalah satu baris log audit. Untuk update, Before/After hanya memuat field yang
+// berubah; untuk create hanya After dan untuk delete hanya Before yang berisi data lengkap.
+type Event struct {
+	ID        int64           `json:"id_event"`
+	ActorID   *int            `json:"id_actor"` // nil untuk login gagal
+	Action    string          `json:"aksi"`
+	Entity    string          `json:"entitas"`
+	EntityID  *int            `json:"id_entitas,omitempty"`
+	Before    json.RawMessage `json:"sebelum,omitempty"`
+	After     json.RawMessage `json:"sesudah,omitempty"`
+	RequestID string          `json:"request_id"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	// Diisi pada kueri audit admin (join ke "User")
+	ActorUsername string `json:"username_actor,omitempty"`
+}
+
+// EventFilter adalah filter opsional untuk daftar event. Nilai kosong berarti tanpa filter.
+type EventFilter struct {
+	ActorID   int
+	Action    string
+	Entity    string
+	EntityID  int
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Role anggota household
+const (
+	HouseholdRoleOwner  = "owner"  // Kelola anggota, undangan dan data
+	HouseholdRoleEditor = "editor" // Ubah item, kategori, budget dan template
+	HouseholdRoleViewer = "viewer" // Hanya baca
+)
+
+// Household adalah kelompok user yang berbagi item, kategori, budget dan template
+type Household struct {
+	ID        int       `json:"id_household"`
+	Name      string    `json:"nama_household"`
+	CreatedBy *int      `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role"` // Role user yang meminta
+	Members   int       `json:"jumlah_anggota"`
+	Active    bool      `json:"aktif"` // Household yang sedang dipakai user
+}
+
+// CanWrite melaporkan apakah role boleh mengubah data household
+func CanWrite(role string) bool {
+	return role == HouseholdRoleOwner || role == HouseholdRoleEditor
+}
+
+// ValidHouseholdRole melaporkan apakah role dikenal
+func ValidHouseholdRole(role string) bool {
+	return role == HouseholdRoleOwner || role == HouseholdRoleEditor || role == HouseholdRoleViewer
+}
+
+// HouseholdMember adalah satu anggota household
+type HouseholdMember struct {
+	HouseholdID int       `json:"id_household"`
+	UserID      int       `json:"id_user"`
+	Username    string    `json:"username"`
+	Name        string    `json:"nama_user"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// HouseholdInvitation adalah link undangan bergabung ke household.
+// Token hanya terisi saat undangan baru dibuat; yang disimpan di database hanya hash-nya.
+type HouseholdInvitation struct {
+	ID          int        `json:"id_undangan"`
+	HouseholdID int        `json:"id_household"`
+	Role        string     `json:"role"`
+	CreatedBy   *int       `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedBy  *int       `json:"accepted_by,omitempty"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	Token       string     `json:"token,omitempty"`
+	Link        string     `json:"link,omitempty"`
+}
+
+// HouseholdRequest adalah body untuk membuat/mengganti nama household
+type HouseholdRequest struct {
+	Name string `json:"nama_household" binding:"required"`
+}
+
+// MemberRoleRequest adalah body untuk mengubah role anggota
+type MemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// InvitationRequest adalah body untuk membuat undangan. Role bawaan editor,
+// masa berlaku bawaan mengikuti konfigurasi server (dalam jam).
+type InvitationRequest struct {
+	Role       string `json:"role"`
+	ExpiresInH int    `json:"berlaku_jam"`
+}
@@ -10,14 +10,23 @@ import (
 type Item struct {
 	ID            int            `json:"id_item"`
 	UserID        int            `json:"id_user"`
+	HouseholdID   int            `json:"-"` // Household pemilik item; dipakai detektor anomali
 	CategoryID    int            `json:"id_kategori"`
 	ItemName      string         `json:"nama_item" binding:"required"`
 	Quantity      int            `json:"jumlah_item" binding:"required"`
 	UnitPrice     float64        `json:"harga_satuan" binding:"required"`
 	TotalCost     float64        `json:"total_harga"` // Dihitung di backend
 	PurchasedDate time.Time      `json:"purchased_date"`
+	Status        string         `json:"status"`                  // "planned" atau "purchased"
 	CategoryName  sql.NullString `json:"nama_kategori,omitempty"` // Untuk join
 }
+
+// Status item belanja
+const (
+	ItemStatusPlanned   = "planned"
+	ItemStatusPurchased = "purchased"
+)
+
 type ItemRequest struct {
 	CategoryID int     `json:"id_kategori" binding:"required"`
 	ItemName   string  `json:"nama_item" binding:"required"`
@@ -27,18 +36,61 @@ type ItemRequest struct {
 
 // Budget represents the data structure for the "Anggaran" entity
 type Budget struct {
-	ID        int       `json:"id_anggaran"`
-	UserID    int       `json:"id_user"`
-	StartDate time.Time `json:"start_date" binding:"required"`
-	EndDate   time.Time `json:"end_date" binding:"required"`
-	Amount    float64   `json:"jumlah_anggaran" binding:"required"`
+	ID          int       `json:"id_anggaran"`
+	HouseholdID int       `json:"id_household"`
+	UserID      int       `json:"id_user"`     // Anggota yang membuat budget
+	PeriodType  string    `json:"period_type"` // weekly | biweekly | monthly | custom
+	StartDate   time.Time `json:"start_date" binding:"required"`
+	EndDate     time.Time `json:"end_date" binding:"required"`
+	Amount      float64   `json:"jumlah_anggaran" binding:"required"`
+
+	// Diisi oleh ApplyRollover: sisa periode sebelumnya yang dibawa ke budget ini
+	CarryOver        float64 `json:"carry_over"`
+	EffectiveAmount  float64 `json:"jumlah_efektif"` // Amount + CarryOver
+	PreviousBudgetID *int    `json:"id_anggaran_sebelumnya,omitempty"`
 }
 
 // Category represents the data structure for "Referensi_Kategori"
 type Category struct {
-	ID           int    `json:"id_kategori"`
-	UserID       int    `json:"id_user"`
-	CategoryName string `json:"nama_kategori" binding:"required"`
+	ID           int        `json:"id_kategori"`
+	UserID       int        `json:"id_user"`
+	CategoryName string     `json:"nama_kategori" binding:"required"`
+	ParentID     *int       `json:"id_parent"`          // nil untuk kategori teratas
+	Children     []Category `json:"children,omitempty"` // Diisi oleh BuildCategoryTree
+}
+
+// BuildCategoryTree menyusun daftar kategori datar menjadi pohon berdasarkan ParentID.
+// Kategori yang parent-nya tidak ada di daftar diperlakukan sebagai kategori teratas.
+func BuildCategoryTree(list []Category) []Category {
+	byParent := make(map[int][]Category)
+	known := make(map[int]bool, len(list))
+	for _, k := range list {
+		known[k.ID] = true
+	}
+
+	var roots []Category
+	for _, k := range list {
+		if k.ParentID == nil || !known[*k.ParentID] {
+			roots = append(roots, k)
+			continue
+		}
+		byParent[*k.ParentID] = append(byParent[*k.ParentID], k)
+	}
+
+	var attach func(nodes []Category) []Category
+	attach = func(nodes []Category) []Category {
+		for i := range nodes {
+			nodes[i].Children = attach(byParent[nodes[i].ID])
+		}
+		return nodes
+	}
+	return attach(roots)
+}
+
+// CategoryMergeRequest adalah body untuk POST /api/v1/kategori/merge
+type CategoryMergeRequest struct {
+	SourceIDs []int `json:"source_ids" binding:"required,min=1"`
+	TargetID  int   `json:"target_id" binding:"required"`
 }
 
 // === DTO (Data Transfer Objects) untuk Laporan/Dasbor ===
@@ -49,15 +101,17 @@ type SpendingByCategory struct {
 	Total    float64 `json:"total" db:"total"`
 }
 
-// SpendingByWeek adalah struct untuk data Bar Chart
-type SpendingByWeek struct {
-	MingguKe string  `json:"minggu_ke" db:"minggu"` // Contoh: "W40"
-	Total    float64 `json:"total" db:"total"`
+// SpendingByDay adalah total pengeluaran dalam satu hari
+type SpendingByDay struct {
+	Tanggal time.Time `json:"tanggal"`
+	Total   float64   `json:"total"`
 }
 type SummaryResponse struct {
-	TotalBelanja float64 `json:"total_belanja"`
-	Budget       float64 `json:"budget"`
-	SisaBudget   float64 `json:"sisa_budget"`
+	TotalBelanja    float64            `json:"total_belanja"`
+	Budget          float64            `json:"budget"`     // Budget efektif (dasar + carry-over)
+	CarryOver       float64            `json:"carry_over"` // Sisa periode sebelumnya yang dibawa
+	SisaBudget      float64            `json:"sisa_budget"`
+	AlokasiKategori []CategoryEnvelope `json:"alokasi_kategori"` // Kosong jika belum ada budget
 }
 
 // PieChartItem adalah DTO untuk satu potong data di Pie Chart.
@@ -72,8 +126,17 @@ type BarChartItem struct {
 	Pengeluaran float64 `json:"pengeluaran"` // Total pengeluaran
 }
 
-// ChartResponse adalah DTO pembungkus untuk kedua data chart.
+// AllocationChartItem adalah DTO untuk satu kategori di chart alokasi budget.
+type AllocationChartItem struct {
+	Name         string  `json:"name"` // Nama kategori atau "Belum Dialokasikan"
+	Dialokasikan float64 `json:"dialokasikan"`
+	Terpakai     float64 `json:"terpakai"`
+	Sisa         float64 `json:"sisa"`
+}
+
+// ChartResponse adalah DTO pembungkus untuk data chart.
 type ChartResponse struct {
-	PieChart []PieChartItem `json:"pie_chart"`
-	BarChart []BarChartItem `json:"bar_chart"`
+	PieChart     []PieChartItem        `json:"pie_chart"`
+	BarChart     []BarChartItem        `json:"bar_chart"`
+	AlokasiChart []AllocationChartItem `json:"alokasi_chart"`
 }
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// Jenis notifikasi
+const (
+	NotificationBudgetThreshold = "budget_threshold"
+)
+
+// DefaultAlertThresholds adalah ambang peringatan bawaan (persen budget terpakai)
+var DefaultAlertThresholds = []int{50, 80, 100}
+
+// Notification adalah satu notifikasi di pusat notifikasi user
+type Notification struct {
+	ID        int        `json:"id_notifikasi"`
+	UserID    int        `json:"id_user"`
+	Type      string     `json:"tipe"`
+	Title     string     `json:"judul"`
+	Message   string     `json:"pesan"`
+	BudgetID  *int       `json:"id_anggaran,omitempty"`
+	IsRead    bool       `json:"is_read"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+// AlertThresholdRequest adalah body untuk PUT /api/v1/budgets/:id/alerts
+type AlertThresholdRequest struct {
+	Thresholds []int `json:"thresholds"`
+}
+
+// NormalizeThresholds mengurutkan ambang dan membuang duplikat.
+// Mengembalikan false jika ada ambang di luar 1..1000 persen.
+func NormalizeThresholds(thresholds []int) ([]int, bool) {
+	seen := make(map[int]bool, len(thresholds))
+	result := []int{}
+	for _, t := range thresholds {
+		if t < 1 || t > 1000 {
+			return nil, false
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	sort.Ints(result)
+	return result, true
+}
+
+// CrossedThresholds mengembalikan ambang (urut naik) yang sudah tercapai oleh persentase terpakai
+func CrossedThresholds(thresholds []int, percentUsed float64) []int {
+	var crossed []int
+	for _, t := range thresholds {
+		if percentUsed >= float64(t) {
+			crossed = append(crossed, t)
+		}
+	}
+	sort.Ints(crossed)
+	return crossed
+}
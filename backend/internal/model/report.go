@@ -0,0 +1,152 @@
+package model
+
+import "time"
+
+// Granularitas tren pada laporan
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// Mode perbandingan laporan
+const (
+	CompareNone     = "none"
+	ComparePrevious = "previous"  // Periode sepanjang yang sama tepat sebelum periode laporan
+	CompareLastYear = "last_year" // Rentang tanggal yang sama tahun lalu
+)
+
+// ValidGranularity memeriksa apakah granularitas dikenali
+func ValidGranularity(g string) bool {
+	return g == GranularityDay || g == GranularityWeek || g == GranularityMonth
+}
+
+// ValidCompare memeriksa apakah mode perbandingan dikenali
+func ValidCompare(mode string) bool {
+	return mode == CompareNone || mode == ComparePrevious || mode == CompareLastYear
+}
+
+// FieldError menjelaskan satu parameter request yang tidak valid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ReportQuery adalah parameter laporan yang sudah divalidasi.
+// From dan To inklusif, di zona waktu user (To = 23:59:59).
+type ReportQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	Compare     string
+}
+
+// ReportItem adalah satu baris item yang sudah dibeli pada daftar item laporan
+type ReportItem struct {
+	Tanggal     time.Time `json:"tanggal"`
+	NamaItem    string    `json:"nama_item"`
+	Kategori    string    `json:"kategori"`
+	Jumlah      int       `json:"jumlah_item"`
+	HargaSatuan float64   `json:"harga_satuan"`
+	Total       float64   `json:"total_harga"`
+}
+
+// SpendingByPeriod adalah total pengeluaran dalam satu hari/minggu/bulan pada tren laporan
+type SpendingByPeriod struct {
+	Label string    `json:"label"` // "2026-10-07", "2026-W41" atau "2026-10"
+	Mulai time.Time `json:"mulai"`
+	Total float64   `json:"total"`
+}
+
+// CategoryComparison membandingkan belanja satu kategori dengan periode pembanding
+type CategoryComparison struct {
+	Kategori        string   `json:"kategori"`
+	Total           float64  `json:"total"`
+	Pembanding      float64  `json:"total_pembanding"`
+	Selisih         float64  `json:"selisih"`
+	PersenPerubahan *float64 `json:"persen_perubahan"` // nil jika periode pembanding tanpa belanja
+}
+
+// ReportComparison adalah hasil perbandingan laporan dengan periode lain
+type ReportComparison struct {
+	Mode            string               `json:"mode"`
+	PeriodStart     time.Time            `json:"periode_mulai"`
+	PeriodEnd       time.Time            `json:"periode_selesai"`
+	TotalBelanja    float64              `json:"total_belanja"`
+	Selisih         float64              `json:"selisih"`
+	PersenPerubahan *float64             `json:"persen_perubahan"`
+	Categories      []CategoryComparison `json:"per_kategori"`
+}
+
+// ReportData adalah data ringkasan satu laporan belanja, dipakai bersama oleh
+// semua format unduhan (Excel, PDF, CSV, ...). Daftar item tidak ikut disimpan di sini
+// karena bisa sangat besar; item dialirkan terpisah (lihat report.ItemSource).
+type ReportData struct {
+	UserName     string               `json:"nama_user"`
+	PeriodStart  time.Time            `json:"periode_mulai"`
+	PeriodEnd    time.Time            `json:"periode_selesai"`
+	GeneratedAt  time.Time            `json:"dibuat_pada"`
+	TotalBelanja float64              `json:"total_belanja"`
+	TotalBudget  float64              `json:"total_budget"` // Jumlah budget yang beririsan dengan periode
+	Categories   []SpendingByCategory `json:"per_kategori"`
+	Granularity  string               `json:"granularitas"`
+	Trend        []SpendingByPeriod   `json:"tren"`                   // Urut dari periode terlama
+	Comparison   *ReportComparison    `json:"perbandingan,omitempty"` // nil jika tanpa perbandingan
+	Inflation    *InflationIndex      `json:"inflasi,omitempty"`      // Indeks harga pribadi 24 bulan sampai akhir periode
+}
+
+// SisaBudget adalah selisih budget dengan belanja (negatif jika melebihi budget)
+func (d *ReportData) SisaBudget() float64 {
+	return d.TotalBudget - d.TotalBelanja
+}
+
+// PersenTerpakai adalah persentase budget yang sudah terpakai (0 jika tidak ada budget)
+func (d *ReportData) PersenTerpakai() float64 {
+	if d.TotalBudget <= 0 {
+		return 0
+	}
+	return d.TotalBelanja / d.TotalBudget * 100
+}
+
+// PercentChange menghitung persentase perubahan dari 'previous' ke 'current'.
+// Mengembalikan nil jika previous 0 (perubahan tidak terdefinisi).
+func PercentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := (current - previous) / previous * 100
+	return &change
+}
+
+// CompareCategories menggabungkan belanja per kategori dua periode. Kategori yang hanya
+// ada di salah satu periode tetap muncul dengan total 0 di periode lainnya. Urutan mengikuti
+// 'current' (total terbesar dulu), lalu kategori yang hanya ada di 'previous'.
+func CompareCategories(current, previous []SpendingByCategory) []CategoryComparison {
+	prevTotals := make(map[string]float64, len(previous))
+	for _, p := range previous {
+		prevTotals[p.Kategori] = p.Total
+	}
+
+	result := make([]CategoryComparison, 0, len(current)+len(previous))
+	seen := make(map[string]bool, len(current))
+	for _, c := range current {
+		seen[c.Kategori] = true
+		result = append(result, newCategoryComparison(c.Kategori, c.Total, prevTotals[c.Kategori]))
+	}
+	for _, p := range previous {
+		if !seen[p.Kategori] {
+			result = append(result, newCategoryComparison(p.Kategori, 0, p.Total))
+		}
+	}
+	return result
+}
+
+func newCategoryComparison(name string, current, previous float64) CategoryComparison {
+	return CategoryComparison{
+		Kategori:        name,
+		Total:           current,
+		Pembanding:      previous,
+		Selisih:         current - previous,
+		PersenPerubahan: PercentChange(current, previous),
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+// Jenis pencocokan aturan kategori
+const (
+	RuleMatchKeyword = "keyword"
+	RuleMatchRegex   = "regex"
+)
+
+// CategoryRule adalah aturan kategorisasi otomatis milik household
+type CategoryRule struct {
+	ID          int    `json:"id_rule"`
+	UserID      int    `json:"id_user"` // Pembuat aturan
+	HouseholdID int    `json:"id_household"`
+	CategoryID  int    `json:"id_kategori" binding:"required"`
+	MatchType   string `json:"match_type"` // "keyword" atau "regex"
+	Pattern     string `json:"pattern" binding:"required"`
+	Priority    int    `json:"priority"`
+}
+
+// CategoryAssignment adalah ringkasan riwayat: berapa kali nama item diberi kategori tertentu
+type CategoryAssignment struct {
+	ItemName   string
+	CategoryID int
+	Count      int
+}
+
+// CategorySuggestion adalah hasil tebakan kategori untuk sebuah nama item
+type CategorySuggestion struct {
+	CategoryID int     `json:"id_kategori"`
+	Source     string  `json:"source"` // "rule" atau "history"
+	RuleID     *int    `json:"id_rule,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Sumber setoran tabungan
+const (
+	ContributionManual     = "manual"
+	ContributionSweep      = "sweep"
+	ContributionWithdrawal = "withdrawal" // Disimpan dengan jumlah negatif
+)
+
+// SavingsGoal adalah tujuan tabungan bersama dalam satu household.
+// UserID adalah anggota yang membuatnya.
+type SavingsGoal struct {
+	ID          int        `json:"id_goal"`
+	HouseholdID int        `json:"id_household"`
+	UserID      int        `json:"id_user"`
+	Name        string     `json:"nama_goal"`
+	Target      float64    `json:"target"`
+	Deadline    *time.Time `json:"deadline,omitempty"`
+	AutoSweep   bool       `json:"auto_sweep"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Saved       float64    `json:"terkumpul"`
+}
+
+// SavingsGoalRequest adalah body untuk membuat/mengubah tujuan tabungan.
+// deadline berformat YYYY-MM-DD dan boleh dikosongkan.
+type SavingsGoalRequest struct {
+	Name      string  `json:"nama_goal" binding:"required"`
+	Target    float64 `json:"target" binding:"required"`
+	Deadline  string  `json:"deadline"`
+	AutoSweep bool    `json:"auto_sweep"`
+}
+
+// SavingsContribution adalah satu setoran ke tujuan tabungan
+type SavingsContribution struct {
+	ID        int       `json:"id_kontribusi"`
+	GoalID    int       `json:"id_goal"`
+	Amount    float64   `json:"jumlah"`
+	Source    string    `json:"sumber"`
+	BudgetID  *int      `json:"id_anggaran,omitempty"`
+	Note      string    `json:"catatan,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ContributionRequest adalah body untuk setoran manual maupun penarikan. Jumlah selalu positif;
+// penarikan memakai endpoint tersendiri.
+type ContributionRequest struct {
+	Amount float64 `json:"jumlah" binding:"required"`
+	Note   string  `json:"catatan"`
+}
+
+// SavingsProgress adalah kemajuan tujuan tabungan beserta proyeksi tanggal tercapainya
+type SavingsProgress struct {
+	SavingsGoal
+	Remaining           float64    `json:"kekurangan"`
+	Percent             float64    `json:"persen"`
+	Completed           bool       `json:"tercapai"`
+	AvgDailySurplus     float64    `json:"rata_rata_surplus_harian"`
+	ProjectedCompletion *time.Time `json:"proyeksi_selesai"`           // nil jika surplus rata-rata tidak positif
+	OnTrack             *bool      `json:"sesuai_deadline,omitempty"`  // nil jika tidak ada deadline
+	RequiredPerWeek     float64    `json:"perlu_per_minggu,omitempty"` // Setoran per minggu agar tepat deadline
+}
+
+// ProjectSavings menghitung kemajuan goal dan proyeksi tanggal tercapainya
+// dengan asumsi surplus harian rata-rata (dari riwayat budget) terus disisihkan.
+func ProjectSavings(goal SavingsGoal, avgDailySurplus float64, today time.Time) SavingsProgress {
+	p := SavingsProgress{
+		SavingsGoal:     goal,
+		Remaining:       math.Max(0, goal.Target-goal.Saved),
+		AvgDailySurplus: math.Round(avgDailySurplus*100) / 100,
+	}
+	if goal.Target > 0 {
+		p.Percent = math.Round(goal.Saved/goal.Target*10000) / 100
+	}
+	p.Completed = p.Remaining == 0
+
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	if p.Completed {
+		p.ProjectedCompletion = &today
+	} else if avgDailySurplus > 0 {
+		projected := today.AddDate(0, 0, int(math.Ceil(p.Remaining/avgDailySurplus)))
+		p.ProjectedCompletion = &projected
+	}
+
+	if goal.Deadline != nil {
+		onTrack := p.ProjectedCompletion != nil && !p.ProjectedCompletion.After(*goal.Deadline)
+		p.OnTrack = &onTrack
+		if !p.Completed {
+			days := goal.Deadline.Sub(today).Hours() / 24
+			weeks := math.Max(1, math.Ceil(days/7))
+			p.RequiredPerWeek = math.Round(p.Remaining/weeks*100) / 100
+		}
+	}
+	return p
+}
+
+// DistributeSurplus membagi surplus budget ke goal auto-sweep yang belum tercapai.
+// Goal dengan deadline terdekat diisi lebih dulu (goal tanpa deadline terakhir),
+// masing-masing maksimal sebesar kekurangannya. Surplus yang tidak terbagi tetap menjadi sisa budget.
+func DistributeSurplus(surplus float64, goals []SavingsGoal) map[int]float64 {
+	result := make(map[int]float64)
+	if surplus <= 0 {
+		return result
+	}
+
+	sorted := make([]SavingsGoal, 0, len(goals))
+	for _, g := range goals {
+		if g.AutoSweep && g.Saved < g.Target {
+			sorted = append(sorted, g)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Deadline, sorted[j].Deadline
+		switch {
+		case a == nil && b == nil:
+			return sorted[i].ID < sorted[j].ID
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		}
+		return a.Before(*b)
+	})
+
+	left := surplus
+	for _, g := range sorted {
+		if left <= 0 {
+			break
+		}
+		amount := math.Min(left, g.Target-g.Saved)
+		result[g.ID] = math.Round(amount*100) / 100
+		left -= amount
+	}
+	return result
+}
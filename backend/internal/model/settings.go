@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+)
+
+// Kebijakan rollover sisa budget
+const (
+	RolloverNone        = "none"         // Sisa tidak dibawa
+	RolloverFull        = "full"         // Surplus dan defisit dibawa penuh
+	RolloverCapped      = "capped"       // Surplus dibawa maksimal RolloverCap, defisit dibawa penuh
+	RolloverDeficitOnly = "deficit_only" // Hanya defisit yang mengurangi budget berikutnya
+)
+
+// UserSettings adalah pengaturan per user
+type UserSettings struct {
+	UserID         int     `json:"id_user"`
+	RolloverPolicy string  `json:"rollover_policy"`
+	RolloverCap    float64 `json:"rollover_cap"`
+	NotifyEmail    bool    `json:"notify_email"` // Kirim notifikasi juga lewat email
+	Timezone       string  `json:"timezone"`     // Nama zona waktu IANA, misal "Asia/Jakarta"
+	WeekStart      int     `json:"week_start"`   // Hari pertama minggu: 0 = Minggu, 1 = Senin, ...
+}
+
+// DefaultUserSettings adalah pengaturan untuk user yang belum menyimpan pengaturan
+func DefaultUserSettings(userID int) UserSettings {
+	return UserSettings{
+		UserID:         userID,
+		RolloverPolicy: RolloverNone,
+		Timezone:       calendar.DefaultTimezone,
+		WeekStart:      int(calendar.DefaultWeekStart),
+	}
+}
+
+// Calendar membuat calendar.Calendar dari pengaturan zona waktu dan awal minggu.
+// Pengaturan yang tidak valid jatuh ke nilai bawaan.
+func (s UserSettings) Calendar() calendar.Calendar {
+	cal, err := calendar.New(s.Timezone, time.Weekday(s.WeekStart))
+	if err != nil {
+		return calendar.Default()
+	}
+	return cal
+}
+
+// ValidRolloverPolicy memeriksa apakah kebijakan rollover dikenali
+func ValidRolloverPolicy(p string) bool {
+	switch p {
+	case RolloverNone, RolloverFull, RolloverCapped, RolloverDeficitOnly:
+		return true
+	}
+	return false
+}
+
+// CarryOver menghitung jumlah yang dibawa ke periode berikutnya dari sisa periode sebelumnya.
+// Nilai positif menambah budget berikutnya, nilai negatif menguranginya.
+func (s UserSettings) CarryOver(previousRemaining float64) float64 {
+	switch s.RolloverPolicy {
+	case RolloverFull:
+		return previousRemaining
+	case RolloverCapped:
+		if previousRemaining > s.RolloverCap {
+			return s.RolloverCap
+		}
+		return previousRemaining
+	case RolloverDeficitOnly:
+		if previousRemaining < 0 {
+			return previousRemaining
+		}
+	}
+	return 0
+}
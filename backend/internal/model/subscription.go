@@ -0,0 +1,151 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+)
+
+// Frekuensi langganan laporan email
+const (
+	ReportFrequencyWeekly  = "weekly"
+	ReportFrequencyMonthly = "monthly"
+)
+
+// Status kiriman laporan terjadwal
+const (
+	ReportJobPending = "pending"
+	ReportJobSending = "sending" // Sudah diklaim satu replika, email sedang dikirim
+	ReportJobDone    = "done"
+	ReportJobFailed  = "failed"
+)
+
+// MaxReportJobAttempts adalah batas percobaan kirim sebelum kiriman ditandai gagal
+const MaxReportJobAttempts = 3
+
+// ReportJobClaimTimeout adalah batas waktu kiriman berstatus 'sending'. Setelah lewat,
+// kiriman dianggap terhenti (misal server mati saat mengirim) dan boleh diklaim ulang.
+const ReportJobClaimTimeout = 15 * time.Minute
+
+// Nilai default langganan: Senin / tanggal 1, pukul 07:00
+const (
+	DefaultReportDay   = 1
+	DefaultReportClock = "07:00"
+)
+
+// ReportSubscription adalah langganan laporan berkala milik user
+type ReportSubscription struct {
+	ID        int        `json:"id_langganan"`
+	UserID    int        `json:"id_user"`
+	Format    string     `json:"format"`    // Sama dengan 'type' pada /reports/download
+	Frequency string     `json:"frekuensi"` // weekly | monthly
+	Day       int        `json:"hari"`      // weekly: 0 = Minggu ... 6 = Sabtu; monthly: tanggal 1..28
+	Clock     string     `json:"jam"`       // "HH:MM" di zona waktu user
+	Active    bool       `json:"aktif"`
+	NextRunAt time.Time  `json:"jadwal_berikutnya"`
+	LastRunAt *time.Time `json:"terakhir_dikirim,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ReportSubscriptionRequest adalah body untuk membuat/mengubah langganan laporan.
+// Field yang kosong memakai default (format pdf, hari Senin/tanggal 1, jam 07:00, aktif).
+type ReportSubscriptionRequest struct {
+	Format    string `json:"format"`
+	Frequency string `json:"frekuensi" binding:"required"`
+	Day       *int   `json:"hari"`
+	Clock     string `json:"jam"`
+	Active    *bool  `json:"aktif"`
+}
+
+// ReportJob adalah satu kiriman terjadwal beserta langganannya
+type ReportJob struct {
+	ID           int
+	RunAt        time.Time
+	Attempts     int // Termasuk percobaan yang sedang berjalan
+	ClaimedAt    time.Time
+	Subscription ReportSubscription
+}
+
+// StatusAfterFailure adalah status kiriman setelah percobaan saat ini gagal:
+// kembali ke antrean, atau gagal permanen jika batas percobaan sudah tercapai.
+func (j *ReportJob) StatusAfterFailure() string {
+	if j.Attempts >= MaxReportJobAttempts {
+		return ReportJobFailed
+	}
+	return ReportJobPending
+}
+
+// ValidateSchedule memeriksa frekuensi, hari dan jam langganan
+func (s *ReportSubscription) ValidateSchedule() error {
+	switch s.Frequency {
+	case ReportFrequencyWeekly:
+		if s.Day < 0 || s.Day > 6 {
+			return fmt.Errorf("hari untuk frekuensi weekly harus 0 (Minggu) sampai 6 (Sabtu)")
+		}
+	case ReportFrequencyMonthly:
+		// Dibatasi 28 agar setiap bulan punya tanggal kirim
+		if s.Day < 1 || s.Day > 28 {
+			return fmt.Errorf("hari untuk frekuensi monthly harus tanggal 1 sampai 28")
+		}
+	default:
+		return fmt.Errorf("frekuensi harus weekly atau monthly")
+	}
+	if _, _, err := parseClock(s.Clock); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NextRun menghitung jadwal kirim pertama yang jatuh setelah 'after',
+// berdasarkan hari dan jam di kalender (zona waktu) user.
+// Langganan harus sudah lolos ValidateSchedule.
+func (s *ReportSubscription) NextRun(cal calendar.Calendar, after time.Time) time.Time {
+	hour, minute, _ := parseClock(s.Clock)
+	local := after.In(cal.Location())
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hour, minute, 0, 0, cal.Location())
+	}
+
+	if s.Frequency == ReportFrequencyMonthly {
+		next := at(local.Year(), local.Month(), s.Day)
+		if !next.After(after) {
+			next = at(local.Year(), local.Month()+1, s.Day)
+		}
+		return next
+	}
+
+	next := at(local.Year(), local.Month(), local.Day())
+	for next.Weekday() != time.Weekday(s.Day) || !next.After(after) {
+		next = at(next.Year(), next.Month(), next.Day()+1)
+	}
+	return next
+}
+
+// ReportQuery menentukan periode laporan untuk kiriman pada runAt: weekly = 7 hari
+// sampai kemarin (tren harian), monthly = bulan kalender sebelumnya (tren mingguan).
+// Keduanya dibandingkan dengan periode sebelumnya.
+func (s *ReportSubscription) ReportQuery(cal calendar.Calendar, runAt time.Time) ReportQuery {
+	today := cal.StartOfDay(runAt)
+	q := ReportQuery{Compare: ComparePrevious}
+	if s.Frequency == ReportFrequencyMonthly {
+		firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+		q.From = firstOfMonth.AddDate(0, -1, 0)
+		q.To = cal.EndOfDay(firstOfMonth.AddDate(0, 0, -1))
+		q.Granularity = GranularityWeek
+		return q
+	}
+	q.From = today.AddDate(0, 0, -7)
+	q.To = cal.EndOfDay(today.AddDate(0, 0, -1))
+	q.Granularity = GranularityDay
+	return q
+}
+
+// parseClock membaca jam "HH:MM" (00:00 - 23:59)
+func parseClock(clock string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, 0, fmt.Errorf("jam harus berformat HH:MM")
+	}
+	return t.Hour(), t.Minute(), nil
+}
@@ -0,0 +1,21 @@
+package model
+
+import "testing"
+
+func TestReportJobStatusAfterFailure(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     string
+	}{
+		{1, ReportJobPending},
+		{MaxReportJobAttempts - 1, ReportJobPending},
+		{MaxReportJobAttempts, ReportJobFailed},
+		{MaxReportJobAttempts + 1, ReportJobFailed}, // Klaim ulang setelah timeout
+	}
+	for _, tt := range tests {
+		job := ReportJob{Attempts: tt.attempts}
+		if got := job.StatusAfterFailure(); got != tt.want {
+			t.Errorf("StatusAfterFailure() with %d attempts = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
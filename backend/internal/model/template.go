@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// Aturan pengulangan template
+const (
+	RecurrenceNone     = "none"
+	RecurrenceWeekly   = "weekly"
+	RecurrenceBiweekly = "biweekly"
+	RecurrenceMonthly  = "monthly"
+)
+
+// ItemTemplate adalah daftar belanja yang bisa dipakai ulang setiap periode
+type ItemTemplate struct {
+	ID          int             `json:"id_template"`
+	HouseholdID int             `json:"id_household"`
+	UserID      int             `json:"id_user"`
+	Name        string          `json:"nama_template"`
+	Recurrence  string          `json:"recurrence"`
+	StartDate   time.Time       `json:"start_date"`
+	Entries     []TemplateEntry `json:"entries"`
+}
+
+// TemplateEntry adalah satu baris item di dalam template
+type TemplateEntry struct {
+	ID         int     `json:"id_entry"`
+	CategoryID *int    `json:"id_kategori"`
+	ItemName   string  `json:"nama_item" binding:"required"`
+	Quantity   int     `json:"jumlah_item" binding:"required"`
+	UnitPrice  float64 `json:"harga_satuan"`
+}
+
+// TemplateRequest adalah body untuk membuat/mengubah template
+type TemplateRequest struct {
+	Name       string          `json:"nama_template" binding:"required"`
+	Recurrence string          `json:"recurrence"`
+	StartDate  string          `json:"start_date"` // YYYY-MM-DD, opsional
+	Entries    []TemplateEntry `json:"entries" binding:"required,dive"`
+}
+
+// ValidRecurrence memeriksa apakah aturan pengulangan dikenali
+func ValidRecurrence(r string) bool {
+	switch r {
+	case RecurrenceNone, RecurrenceWeekly, RecurrenceBiweekly, RecurrenceMonthly:
+		return true
+	}
+	return false
+}
+
+// IsDueInWeek menentukan apakah template harus di-generate untuk minggu
+// yang dimulai pada weekStart (weekEnd adalah hari terakhir minggu itu).
+func (t *ItemTemplate) IsDueInWeek(weekStart, weekEnd time.Time) bool {
+	anchor := time.Date(t.StartDate.Year(), t.StartDate.Month(), t.StartDate.Day(), 0, 0, 0, 0, weekStart.Location())
+	if anchor.After(weekEnd) {
+		return false
+	}
+
+	switch t.Recurrence {
+	case RecurrenceWeekly:
+		return true
+	case RecurrenceBiweekly:
+		// Hitung selisih minggu dari minggu pertama template
+		offset := (int(anchor.Weekday()) - int(weekStart.Weekday()) + 7) % 7
+		anchorWeekStart := anchor.AddDate(0, 0, -offset)
+		weeks := int(weekStart.Sub(anchorWeekStart).Round(24*time.Hour).Hours()/24) / 7
+		return weeks%2 == 0
+	case RecurrenceMonthly:
+		// Jatuh tempo pada minggu yang memuat tanggal yang sama dengan start_date
+		// (dipotong ke akhir bulan untuk bulan yang lebih pendek)
+		for d := weekStart; !d.After(weekEnd); d = d.AddDate(0, 0, 1) {
+			lastDay := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+			day := anchor.Day()
+			if day > lastDay {
+				day = lastDay
+			}
+			if d.Day() == day {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Jenis data yang bisa masuk trash
+const (
+	TrashTypeItem     = "item"
+	TrashTypeCategory = "kategori"
+	TrashTypeBudget   = "budget"
+)
+
+// TrashEntry adalah satu baris yang sudah di-soft delete dan masih bisa dipulihkan
+type TrashEntry struct {
+	Type      string    `json:"type"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"` // Nama item/kategori, atau periode budget
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -1,5 +1,11 @@
 package model
 
+// Role user
+const (
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+)
+
 // User represents the data structure for the "User" entity in the database (TK2 ERD)
 type User struct {
 	ID       int    `json:"id_user"`
@@ -26,6 +32,7 @@ type RegisterRequest struct {
 
 // LoginResponse defines the data structure returned upon successful login
 type LoginResponse struct {
-	Token string `json:"token"`
-	Role  string `json:"role"`
+	Token  string `json:"token"`
+	Role   string `json:"role"`
+	UserID int    `json:"-"` // Untuk log audit login, tidak dikirim ke klien
 }
@@ -0,0 +1,48 @@
+// Package notifier meneruskan notifikasi ke user lewat channel di luar aplikasi (email, ...).
+// Notifikasi in-app disimpan langsung oleh repository bersama klaim ambangnya.
+// Channel baru cukup mengimplementasikan antarmuka Channel dan didaftarkan di main.
+package notifier
+
+import (
+	"context"
+
+	"github.com/gusti3111/TKBMG/backend/internal/mailer"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// Recipient adalah penerima notifikasi beserta pengaturannya
+type Recipient struct {
+	User     model.User
+	Settings model.UserSettings
+}
+
+// Channel adalah satu jalur pengiriman notifikasi
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, to Recipient, n *model.Notification) error
+}
+
+// EmailChannel mengirim notifikasi lewat email ke user yang mengaktifkan notify_email
+type EmailChannel struct {
+	mailer mailer.Mailer
+}
+
+// NewEmailChannel membuat channel email
+func NewEmailChannel(m mailer.Mailer) *EmailChannel {
+	return &EmailChannel{mailer: m}
+}
+
+// Name mengembalikan nama channel
+func (c *EmailChannel) Name() string { return "email" }
+
+// Send mengirim email; dilewati jika user tidak mengaktifkan email atau tidak punya alamat email
+func (c *EmailChannel) Send(ctx context.Context, to Recipient, n *model.Notification) error {
+	if !to.Settings.NotifyEmail || to.User.Email == "" {
+		return nil
+	}
+	return c.mailer.Send(ctx, mailer.Message{
+		To:      []string{to.User.Email},
+		Subject: n.Title,
+		Body:    "Halo " + to.User.Name + ",\n\n" + n.Message + "\n",
+	})
+}
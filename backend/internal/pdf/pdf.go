@@ -0,0 +1,322 @@
+// Package pdf adalah penulis PDF minimal tanpa dependensi eksternal:
+// halaman A4, font standar Helvetica (WinAnsiEncoding), teks, garis, kotak berwarna,
+// dan gambar raster. Koordinat memakai titik (1/72 inci) dengan titik asal di kiri atas.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+// Ukuran kertas A4 dalam titik
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font standar yang tersedia
+const (
+	Helvetica     = "F1"
+	HelveticaBold = "F2"
+)
+
+// Color adalah warna RGB dengan komponen 0..255
+type Color struct{ R, G, B uint8 }
+
+// Warna umum
+var (
+	Black     = Color{0, 0, 0}
+	White     = Color{255, 255, 255}
+	Gray      = Color{110, 110, 110}
+	LightGray = Color{230, 230, 230}
+)
+
+type page struct {
+	content bytes.Buffer
+}
+
+type imageObject struct {
+	name   string
+	width  int
+	height int
+	data   []byte // RGB terkompresi zlib
+}
+
+// Document adalah dokumen PDF yang sedang disusun di memori
+type Document struct {
+	pages    []*page
+	current  int
+	font     string
+	fontSize float64
+	images   []imageObject
+}
+
+// New membuat dokumen A4 kosong tanpa halaman
+func New() *Document {
+	return &Document{current: -1, font: Helvetica, fontSize: 10}
+}
+
+// AddPage menambah halaman baru dan menjadikannya halaman aktif
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &page{})
+	d.current = len(d.pages) - 1
+}
+
+// PageCount mengembalikan jumlah halaman
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// SetPage memilih halaman aktif (dimulai dari 0), misal untuk menulis footer setelah semua halaman dibuat
+func (d *Document) SetPage(index int) {
+	if index >= 0 && index < len(d.pages) {
+		d.current = index
+	}
+}
+
+// SetFont mengatur font dan ukuran untuk teks berikutnya
+func (d *Document) SetFont(font string, size float64) {
+	d.font = font
+	d.fontSize = size
+}
+
+// FontSize mengembalikan ukuran font aktif
+func (d *Document) FontSize() float64 {
+	return d.fontSize
+}
+
+// Text menulis teks dengan garis dasar (baseline) di y
+func (d *Document) Text(x, y float64, s string, color Color) {
+	fmt.Fprintf(d.out(), "BT %s /%s %s Tf %s %s Td (%s) Tj ET\n",
+		fillColor(color), d.font, num(d.fontSize), num(x), num(A4Height-y), escape(s))
+}
+
+// TextRight menulis teks rata kanan yang berakhir di x
+func (d *Document) TextRight(x, y float64, s string, color Color) {
+	d.Text(x-d.TextWidth(s), y, s, color)
+}
+
+// TextWidth menghitung lebar teks dengan font dan ukuran aktif
+func (d *Document) TextWidth(s string) float64 {
+	widths := &helveticaWidths
+	if d.font == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, b := range encode(s) {
+		w := 556
+		if b >= 32 && b <= 126 {
+			w = widths[b-32]
+		}
+		total += w
+	}
+	return float64(total) * d.fontSize / 1000
+}
+
+// Truncate memotong teks dengan "..." agar lebarnya tidak melebihi maxWidth
+func (d *Document) Truncate(s string, maxWidth float64) string {
+	if d.TextWidth(s) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && d.TextWidth(string(runes)+"...") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// Line menggambar garis lurus
+func (d *Document) Line(x1, y1, x2, y2, width float64, color Color) {
+	fmt.Fprintf(d.out(), "%s %s w %s %s m %s %s l S\n",
+		strokeColor(color), num(width), num(x1), num(A4Height-y1), num(x2), num(A4Height-y2))
+}
+
+// FillRect menggambar kotak berisi warna; (x, y) adalah sudut kiri atas
+func (d *Document) FillRect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(d.out(), "%s %s %s %s %s re f\n",
+		fillColor(color), num(x), num(A4Height-y-h), num(w), num(h))
+}
+
+// Image menempatkan gambar raster pada kotak (x, y, w, h); (x, y) adalah sudut kiri atas
+func (d *Document) Image(img image.Image, x, y, w, h float64) error {
+	obj, err := newImageObject(fmt.Sprintf("Im%d", len(d.images)+1), img)
+	if err != nil {
+		return err
+	}
+	d.images = append(d.images, obj)
+	fmt.Fprintf(d.out(), "q %s 0 0 %s %s %s cm /%s Do Q\n",
+		num(w), num(h), num(x), num(A4Height-y-h), obj.name)
+	return nil
+}
+
+// WriteTo menulis dokumen PDF lengkap ke w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	// Objek 1 = Catalog, 2 = Pages, 3-4 = font, lalu gambar, lalu halaman + konten
+	writeObj := func(body string) int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", id, body)
+		return id
+	}
+	writeStream := func(dict string, data []byte) int {
+		offsets = append(offsets, buf.Len())
+		id := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", id, dict, len(data))
+		buf.Write(data)
+		buf.WriteString("\nendstream\nendobj\n")
+		return id
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	firstPage := 5 + len(d.images)
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), num(A4Width), num(A4Height)))
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	writeObj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	var xobjects strings.Builder
+	for i, img := range d.images {
+		id := writeStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+			img.width, img.height), img.data)
+		fmt.Fprintf(&xobjects, "/%s %d 0 R ", d.images[i].name, id)
+	}
+	resources := fmt.Sprintf("<< /Font << /F1 3 0 R /F2 4 0 R >> /XObject << %s>> >>", xobjects.String())
+
+	for i, p := range d.pages {
+		contentID := firstPage + 2*i + 1
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources %s /Contents %d 0 R >>", resources, contentID))
+		compressed, err := deflate(p.content.Bytes())
+		if err != nil {
+			return 0, err
+		}
+		writeStream("/Filter /FlateDecode", compressed)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+func (d *Document) out() *bytes.Buffer {
+	if d.current < 0 {
+		d.AddPage()
+	}
+	return &d.pages[d.current].content
+}
+
+func newImageObject(name string, img image.Image) (imageObject, error) {
+	bounds := img.Bounds()
+	raw := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			raw = append(raw, uint8(r>>8), uint8(g>>8), uint8(b>>8))
+		}
+	}
+	data, err := deflate(raw)
+	if err != nil {
+		return imageObject{}, err
+	}
+	return imageObject{name: name, width: bounds.Dx(), height: bounds.Dy(), data: data}, nil
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("pdf: failed to compress stream: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("pdf: failed to compress stream: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encode mengubah teks UTF-8 ke WinAnsi (Latin-1); karakter lain menjadi '?'
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 256 {
+			out = append(out, byte(r))
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	for _, c := range encode(s) {
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			if c < 32 || c > 126 {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+func fillColor(c Color) string {
+	return fmt.Sprintf("%s %s %s rg", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+func strokeColor(c Color) string {
+	return fmt.Sprintf("%s %s %s RG", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+// num memformat angka dengan titik desimal (format PDF, bukan format lokal)
+func num(v float64) string {
+	s := fmt.Sprintf("%.3f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// Lebar glyph (per 1000 unit) karakter 32..126 dari metrik AFM standar
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// utf8BOM dikenali Microsoft Excel sebagai penanda berkas UTF-8
+const utf8BOM = "\ufeff"
+
+// CSVWriter menulis daftar item laporan sebagai CSV (RFC 4180: koma, baris CRLF,
+// kutip ganda bila perlu). Baris ditulis langsung saat item dibaca dari database.
+// Angka memakai titik desimal tanpa pemisah ribuan dan tanggal berformat YYYY-MM-DD
+// agar mudah diolah ulang.
+type CSVWriter struct {
+	BOM bool // Tambahkan UTF-8 BOM di awal berkas
+}
+
+// ContentType implements ReportWriter
+func (CSVWriter) ContentType() string { return "text/csv; charset=utf-8" }
+
+// Extension implements ReportWriter
+func (CSVWriter) Extension() string { return ".csv" }
+
+// WriteReport implements ReportWriter
+func (cw CSVWriter) WriteReport(w io.Writer, data *model.ReportData, items ItemSource) error {
+	if cw.BOM {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return err
+		}
+	}
+
+	out := csv.NewWriter(w)
+	out.UseCRLF = true
+	if err := out.Write([]string{"Tanggal", "Nama Item", "Kategori", "Jumlah", "Harga Satuan", "Total"}); err != nil {
+		return err
+	}
+
+	err := items(func(item model.ReportItem) error {
+		return out.Write([]string{
+			item.Tanggal.Format(calendar.DateLayout),
+			item.NamaItem,
+			item.Kategori,
+			strconv.Itoa(item.Jumlah),
+			formatFloat(item.HargaSatuan),
+			formatFloat(item.Total),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// formatFloat memformat angka untuk berkas yang dibaca mesin (titik desimal, tanpa pemisah ribuan)
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,547 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/xuri/excelize/v2"
+)
+
+// Nama sheet pada workbook laporan
+const (
+	sheetSummary  = "Ringkasan"
+	sheetCategory = "Per Kategori"
+	sheetCompare  = "Perbandingan"
+	sheetTrend    = "Tren"
+	sheetInflasi  = "Inflasi"
+	sheetItems    = "Detail Item"
+)
+
+// Format angka Excel. [$-421] = locale Indonesia, sehingga nama bulan tampil dalam bahasa Indonesia.
+const (
+	numFmtRupiah  = `"Rp "#,##0`
+	numFmtPercent = `0.0%`
+	numFmtDate    = `[$-421]d mmmm yyyy`
+	numFmtMonth   = `[$-421]mmmm yyyy`
+	numFmtIndex   = `0.0`
+	numFmtPoints  = `+0.00;-0.00;0.00`
+)
+
+// ExcelWriter menulis laporan berformat .xlsx dengan sheet Ringkasan (budget vs realisasi
+// beserta grafik), Per Kategori, Perbandingan (jika diminta), Tren, Inflasi (indeks harga
+// pribadi, jika riwayat cukup) dan Detail Item. Total dihitung dengan
+// rumus Excel sehingga tetap benar jika user mengubah angka. Sheet Detail Item ditulis
+// dengan StreamWriter agar item dalam jumlah besar tidak ditampung di memori.
+type ExcelWriter struct{}
+
+// ContentType implements ReportWriter
+func (ExcelWriter) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// Extension implements ReportWriter
+func (ExcelWriter) Extension() string { return ".xlsx" }
+
+// excelStyles adalah ID style yang dipakai bersama oleh semua sheet
+type excelStyles struct {
+	title, header, label               int
+	rupiah, rupiahTotal, percent, date int
+	percentTotal, labelTotal, integer  int
+	month, index, points               int
+}
+
+// WriteReport implements ReportWriter
+func (ExcelWriter) WriteReport(w io.Writer, data *model.ReportData, items ItemSource) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	st, err := newExcelStyles(f)
+	if err != nil {
+		return err
+	}
+
+	// Sheet default "Sheet1" diganti namanya agar Ringkasan menjadi sheet pertama
+	if err := f.SetSheetName("Sheet1", sheetSummary); err != nil {
+		return err
+	}
+	sheets := []string{sheetCategory}
+	if data.Comparison != nil {
+		sheets = append(sheets, sheetCompare)
+	}
+	sheets = append(sheets, sheetTrend)
+	if hasInflation(data) {
+		sheets = append(sheets, sheetInflasi)
+	}
+	sheets = append(sheets, sheetItems)
+	for _, name := range sheets {
+		if _, err := f.NewSheet(name); err != nil {
+			return err
+		}
+	}
+
+	categoryTotal, err := writeCategorySheet(f, st, data)
+	if err != nil {
+		return err
+	}
+	compareTotalRow := 0
+	if data.Comparison != nil {
+		if compareTotalRow, err = writeComparisonSheet(f, st, data.Comparison); err != nil {
+			return err
+		}
+	}
+	trendRows, err := writeTrendSheet(f, st, data)
+	if err != nil {
+		return err
+	}
+	if hasInflation(data) {
+		if err := writeInflationSheet(f, st, data.Inflation); err != nil {
+			return err
+		}
+	}
+	if err := writeItemSheet(f, st, items); err != nil {
+		return err
+	}
+	if err := writeSummarySheet(f, st, data, categoryTotal, compareTotalRow, trendRows); err != nil {
+		return err
+	}
+
+	// Rumus ditulis tanpa nilai cache; minta aplikasi spreadsheet menghitung ulang saat dibuka
+	fullCalc := true
+	if err := f.SetCalcProps(&excelize.CalcPropsOptions{FullCalcOnLoad: &fullCalc}); err != nil {
+		return err
+	}
+
+	f.SetActiveSheet(0)
+	return f.Write(w)
+}
+
+func newExcelStyles(f *excelize.File) (excelStyles, error) {
+	var st excelStyles
+	rupiah, percent, date := numFmtRupiah, numFmtPercent, numFmtDate
+	month, index, points := numFmtMonth, numFmtIndex, numFmtPoints
+	border := []excelize.Border{
+		{Type: "top", Color: "#9E9E9E", Style: 1},
+		{Type: "bottom", Color: "#9E9E9E", Style: 1},
+	}
+	topBorder := []excelize.Border{{Type: "top", Color: "#424242", Style: 2}}
+
+	defs := []struct {
+		id    *int
+		style *excelize.Style
+	}{
+		{&st.title, &excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}}},
+		{&st.header, &excelize.Style{
+			Font:   &excelize.Font{Bold: true},
+			Fill:   excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+			Border: border,
+		}},
+		{&st.label, &excelize.Style{Font: &excelize.Font{Color: "#616161"}}},
+		{&st.rupiah, &excelize.Style{CustomNumFmt: &rupiah}},
+		{&st.percent, &excelize.Style{CustomNumFmt: &percent}},
+		{&st.date, &excelize.Style{CustomNumFmt: &date}},
+		{&st.month, &excelize.Style{CustomNumFmt: &month}},
+		{&st.index, &excelize.Style{CustomNumFmt: &index}},
+		{&st.points, &excelize.Style{CustomNumFmt: &points}},
+		{&st.integer, &excelize.Style{NumFmt: 3}}, // #,##0
+		{&st.labelTotal, &excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder}},
+		{&st.rupiahTotal, &excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder, CustomNumFmt: &rupiah}},
+		{&st.percentTotal, &excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder, CustomNumFmt: &percent}},
+	}
+	for _, d := range defs {
+		id, err := f.NewStyle(d.style)
+		if err != nil {
+			return st, fmt.Errorf("report: failed to create excel style: %w", err)
+		}
+		*d.id = id
+	}
+	return st, nil
+}
+
+// writeCategorySheet mengisi sheet Per Kategori dan mengembalikan alamat sel total belanja
+func writeCategorySheet(f *excelize.File, st excelStyles, data *model.ReportData) (string, error) {
+	s := sheetCategory
+	if err := writeHeader(f, st, s, []string{"Kategori", "Total", "Persentase"}); err != nil {
+		return "", err
+	}
+
+	last := len(data.Categories) + 1
+	totalRow := last + 1
+	for i, c := range data.Categories {
+		row := i + 2
+		f.SetCellValue(s, cell("A", row), c.Kategori)
+		f.SetCellValue(s, cell("B", row), c.Total)
+		f.SetCellFormula(s, cell("C", row), fmt.Sprintf("IF($B$%d>0,B%d/$B$%d,0)", totalRow, row, totalRow))
+	}
+	f.SetCellValue(s, cell("A", totalRow), "Total")
+	f.SetCellFormula(s, cell("B", totalRow), sumRange("B", last))
+	f.SetCellFormula(s, cell("C", totalRow), sumRange("C", last))
+
+	if last >= 2 {
+		f.SetCellStyle(s, "B2", cell("B", last), st.rupiah)
+		f.SetCellStyle(s, "C2", cell("C", last), st.percent)
+	}
+	f.SetCellStyle(s, cell("A", totalRow), cell("A", totalRow), st.labelTotal)
+	f.SetCellStyle(s, cell("B", totalRow), cell("B", totalRow), st.rupiahTotal)
+	f.SetCellStyle(s, cell("C", totalRow), cell("C", totalRow), st.percentTotal)
+	f.SetColWidth(s, "A", "A", 32)
+	f.SetColWidth(s, "B", "C", 18)
+
+	if err := freezeAndFilter(f, s, "C", last); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("'%s'!$B$%d", s, totalRow), nil
+}
+
+// writeComparisonSheet mengisi sheet Perbandingan (selisih dan persentase perubahan per
+// kategori) dan mengembalikan nomor baris total
+func writeComparisonSheet(f *excelize.File, st excelStyles, cmp *model.ReportComparison) (int, error) {
+	s := sheetCompare
+	if err := writeHeader(f, st, s, []string{"Kategori", "Periode Ini", "Pembanding", "Selisih", "Perubahan"}); err != nil {
+		return 0, err
+	}
+
+	last := len(cmp.Categories) + 1
+	totalRow := last + 1
+	for i, c := range cmp.Categories {
+		row := i + 2
+		f.SetCellValue(s, cell("A", row), c.Kategori)
+		f.SetCellValue(s, cell("B", row), c.Total)
+		f.SetCellValue(s, cell("C", row), c.Pembanding)
+		f.SetCellFormula(s, cell("D", row), fmt.Sprintf("B%d-C%d", row, row))
+		f.SetCellFormula(s, cell("E", row), changeFormula("B", "C", row))
+	}
+	f.SetCellValue(s, cell("A", totalRow), "Total")
+	f.SetCellFormula(s, cell("B", totalRow), sumRange("B", last))
+	f.SetCellFormula(s, cell("C", totalRow), sumRange("C", last))
+	f.SetCellFormula(s, cell("D", totalRow), fmt.Sprintf("B%d-C%d", totalRow, totalRow))
+	f.SetCellFormula(s, cell("E", totalRow), changeFormula("B", "C", totalRow))
+
+	if last >= 2 {
+		f.SetCellStyle(s, "B2", cell("D", last), st.rupiah)
+		f.SetCellStyle(s, "E2", cell("E", last), st.percent)
+	}
+	f.SetCellStyle(s, cell("A", totalRow), cell("A", totalRow), st.labelTotal)
+	f.SetCellStyle(s, cell("B", totalRow), cell("D", totalRow), st.rupiahTotal)
+	f.SetCellStyle(s, cell("E", totalRow), cell("E", totalRow), st.percentTotal)
+	f.SetColWidth(s, "A", "A", 32)
+	f.SetColWidth(s, "B", "E", 18)
+
+	return totalRow, freezeAndFilter(f, s, "E", last)
+}
+
+// writeTrendSheet mengisi sheet Tren (periode terlama di atas) dan mengembalikan jumlah baris data
+func writeTrendSheet(f *excelize.File, st excelStyles, data *model.ReportData) (int, error) {
+	s := sheetTrend
+	if err := writeHeader(f, st, s, []string{"Periode", "Mulai", "Total", "Perubahan"}); err != nil {
+		return 0, err
+	}
+
+	last := len(data.Trend) + 1
+	for i, p := range data.Trend {
+		row := i + 2
+		f.SetCellValue(s, cell("A", row), p.Label)
+		f.SetCellValue(s, cell("B", row), p.Mulai)
+		f.SetCellValue(s, cell("C", row), p.Total)
+		if row > 2 {
+			// Perubahan dibanding periode sebelumnya; kosong jika periode sebelumnya tanpa belanja
+			f.SetCellFormula(s, cell("D", row), fmt.Sprintf(`IF(C%d>0,C%d/C%d-1,"")`, row-1, row, row-1))
+		}
+	}
+
+	totalRow, avgRow := last+1, last+2
+	f.SetCellValue(s, cell("A", totalRow), "Total")
+	f.SetCellFormula(s, cell("C", totalRow), sumRange("C", last))
+	f.SetCellValue(s, cell("A", avgRow), "Rata-rata per Periode")
+	f.SetCellFormula(s, cell("C", avgRow), fmt.Sprintf("IFERROR(AVERAGE(C2:C%d),0)", last))
+
+	if last >= 2 {
+		f.SetCellStyle(s, "B2", cell("B", last), st.date)
+		f.SetCellStyle(s, "C2", cell("C", last), st.rupiah)
+		f.SetCellStyle(s, "D2", cell("D", last), st.percent)
+	}
+	f.SetCellStyle(s, cell("A", totalRow), cell("D", totalRow), st.labelTotal)
+	f.SetCellStyle(s, cell("C", totalRow), cell("C", avgRow), st.rupiahTotal)
+	f.SetCellStyle(s, cell("A", avgRow), cell("A", avgRow), st.labelTotal)
+	f.SetColWidth(s, "A", "A", 22)
+	f.SetColWidth(s, "B", "D", 20)
+
+	return len(data.Trend), freezeAndFilter(f, s, "D", last)
+}
+
+// hasInflation melaporkan apakah laporan punya indeks harga yang bisa ditampilkan
+func hasInflation(data *model.ReportData) bool {
+	return data.Inflation != nil && len(data.Inflation.Months) > 0
+}
+
+// writeInflationSheet mengisi sheet Inflasi: indeks bulanan, perubahan bulanan/tahunan
+// (rumus), cakupan dan kontribusi per kategori (poin indeks), lalu keterangan periode
+// dasar beserta bobot kategori di bawah tabel
+func writeInflationSheet(f *excelize.File, st excelStyles, idx *model.InflationIndex) error {
+	s := sheetInflasi
+	titles := []string{"Bulan", "Indeks", "Perubahan Bulanan", "Perubahan Tahunan", "Cakupan"}
+	for _, w := range idx.Bobot {
+		titles = append(titles, "Kontribusi "+w.Kategori)
+	}
+	if err := writeHeader(f, st, s, titles); err != nil {
+		return err
+	}
+	lastCol, err := excelize.ColumnNumberToName(len(titles))
+	if err != nil {
+		return err
+	}
+
+	last := len(idx.Months) + 1
+	for i, m := range idx.Months {
+		row := i + 2
+		f.SetCellValue(s, cell("A", row), m.Bulan)
+		f.SetCellValue(s, cell("B", row), m.Indeks)
+		if row > 2 {
+			f.SetCellFormula(s, cell("C", row), fmt.Sprintf(`IF(B%d<>0,B%d/B%d-1,"")`, row-1, row, row-1))
+		}
+		if row > 13 {
+			// Perubahan tahunan dibanding bulan yang sama 12 baris di atas
+			f.SetCellFormula(s, cell("D", row), fmt.Sprintf(`IF(B%d<>0,B%d/B%d-1,"")`, row-12, row, row-12))
+		}
+		f.SetCellValue(s, cell("E", row), m.Cakupan/100)
+		for j, k := range m.Kontribusi {
+			col, _ := excelize.ColumnNumberToName(6 + j)
+			f.SetCellValue(s, cell(col, row), k.Kontribusi)
+		}
+	}
+
+	f.SetCellStyle(s, "A2", cell("A", last), st.month)
+	f.SetCellStyle(s, "B2", cell("B", last), st.index)
+	f.SetCellStyle(s, "C2", cell("E", last), st.percent)
+	if len(titles) > 5 {
+		f.SetCellStyle(s, "F2", cell(lastCol, last), st.points)
+	}
+
+	// Keterangan periode dasar dan bobot kategori
+	row := last + 2
+	f.SetCellValue(s, cell("A", row), "Periode Dasar")
+	f.SetCellValue(s, cell("B", row), helper.FormatPeriode(idx.BaseStart, idx.BaseEnd))
+	f.SetCellValue(s, cell("A", row+1), "Jumlah Produk")
+	f.SetCellValue(s, cell("B", row+1), idx.JumlahProduk)
+	f.SetCellValue(s, cell("A", row+2), "Nilai Keranjang Dasar")
+	f.SetCellValue(s, cell("B", row+2), idx.BelanjaDasar)
+	f.SetCellStyle(s, cell("A", row), cell("A", row+2), st.label)
+	f.SetCellStyle(s, cell("B", row+1), cell("B", row+1), st.integer)
+	f.SetCellStyle(s, cell("B", row+2), cell("B", row+2), st.rupiah)
+
+	row += 4
+	if err := writeHeaderAt(f, st, s, row, []string{"Kategori", "Bobot"}); err != nil {
+		return err
+	}
+	for i, w := range idx.Bobot {
+		f.SetCellValue(s, cell("A", row+1+i), w.Kategori)
+		f.SetCellValue(s, cell("B", row+1+i), w.Bobot/100)
+	}
+	if len(idx.Bobot) > 0 {
+		f.SetCellStyle(s, cell("B", row+1), cell("B", row+len(idx.Bobot)), st.percent)
+	}
+
+	f.SetColWidth(s, "A", "A", 24)
+	f.SetColWidth(s, "B", lastCol, 18)
+	return freezeAndFilter(f, s, lastCol, last)
+}
+
+// writeItemSheet mengalirkan item ke sheet Detail Item memakai StreamWriter
+func writeItemSheet(f *excelize.File, st excelStyles, items ItemSource) error {
+	sw, err := f.NewStreamWriter(sheetItems)
+	if err != nil {
+		return err
+	}
+	if err := sw.SetPanes(frozenHeader()); err != nil {
+		return err
+	}
+	sw.SetColWidth(1, 1, 20)
+	sw.SetColWidth(2, 3, 30)
+	sw.SetColWidth(4, 4, 10)
+	sw.SetColWidth(5, 6, 18)
+
+	header := []interface{}{"Tanggal", "Nama Item", "Kategori", "Jumlah", "Harga Satuan", "Total"}
+	for i, h := range header {
+		header[i] = excelize.Cell{StyleID: st.header, Value: h}
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+
+	row := 1
+	err = items(func(item model.ReportItem) error {
+		row++
+		return sw.SetRow(cell("A", row), []interface{}{
+			excelize.Cell{StyleID: st.date, Value: item.Tanggal},
+			item.NamaItem,
+			item.Kategori,
+			excelize.Cell{StyleID: st.integer, Value: item.Jumlah},
+			excelize.Cell{StyleID: st.rupiah, Value: item.HargaSatuan},
+			excelize.Cell{StyleID: st.rupiah, Value: item.Total},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	last := row
+	if err := sw.SetRow(cell("A", last+1), []interface{}{
+		excelize.Cell{StyleID: st.labelTotal, Value: "Total"},
+		excelize.Cell{StyleID: st.labelTotal},
+		excelize.Cell{StyleID: st.labelTotal},
+		excelize.Cell{StyleID: st.labelTotal, Formula: sumRange("D", last)},
+		excelize.Cell{StyleID: st.labelTotal},
+		excelize.Cell{StyleID: st.rupiahTotal, Formula: sumRange("F", last)},
+	}); err != nil {
+		return err
+	}
+
+	// Tabel Excel memberi autofilter pada header; minimal harus ada satu baris data
+	if last >= 2 {
+		if err := sw.AddTable(&excelize.Table{
+			Range:     "A1:" + cell("F", last),
+			Name:      "DetailItem",
+			StyleName: "TableStyleLight1",
+		}); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
+
+// writeSummarySheet mengisi sheet Ringkasan (budget vs realisasi) beserta pie chart
+// per kategori dan bar chart tren seperti di dasbor. compareTotalRow adalah baris total
+// sheet Perbandingan (0 jika tanpa perbandingan).
+func writeSummarySheet(f *excelize.File, st excelStyles, data *model.ReportData, categoryTotal string, compareTotalRow, trendRows int) error {
+	s := sheetSummary
+	f.SetCellValue(s, "A1", "Laporan Belanja")
+	f.SetCellStyle(s, "A1", "A1", st.title)
+
+	info := [][2]string{
+		{"Nama", data.UserName},
+		{"Periode", helper.FormatPeriode(data.PeriodStart, data.PeriodEnd)},
+		{"Dibuat", helper.FormatTanggal(data.GeneratedAt) + ", " + data.GeneratedAt.Format("15.04")},
+	}
+	for i, kv := range info {
+		f.SetCellValue(s, cell("A", i+3), kv[0])
+		f.SetCellValue(s, cell("B", i+3), kv[1])
+	}
+	f.SetCellStyle(s, "A3", "A5", st.label)
+
+	if err := writeHeaderAt(f, st, s, 7, []string{"Keterangan", "Nilai"}); err != nil {
+		return err
+	}
+	f.SetCellValue(s, "A8", "Total Budget")
+	f.SetCellValue(s, "B8", data.TotalBudget)
+	f.SetCellValue(s, "A9", "Total Belanja")
+	f.SetCellFormula(s, "B9", categoryTotal)
+	f.SetCellValue(s, "A10", "Sisa Budget")
+	f.SetCellFormula(s, "B10", "B8-B9")
+	f.SetCellValue(s, "A11", "Budget Terpakai")
+	f.SetCellFormula(s, "B11", "IF(B8>0,B9/B8,0)")
+	f.SetCellStyle(s, "B8", "B9", st.rupiah)
+	f.SetCellStyle(s, "A10", "A10", st.labelTotal)
+	f.SetCellStyle(s, "B10", "B10", st.rupiahTotal)
+	f.SetCellStyle(s, "B11", "B11", st.percent)
+	if cmp := data.Comparison; cmp != nil {
+		ref := func(col string) string { return fmt.Sprintf("'%s'!$%s$%d", sheetCompare, col, compareTotalRow) }
+		f.SetCellValue(s, "A12", "Belanja "+compareTitle(cmp.Mode))
+		f.SetCellFormula(s, "B12", ref("C"))
+		f.SetCellValue(s, "A13", "Selisih Belanja")
+		f.SetCellFormula(s, "B13", ref("D"))
+		f.SetCellValue(s, "A14", "Perubahan Belanja")
+		f.SetCellFormula(s, "B14", ref("E"))
+		f.SetCellStyle(s, "B12", "B13", st.rupiah)
+		f.SetCellStyle(s, "B14", "B14", st.percent)
+	}
+	f.SetColWidth(s, "A", "A", 20)
+	f.SetColWidth(s, "B", "B", 36)
+	if err := f.SetPanes(s, &excelize.Panes{Freeze: true, YSplit: 7, TopLeftCell: "A8", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+
+	if n := len(data.Categories); n > 0 {
+		if err := f.AddChart(s, "D2", &excelize.Chart{
+			Type: excelize.Pie,
+			Series: []excelize.ChartSeries{{
+				Name:       fmt.Sprintf("'%s'!$B$1", sheetCategory),
+				Categories: fmt.Sprintf("'%s'!$A$2:$A$%d", sheetCategory, n+1),
+				Values:     fmt.Sprintf("'%s'!$B$2:$B$%d", sheetCategory, n+1),
+			}},
+			Title:     []excelize.RichTextRun{{Text: "Pengeluaran per Kategori"}},
+			Legend:    excelize.ChartLegend{Position: "right"},
+			PlotArea:  excelize.ChartPlotArea{ShowPercent: true},
+			Dimension: excelize.ChartDimension{Width: 480, Height: 300},
+		}); err != nil {
+			return err
+		}
+	}
+	if trendRows > 0 {
+		if err := f.AddChart(s, "D19", &excelize.Chart{
+			Type: excelize.Col,
+			Series: []excelize.ChartSeries{{
+				Name:       fmt.Sprintf("'%s'!$C$1", sheetTrend),
+				Categories: fmt.Sprintf("'%s'!$A$2:$A$%d", sheetTrend, trendRows+1),
+				Values:     fmt.Sprintf("'%s'!$C$2:$C$%d", sheetTrend, trendRows+1),
+			}},
+			Title:     []excelize.RichTextRun{{Text: trendTitle(data.Granularity)}},
+			Legend:    excelize.ChartLegend{Position: "none"},
+			Dimension: excelize.ChartDimension{Width: 480, Height: 300},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHeader(f *excelize.File, st excelStyles, sheet string, titles []string) error {
+	return writeHeaderAt(f, st, sheet, 1, titles)
+}
+
+// writeHeaderAt menulis baris header; hanya sel header yang diberi style header
+func writeHeaderAt(f *excelize.File, st excelStyles, sheet string, row int, titles []string) error {
+	if err := f.SetSheetRow(sheet, cell("A", row), &titles); err != nil {
+		return err
+	}
+	lastCol, err := excelize.ColumnNumberToName(len(titles))
+	if err != nil {
+		return err
+	}
+	return f.SetCellStyle(sheet, cell("A", row), cell(lastCol, row), st.header)
+}
+
+// freezeAndFilter membekukan baris header dan memasang autofilter pada baris data
+// (baris total di bawahnya tidak ikut difilter)
+func freezeAndFilter(f *excelize.File, sheet, lastCol string, lastRow int) error {
+	if err := f.SetPanes(sheet, frozenHeader()); err != nil {
+		return err
+	}
+	if lastRow < 2 {
+		return nil
+	}
+	return f.AutoFilter(sheet, "A1:"+cell(lastCol, lastRow), nil)
+}
+
+// sumRange adalah rumus SUM untuk baris data 2..lastRow; "0" jika tidak ada data
+// (rumus SUM di baris total akan merujuk dirinya sendiri)
+func sumRange(col string, lastRow int) string {
+	if lastRow < 2 {
+		return "0"
+	}
+	return fmt.Sprintf("SUM(%s2:%s%d)", col, col, lastRow)
+}
+
+func frozenHeader() *excelize.Panes {
+	return &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}
+}
+
+// changeFormula adalah rumus persentase perubahan cur terhadap prev pada satu baris;
+// kosong jika prev 0
+func changeFormula(cur, prev string, row int) string {
+	return fmt.Sprintf(`IF(%s%d<>0,%s%d/%s%d-1,"")`, prev, row, cur, row, prev, row)
+}
+
+func cell(col string, row int) string {
+	return fmt.Sprintf("%s%d", col, row)
+}
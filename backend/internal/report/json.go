@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// JSONWriter menulis laporan sebagai satu objek JSON: field ReportData ditambah
+// array "items". Item di-encode satu per satu saat dibaca dari database.
+type JSONWriter struct{}
+
+// ContentType implements ReportWriter
+func (JSONWriter) ContentType() string { return "application/json; charset=utf-8" }
+
+// Extension implements ReportWriter
+func (JSONWriter) Extension() string { return ".json" }
+
+// WriteReport implements ReportWriter
+func (JSONWriter) WriteReport(w io.Writer, data *model.ReportData, items ItemSource) error {
+	head, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	// Buka kembali objek ReportData (tanpa '}' penutup) lalu sambung dengan array items
+	bw.Write(bytes.TrimSuffix(head, []byte("}")))
+	bw.WriteString(`,"items":[`)
+
+	first := true
+	err = items(func(item model.ReportItem) error {
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if !first {
+			bw.WriteByte(',')
+		}
+		first = false
+		_, err = bw.Write(encoded)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	bw.WriteString("]}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,195 @@
+package report
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+const odsMimetype = "application/vnd.oasis.opendocument.spreadsheet"
+
+const odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+
+const odsContentHead = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" office:version="1.2">
+<office:body><office:spreadsheet>
+`
+
+const odsContentTail = `</office:spreadsheet></office:body></office:document-content>
+`
+
+// ODSWriter menulis laporan sebagai OpenDocument Spreadsheet dengan sheet "Ringkasan"
+// dan "Item". Arsip zip dan content.xml ditulis bertahap, sehingga baris item
+// dialirkan langsung dari database ke response.
+type ODSWriter struct{}
+
+// ContentType implements ReportWriter
+func (ODSWriter) ContentType() string { return odsMimetype }
+
+// Extension implements ReportWriter
+func (ODSWriter) Extension() string { return ".ods" }
+
+// WriteReport implements ReportWriter
+func (ODSWriter) WriteReport(w io.Writer, data *model.ReportData, items ItemSource) error {
+	zw := zip.NewWriter(w)
+
+	// Spesifikasi ODF: "mimetype" harus entri pertama dan tidak dikompresi
+	mimetype, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE([]byte(odsMimetype)),
+		CompressedSize64:   uint64(len(odsMimetype)),
+		UncompressedSize64: uint64(len(odsMimetype)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, odsMimetype); err != nil {
+		return err
+	}
+
+	manifest, err := zw.Create("META-INF/manifest.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(manifest, odsManifest); err != nil {
+		return err
+	}
+
+	content, err := zw.Create("content.xml")
+	if err != nil {
+		return err
+	}
+	s := &odsSheet{w: bufio.NewWriter(content)}
+	s.raw(odsContentHead)
+
+	// Sheet 1: ringkasan, per kategori (dengan perbandingan jika ada), tren
+	s.raw(`<table:table table:name="Ringkasan">`)
+	s.row(odsString("Laporan Belanja"))
+	s.row(odsString("Nama"), odsString(data.UserName))
+	s.row(odsString("Periode"), odsString(helper.FormatPeriode(data.PeriodStart, data.PeriodEnd)))
+	s.row(odsString("Total Belanja"), odsFloat(data.TotalBelanja))
+	s.row(odsString("Total Budget"), odsFloat(data.TotalBudget))
+	s.row(odsString("Sisa Budget"), odsFloat(data.SisaBudget()))
+	if cmp := data.Comparison; cmp != nil {
+		s.row(odsString("Pembanding"), odsString(compareTitle(cmp.Mode)+" ("+helper.FormatPeriode(cmp.PeriodStart, cmp.PeriodEnd)+")"))
+		s.row(odsString("Total Belanja Pembanding"), odsFloat(cmp.TotalBelanja))
+		s.row(odsString("Selisih Belanja"), odsFloat(cmp.Selisih))
+		s.row(odsString("Perubahan Belanja"), odsPercent(cmp.PersenPerubahan))
+		s.row()
+		s.row(odsString("Kategori"), odsString("Total"), odsString("Pembanding"), odsString("Selisih"), odsString("Perubahan"))
+		for _, c := range cmp.Categories {
+			s.row(odsString(c.Kategori), odsFloat(c.Total), odsFloat(c.Pembanding), odsFloat(c.Selisih), odsPercent(c.PersenPerubahan))
+		}
+	} else {
+		s.row()
+		s.row(odsString("Kategori"), odsString("Total"))
+		for _, c := range data.Categories {
+			s.row(odsString(c.Kategori), odsFloat(c.Total))
+		}
+	}
+	s.row()
+	s.row(odsString(trendTitle(data.Granularity)), odsString("Mulai"), odsString("Total"))
+	for _, p := range data.Trend {
+		s.row(odsString(p.Label), odsDate(p.Mulai), odsFloat(p.Total))
+	}
+	s.raw(`</table:table>`)
+
+	// Sheet 2: daftar item, dialirkan baris per baris
+	s.raw(`<table:table table:name="Item">`)
+	s.row(odsString("Tanggal"), odsString("Nama Item"), odsString("Kategori"),
+		odsString("Jumlah"), odsString("Harga Satuan"), odsString("Total"))
+	err = items(func(item model.ReportItem) error {
+		s.row(
+			odsDate(item.Tanggal),
+			odsString(item.NamaItem),
+			odsString(item.Kategori),
+			odsFloat(float64(item.Jumlah)),
+			odsFloat(item.HargaSatuan),
+			odsFloat(item.Total),
+		)
+		return s.err
+	})
+	if err != nil {
+		return err
+	}
+	s.raw(`</table:table>`)
+
+	s.raw(odsContentTail)
+	if err := s.flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// odsSheet menulis elemen content.xml; error tulis pertama disimpan dan
+// menghentikan penulisan berikutnya
+type odsSheet struct {
+	w   *bufio.Writer
+	err error
+}
+
+type odsCell struct {
+	attrs string // atribut office:value-type dan nilainya
+	text  string // teks tampilan
+}
+
+func odsString(s string) odsCell {
+	return odsCell{attrs: `office:value-type="string"`, text: s}
+}
+
+func odsFloat(v float64) odsCell {
+	return odsCell{attrs: `office:value-type="float" office:value="` + formatFloat(v) + `"`, text: helper.FormatNumber(v)}
+}
+
+// odsPercent menulis persentase (misal 12,5) sebagai sel persentase; kosong jika nil
+func odsPercent(v *float64) odsCell {
+	if v == nil {
+		return odsString("")
+	}
+	return odsCell{attrs: `office:value-type="percentage" office:value="` + formatFloat(*v/100) + `"`, text: helper.FormatPersen(*v)}
+}
+
+func odsDate(t time.Time) odsCell {
+	return odsCell{attrs: `office:value-type="date" office:date-value="` + t.Format(calendar.DateLayout) + `"`, text: helper.FormatTanggal(t)}
+}
+
+func (s *odsSheet) raw(str string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(str)
+	}
+}
+
+func (s *odsSheet) row(cells ...odsCell) {
+	s.raw("<table:table-row>")
+	if len(cells) == 0 {
+		s.raw("<table:table-cell/>")
+	}
+	for _, c := range cells {
+		s.raw("<table:table-cell " + c.attrs + "><text:p>")
+		if s.err == nil {
+			s.err = xml.EscapeText(s.w, []byte(c.text))
+		}
+		s.raw("</text:p></table:table-cell>")
+	}
+	s.raw("</table:table-row>\n")
+}
+
+func (s *odsSheet) flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.w.Flush()
+}
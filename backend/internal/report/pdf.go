@@ -0,0 +1,435 @@
+package report
+
+import (
+	"io"
+
+	"github.com/gusti3111/TKBMG/backend/internal/chart"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/pdf"
+)
+
+// Tata letak halaman A4 dalam titik
+const (
+	marginX      = 40.0
+	marginTop    = 50.0
+	contentWidth = pdf.A4Width - 2*marginX
+	contentEnd   = pdf.A4Height - 60 // Batas bawah isi; di bawahnya footer
+	rowHeight    = 16.0
+
+	// Gambar grafik dirender 3x lebih rapat dari ukuran cetaknya agar tetap tajam
+	chartScale = 3
+
+	// Kategori di luar N terbesar digabung menjadi "Lainnya" pada pie chart
+	maxPieSlices = 8
+)
+
+var (
+	headerFill = pdf.Color{R: 52, G: 73, B: 94}
+	stripeFill = pdf.Color{R: 245, G: 247, B: 250}
+	overBudget = pdf.Color{R: 192, G: 57, B: 43}
+)
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignRight
+)
+
+type column struct {
+	title string
+	width float64
+	align align
+}
+
+// PDFWriter menulis laporan berformat PDF (A4) lengkap dengan grafik.
+// Dokumen disusun di memori karena nomor halaman baru diketahui di akhir.
+type PDFWriter struct{}
+
+// ContentType implements ReportWriter
+func (PDFWriter) ContentType() string { return "application/pdf" }
+
+// Extension implements ReportWriter
+func (PDFWriter) Extension() string { return ".pdf" }
+
+// pdfReport menyimpan posisi tulis (y) selama laporan disusun
+type pdfReport struct {
+	doc   *pdf.Document
+	data  *model.ReportData
+	items []model.ReportItem
+	y     float64
+}
+
+// WriteReport implements ReportWriter
+func (PDFWriter) WriteReport(w io.Writer, data *model.ReportData, items ItemSource) error {
+	list, err := collectItems(items)
+	if err != nil {
+		return err
+	}
+	r := &pdfReport{doc: pdf.New(), data: data, items: list}
+	r.newPage()
+
+	r.header()
+	r.summary()
+	if err := r.charts(); err != nil {
+		return err
+	}
+	r.categoryTable()
+	r.comparisonTable()
+	r.trendTable()
+	r.itemTable()
+	r.footers()
+
+	_, err = r.doc.WriteTo(w)
+	return err
+}
+
+func (r *pdfReport) newPage() {
+	r.doc.AddPage()
+	r.y = marginTop
+}
+
+// ensureSpace pindah ke halaman baru jika sisa ruang kurang dari h
+func (r *pdfReport) ensureSpace(h float64) bool {
+	if r.y+h <= contentEnd {
+		return false
+	}
+	r.newPage()
+	return true
+}
+
+func (r *pdfReport) header() {
+	r.doc.SetFont(pdf.HelveticaBold, 18)
+	r.doc.Text(marginX, r.y, "Laporan Belanja", pdf.Black)
+	r.y += 22
+
+	r.doc.SetFont(pdf.Helvetica, 10)
+	lines := [][2]string{
+		{"Nama", r.data.UserName},
+		{"Periode", helper.FormatPeriode(r.data.PeriodStart, r.data.PeriodEnd)},
+		{"Dibuat", helper.FormatTanggal(r.data.GeneratedAt) + ", " + r.data.GeneratedAt.Format("15.04")},
+	}
+	for _, l := range lines {
+		r.doc.Text(marginX, r.y, l[0], pdf.Gray)
+		r.doc.Text(marginX+60, r.y, ": "+l[1], pdf.Black)
+		r.y += 14
+	}
+	r.y += 2
+	r.doc.Line(marginX, r.y, marginX+contentWidth, r.y, 1, headerFill)
+	r.y += 22
+}
+
+func (r *pdfReport) sectionTitle(title string) {
+	r.ensureSpace(40)
+	r.doc.SetFont(pdf.HelveticaBold, 12)
+	r.doc.Text(marginX, r.y, title, pdf.Black)
+	r.y += 12
+}
+
+func (r *pdfReport) summary() {
+	r.sectionTitle("Ringkasan")
+
+	sisa := r.data.SisaBudget()
+	sisaColor := pdf.Black
+	if sisa < 0 {
+		sisaColor = overBudget
+	}
+	boxes := []struct {
+		label string
+		value string
+		color pdf.Color
+	}{
+		{"Total Belanja", helper.FormatRupiah(r.data.TotalBelanja), pdf.Black},
+		{"Total Budget", helper.FormatRupiah(r.data.TotalBudget), pdf.Black},
+		{"Sisa Budget", helper.FormatRupiah(sisa), sisaColor},
+		{"Budget Terpakai", helper.FormatPersen(r.data.PersenTerpakai()), sisaColor},
+	}
+	if r.data.TotalBudget <= 0 {
+		boxes[3].value = "-"
+	}
+
+	const gap, height = 10.0, 42.0
+	width := (contentWidth - gap*float64(len(boxes)-1)) / float64(len(boxes))
+	for i, b := range boxes {
+		x := marginX + float64(i)*(width+gap)
+		r.doc.FillRect(x, r.y, width, height, stripeFill)
+		r.doc.SetFont(pdf.Helvetica, 9)
+		r.doc.Text(x+8, r.y+15, b.label, pdf.Gray)
+		r.doc.SetFont(pdf.HelveticaBold, 12)
+		r.doc.Text(x+8, r.y+33, r.doc.Truncate(b.value, width-16), b.color)
+	}
+	r.y += height + 26
+}
+
+func (r *pdfReport) charts() error {
+	// Pie chart per kategori dengan legenda di sebelah kanan
+	r.sectionTitle("Pengeluaran per Kategori")
+	const pieSize = 150.0
+	r.ensureSpace(pieSize + 10)
+
+	names, values := pieSlices(r.data.Categories)
+	if len(values) == 0 {
+		r.doc.SetFont(pdf.Helvetica, 10)
+		r.doc.Text(marginX, r.y+14, "Belum ada belanja pada periode ini.", pdf.Gray)
+		r.y += 34
+	} else {
+		if err := r.doc.Image(chart.Pie(values, int(pieSize)*chartScale), marginX, r.y, pieSize, pieSize); err != nil {
+			return err
+		}
+		r.doc.SetFont(pdf.Helvetica, 9)
+		legendX := marginX + pieSize + 30
+		ly := r.y + 8
+		for i, name := range names {
+			c := chart.ColorAt(i)
+			r.doc.FillRect(legendX, ly, 9, 9, pdf.Color{R: c.R, G: c.G, B: c.B})
+			label := r.doc.Truncate(name, 170)
+			r.doc.Text(legendX+15, ly+8, label, pdf.Black)
+			r.doc.TextRight(marginX+contentWidth-70, ly+8, helper.FormatRupiah(values[i]), pdf.Black)
+			r.doc.TextRight(marginX+contentWidth, ly+8, helper.FormatPersen(values[i]/r.data.TotalBelanja*100), pdf.Gray)
+			ly += 16
+		}
+		r.y += pieSize + 24
+	}
+
+	// Bar chart tren (periode terlama di kiri)
+	r.sectionTitle(trendTitle(r.data.Granularity))
+	const axisWidth, barHeight = 70.0, 140.0
+	r.ensureSpace(barHeight + 30)
+
+	trend := r.data.Trend
+	values = make([]float64, len(trend))
+	maxValue := 0.0
+	for i, w := range trend {
+		values[i] = w.Total
+		if w.Total > maxValue {
+			maxValue = w.Total
+		}
+	}
+	maxValue = chart.NiceMax(maxValue)
+
+	barWidth := contentWidth - axisWidth
+	img := chart.Bar(values, maxValue, int(barWidth)*chartScale, int(barHeight)*chartScale)
+	if err := r.doc.Image(img, marginX+axisWidth, r.y, barWidth, barHeight); err != nil {
+		return err
+	}
+
+	r.doc.SetFont(pdf.Helvetica, 8)
+	for i := 0; i <= 4; i++ {
+		yy := r.y + barHeight - barHeight*float64(i)/4
+		r.doc.TextRight(marginX+axisWidth-6, yy+3, helper.FormatNumber(maxValue*float64(i)/4), pdf.Gray)
+	}
+	if len(trend) > 0 {
+		slot := barWidth / float64(len(trend))
+		labelWidth := 0.0
+		for _, p := range trend {
+			labelWidth = max(labelWidth, r.doc.TextWidth(p.Label))
+		}
+		// Label dijarangkan agar tidak bertumpuk jika periodenya banyak
+		step := 1
+		for slot*float64(step) < labelWidth+6 {
+			step++
+		}
+		for i := 0; i < len(trend); i += step {
+			cx := marginX + axisWidth + slot*(float64(i)+0.5)
+			label := trend[i].Label
+			r.doc.Text(cx-r.doc.TextWidth(label)/2, r.y+barHeight+11, label, pdf.Gray)
+		}
+	}
+	r.y += barHeight + 34
+	return nil
+}
+
+func (r *pdfReport) categoryTable() {
+	r.sectionTitle("Rincian per Kategori")
+	cols := []column{
+		{"Kategori", 295, alignLeft},
+		{"Total", 140, alignRight},
+		{"Persentase", contentWidth - 435, alignRight},
+	}
+	r.tableHeader(cols)
+	for i, c := range r.data.Categories {
+		persen := 0.0
+		if r.data.TotalBelanja > 0 {
+			persen = c.Total / r.data.TotalBelanja * 100
+		}
+		r.tableRow(cols, i, []string{c.Kategori, helper.FormatRupiah(c.Total), helper.FormatPersen(persen)}, false)
+	}
+	r.tableRow(cols, len(r.data.Categories), []string{"Total", helper.FormatRupiah(r.data.TotalBelanja), ""}, true)
+	r.y += 24
+}
+
+func (r *pdfReport) trendTable() {
+	r.sectionTitle("Rincian " + trendTitle(r.data.Granularity))
+	cols := []column{
+		{"Periode", 110, alignLeft},
+		{"Mulai", 150, alignLeft},
+		{"Total", 130, alignRight},
+		{"Perubahan", contentWidth - 390, alignRight},
+	}
+	r.tableHeader(cols)
+	trend := r.data.Trend
+	for i, p := range trend {
+		change := "-"
+		if i > 0 {
+			change = formatChange(model.PercentChange(p.Total, trend[i-1].Total))
+		}
+		r.tableRow(cols, i, []string{p.Label, helper.FormatTanggal(p.Mulai), helper.FormatRupiah(p.Total), change}, false)
+	}
+	r.y += 24
+}
+
+// comparisonTable menampilkan selisih dan persentase perubahan terhadap periode pembanding
+func (r *pdfReport) comparisonTable() {
+	cmp := r.data.Comparison
+	if cmp == nil {
+		return
+	}
+	r.sectionTitle("Perbandingan dengan " + compareTitle(cmp.Mode))
+	r.doc.SetFont(pdf.Helvetica, 9)
+	r.doc.Text(marginX, r.y+2, "Periode pembanding: "+helper.FormatPeriode(cmp.PeriodStart, cmp.PeriodEnd), pdf.Gray)
+	r.y += 10
+
+	cols := []column{
+		{"Kategori", 155, alignLeft},
+		{"Periode Ini", 95, alignRight},
+		{"Pembanding", 95, alignRight},
+		{"Selisih", 95, alignRight},
+		{"Perubahan", contentWidth - 440, alignRight},
+	}
+	r.tableHeader(cols)
+	for i, c := range cmp.Categories {
+		r.tableRow(cols, i, []string{
+			c.Kategori,
+			helper.FormatRupiah(c.Total),
+			helper.FormatRupiah(c.Pembanding),
+			formatDelta(c.Selisih),
+			formatChange(c.PersenPerubahan),
+		}, false)
+	}
+	r.tableRow(cols, len(cmp.Categories), []string{
+		"Total",
+		helper.FormatRupiah(r.data.TotalBelanja),
+		helper.FormatRupiah(cmp.TotalBelanja),
+		formatDelta(cmp.Selisih),
+		formatChange(cmp.PersenPerubahan),
+	}, true)
+	r.y += 24
+}
+
+func (r *pdfReport) itemTable() {
+	// Daftar item selalu dimulai di halaman baru dan boleh berlanjut ke banyak halaman
+	r.newPage()
+	r.sectionTitle("Daftar Item")
+	cols := []column{
+		{"Tanggal", 90, alignLeft},
+		{"Nama Item", 135, alignLeft},
+		{"Kategori", 95, alignLeft},
+		{"Jumlah", 40, alignRight},
+		{"Harga Satuan", 75, alignRight},
+		{"Total", contentWidth - 435, alignRight},
+	}
+	r.tableHeader(cols)
+	if len(r.items) == 0 {
+		r.doc.SetFont(pdf.Helvetica, 9)
+		r.doc.Text(marginX+6, r.y+11, "Tidak ada item yang dibeli pada periode ini.", pdf.Gray)
+		r.y += rowHeight
+		return
+	}
+	for i, it := range r.items {
+		r.tableRow(cols, i, []string{
+			helper.FormatTanggal(it.Tanggal),
+			it.NamaItem,
+			it.Kategori,
+			helper.FormatNumber(float64(it.Jumlah)),
+			helper.FormatNumber(it.HargaSatuan),
+			helper.FormatNumber(it.Total),
+		}, false)
+	}
+	r.tableRow(cols, len(r.items), []string{"Total", "", "", "", "", helper.FormatNumber(r.data.TotalBelanja)}, true)
+}
+
+func (r *pdfReport) tableHeader(cols []column) {
+	r.ensureSpace(rowHeight * 2)
+	r.doc.FillRect(marginX, r.y, contentWidth, rowHeight, headerFill)
+	r.doc.SetFont(pdf.HelveticaBold, 9)
+	r.writeCells(cols, headerTitles(cols), pdf.White)
+	r.y += rowHeight
+}
+
+// tableRow menulis satu baris; jika halaman penuh, header tabel diulang di halaman berikutnya
+func (r *pdfReport) tableRow(cols []column, index int, cells []string, bold bool) {
+	if r.ensureSpace(rowHeight) {
+		r.tableHeader(cols)
+	}
+	if bold {
+		r.doc.Line(marginX, r.y, marginX+contentWidth, r.y, 0.8, headerFill)
+		r.doc.SetFont(pdf.HelveticaBold, 9)
+	} else {
+		if index%2 == 1 {
+			r.doc.FillRect(marginX, r.y, contentWidth, rowHeight, stripeFill)
+		}
+		r.doc.SetFont(pdf.Helvetica, 9)
+	}
+	r.writeCells(cols, cells, pdf.Black)
+	r.y += rowHeight
+}
+
+func (r *pdfReport) writeCells(cols []column, cells []string, color pdf.Color) {
+	const padding = 5.0
+	x := marginX
+	baseline := r.y + rowHeight - 4.5
+	for i, col := range cols {
+		text := r.doc.Truncate(cells[i], col.width-2*padding)
+		if col.align == alignRight {
+			r.doc.TextRight(x+col.width-padding, baseline, text, color)
+		} else {
+			r.doc.Text(x+padding, baseline, text, color)
+		}
+		x += col.width
+	}
+}
+
+// footers menulis nomor halaman setelah jumlah halaman diketahui
+func (r *pdfReport) footers() {
+	total := r.doc.PageCount()
+	for i := 0; i < total; i++ {
+		r.doc.SetPage(i)
+		y := pdf.A4Height - 30
+		r.doc.Line(marginX, y-12, marginX+contentWidth, y-12, 0.5, pdf.LightGray)
+		r.doc.SetFont(pdf.Helvetica, 8)
+		r.doc.Text(marginX, y, "Laporan Belanja - "+r.data.UserName, pdf.Gray)
+		r.doc.TextRight(marginX+contentWidth, y,
+			"Halaman "+helper.FormatNumber(float64(i+1))+" dari "+helper.FormatNumber(float64(total)), pdf.Gray)
+	}
+}
+
+func headerTitles(cols []column) []string {
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.title
+	}
+	return titles
+}
+
+// pieSlices mengambil kategori terbesar untuk pie chart dan menggabungkan sisanya
+// menjadi "Lainnya". Data kategori sudah terurut dari total terbesar.
+func pieSlices(categories []model.SpendingByCategory) ([]string, []float64) {
+	var names []string
+	var values []float64
+	for i, c := range categories {
+		if c.Total <= 0 {
+			continue
+		}
+		if i == maxPieSlices-1 && len(categories) > maxPieSlices {
+			rest := 0.0
+			for _, other := range categories[i:] {
+				rest += other.Total
+			}
+			return append(names, "Lainnya"), append(values, rest)
+		}
+		names = append(names, c.Kategori)
+		values = append(values, c.Total)
+	}
+	return names, values
+}
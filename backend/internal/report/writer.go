@@ -0,0 +1,111 @@
+// Package report merender model.ReportData ke berbagai format berkas unduhan.
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// ItemSource mengiterasi item laporan dan memanggil fn untuk setiap item secara berurutan.
+// Biasanya dibaca langsung dari cursor database (lihat ReportRepository.StreamPurchasedItems),
+// sehingga writer yang mengalirkan output tidak perlu menampung semua item di memori.
+type ItemSource func(fn func(model.ReportItem) error) error
+
+// ReportWriter menulis satu laporan dalam format berkas tertentu
+type ReportWriter interface {
+	// ContentType adalah MIME type untuk header Content-Type
+	ContentType() string
+	// Extension adalah ekstensi nama berkas, misal ".csv"
+	Extension() string
+	// WriteReport menulis laporan ke w. Writer yang mendukung streaming menulis item
+	// satu per satu saat dibaca dari items.
+	WriteReport(w io.Writer, data *model.ReportData, items ItemSource) error
+}
+
+// Options adalah pilihan tambahan yang berlaku untuk sebagian format
+type Options struct {
+	// CSVBOM menambahkan UTF-8 BOM di awal CSV agar Microsoft Excel membaca UTF-8 dengan benar
+	CSVBOM bool
+}
+
+// Tipe laporan yang didukung (nilai query param 'type')
+const (
+	TypeExcel = "excel"
+	TypePDF   = "pdf"
+	TypeCSV   = "csv"
+	TypeJSON  = "json"
+	TypeODS   = "ods"
+)
+
+// NewWriter mengembalikan ReportWriter untuk tipe laporan yang diminta
+func NewWriter(reportType string, opts Options) (ReportWriter, error) {
+	switch reportType {
+	case TypeExcel:
+		return ExcelWriter{}, nil
+	case TypePDF:
+		return PDFWriter{}, nil
+	case TypeCSV:
+		return CSVWriter{BOM: opts.CSVBOM}, nil
+	case TypeJSON:
+		return JSONWriter{}, nil
+	case TypeODS:
+		return ODSWriter{}, nil
+	default:
+		return nil, fmt.Errorf("report: unsupported report type %q", reportType)
+	}
+}
+
+// collectItems membaca seluruh item ke slice, untuk format yang harus disusun utuh di memori
+func collectItems(items ItemSource) ([]model.ReportItem, error) {
+	var list []model.ReportItem
+	err := items(func(item model.ReportItem) error {
+		list = append(list, item)
+		return nil
+	})
+	return list, err
+}
+
+// trendTitle adalah judul tabel/grafik tren sesuai granularitas
+func trendTitle(granularity string) string {
+	switch granularity {
+	case model.GranularityDay:
+		return "Tren Harian"
+	case model.GranularityMonth:
+		return "Tren Bulanan"
+	default:
+		return "Tren Mingguan"
+	}
+}
+
+// compareTitle adalah nama periode pembanding untuk mode perbandingan
+func compareTitle(mode string) string {
+	if mode == model.CompareLastYear {
+		return "Periode yang Sama Tahun Lalu"
+	}
+	return "Periode Sebelumnya"
+}
+
+// formatChange memformat persentase perubahan bertanda, misal "+12,5%"; "-" jika tidak terdefinisi
+func formatChange(change *float64) string {
+	if change == nil {
+		return "-"
+	}
+	if *change > 0 {
+		return "+" + helper.FormatPersen(*change)
+	}
+	return helper.FormatPersen(*change)
+}
+
+// formatDelta memformat selisih rupiah bertanda, misal "+Rp 25.000"
+func formatDelta(delta float64) string {
+	if delta > 0 {
+		return "+" + helper.FormatRupiah(delta)
+	}
+	if delta < 0 {
+		return "-" + helper.FormatRupiah(-delta)
+	}
+	return helper.FormatRupiah(0)
+}
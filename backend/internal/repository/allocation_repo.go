@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// SetAllocations mengganti seluruh alokasi kategori untuk satu budget secara atomik.
+// Pada mode 'sum', jumlah_anggaran diperbarui menjadi total alokasi.
+// Pada mode 'cap', total alokasi tidak boleh melebihi jumlah_anggaran (carry-over tidak ikut dihitung).
+func (r *BudgetRepository) SetAllocations(ctx context.Context, budgetID int, userID int, mode string, allocations []model.BudgetAllocation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var amount float64
+	err = tx.QueryRowContext(ctx,
+		`SELECT jumlah_anggaran FROM anggaran WHERE id_anggaran = $1 AND id_household = household_writable($2) AND deleted_at IS NULL FOR UPDATE`,
+		budgetID, userID).Scan(&amount)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error locking budget %d: %v", budgetID, err)
+		return fmt.Errorf("failed to fetch budget: %w", err)
+	}
+
+	var sum float64
+	ids := make([]int, 0, len(allocations))
+	for _, a := range allocations {
+		sum += a.Amount
+		ids = append(ids, a.CategoryID)
+	}
+	if len(ids) > 0 {
+		if err := lockOwnedCategories(ctx, tx, userID, ids); err != nil {
+			return err
+		}
+	}
+	if mode == model.AllocationModeCap && sum > amount {
+		return ErrAllocationExceedsBudget
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM anggaran_kategori WHERE id_anggaran = $1`, budgetID); err != nil {
+		log.Printf("Error clearing allocations: %v", err)
+		return fmt.Errorf("failed to update allocations: %w", err)
+	}
+
+	insert := `INSERT INTO anggaran_kategori (id_anggaran, id_kategori, jumlah, draw_from_pool) VALUES ($1, $2, $3, $4)`
+	for _, a := range allocations {
+		if _, err := tx.ExecContext(ctx, insert, budgetID, a.CategoryID, a.Amount, a.DrawFromPool); err != nil {
+			log.Printf("Error inserting allocation: %v", err)
+			return fmt.Errorf("failed to save allocation: %w", err)
+		}
+	}
+
+	update := `UPDATE anggaran SET allocation_mode = $1 WHERE id_anggaran = $2`
+	args := []any{mode, budgetID}
+	if mode == model.AllocationModeSum {
+		update = `UPDATE anggaran SET allocation_mode = $1, jumlah_anggaran = $3 WHERE id_anggaran = $2`
+		args = append(args, sum)
+	}
+	if _, err := tx.ExecContext(ctx, update, args...); err != nil {
+		log.Printf("Error updating allocation mode: %v", err)
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetEnvelopeSummary menghitung alokasi, belanja, dan sisa per kategori untuk satu budget.
+// Belanja pada sub-kategori masuk ke amplop leluhur terdekat yang memiliki alokasi;
+// belanja yang tidak memiliki amplop masuk ke pool "belum dialokasikan".
+func (r *BudgetRepository) GetEnvelopeSummary(ctx context.Context, budgetID int, userID int) (*model.EnvelopeSummary, error) {
+	// Carry-over dihitung saat dibaca (rantai budget), bukan disimpan
+	status, err := r.GetBudgetStatusByID(ctx, budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mode string
+	err = r.db.QueryRowContext(ctx,
+		`SELECT allocation_mode FROM anggaran WHERE id_anggaran = $1 AND id_household = household_of($2) AND deleted_at IS NULL`,
+		budgetID, userID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error fetching budget %d: %v", budgetID, err)
+		return nil, fmt.Errorf("failed to fetch budget: %w", err)
+	}
+
+	query := `
+		WITH RECURSIVE alloc AS (
+			SELECT id_kategori, jumlah, draw_from_pool FROM anggaran_kategori WHERE id_anggaran = $1
+		),
+		chain AS (
+			-- Setiap kategori dipasangkan dengan dirinya sendiri dan semua leluhurnya
+			SELECT rk.id_kategori AS leaf, rk.id_kategori AS node, rk.id_parent, 0 AS depth
+			FROM referensi_kategori rk WHERE rk.id_household = household_of($2)
+			UNION ALL
+			SELECT c.leaf, p.id_kategori, p.id_parent, c.depth + 1
+			FROM chain c JOIN referensi_kategori p ON p.id_kategori = c.id_parent
+			WHERE c.depth < 100
+		),
+		owner AS (
+			-- Amplop terdekat untuk setiap kategori
+			SELECT DISTINCT ON (c.leaf) c.leaf, c.node AS id_alokasi_kategori
+			FROM chain c JOIN alloc a ON a.id_kategori = c.node
+			ORDER BY c.leaf, c.depth
+		),
+		spend AS (
+			SELECT o.id_alokasi_kategori, SUM(i.total_harga) AS total
+			FROM items i
+			JOIN anggaran b ON b.id_anggaran = $1
+			LEFT JOIN owner o ON o.leaf = i.id_kategori
+			WHERE i.id_household = b.id_household AND i.status = 'purchased' AND i.deleted_at IS NULL
+			  AND i.purchased_date BETWEEN b.start_date AND b.end_date
+			GROUP BY o.id_alokasi_kategori
+		)
+		SELECT a.id_kategori, rk.nama_kategori, a.jumlah, a.draw_from_pool, COALESCE(s.total, 0)
+		FROM alloc a
+		JOIN referensi_kategori rk ON rk.id_kategori = a.id_kategori
+		LEFT JOIN spend s ON s.id_alokasi_kategori = a.id_kategori
+		UNION ALL
+		SELECT NULL, '', 0, false, COALESCE((SELECT total FROM spend WHERE id_alokasi_kategori IS NULL), 0)
+		ORDER BY 2`
+
+	rows, err := r.db.QueryContext(ctx, query, budgetID, userID)
+	if err != nil {
+		log.Printf("Error querying envelopes for budget %d: %v", budgetID, err)
+		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+	defer rows.Close()
+
+	var spending []model.AllocationSpending
+	for rows.Next() {
+		var (
+			s          model.AllocationSpending
+			categoryID sql.NullInt64
+		)
+		if err := rows.Scan(&categoryID, &s.CategoryName, &s.Amount, &s.DrawFromPool, &s.Spent); err != nil {
+			log.Printf("Error scanning allocation row: %v", err)
+			continue
+		}
+		if categoryID.Valid {
+			id := int(categoryID.Int64)
+			s.CategoryID = &id
+		}
+		spending = append(spending, s)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+
+	summary := model.BuildEnvelopes(budgetID, mode, status.Amount, status.CarryOver, spending)
+	return &summary, nil
+}
+
+// reconcileBudgetAmount menyesuaikan jumlah baru budget dengan alokasinya, di dalam transaksi
+// yang sama dengan UPDATE-nya. Baris budget dikunci agar tidak berubah bersamaan dengan SetAllocations.
+// Pada mode 'sum' jumlah dihitung ulang dari total alokasi; pada mode 'cap' jumlah yang lebih kecil
+// dari total alokasi ditolak dengan ErrAllocationExceedsBudget.
+func reconcileBudgetAmount(ctx context.Context, tx *sql.Tx, budgetID int, userID int, amount float64) (float64, error) {
+	var (
+		mode      string
+		allocated float64
+	)
+	err := tx.QueryRowContext(ctx,
+		`SELECT a.allocation_mode,
+		        COALESCE((SELECT SUM(k.jumlah) FROM anggaran_kategori k WHERE k.id_anggaran = a.id_anggaran), 0)
+		 FROM anggaran a
+		 WHERE a.id_anggaran = $1 AND a.id_household = household_writable($2) AND a.deleted_at IS NULL
+		 FOR UPDATE`,
+		budgetID, userID).Scan(&mode, &allocated)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error locking budget %d: %v", budgetID, err)
+		return 0, fmt.Errorf("failed to fetch budget: %w", err)
+	}
+
+	if mode == model.AllocationModeSum {
+		return allocated, nil
+	}
+	if amount < allocated {
+		return 0, ErrAllocationExceedsBudget
+	}
+	return amount, nil
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// AnalyticsRepository menghitung analitik pola belanja (item teratas, keranjang,
+// item yang dibeli bersamaan, item rutin) langsung di database dengan window function
+type AnalyticsRepository struct {
+	db *sql.DB
+}
+
+// NewAnalyticsRepository membuat instance AnalyticsRepository baru
+func NewAnalyticsRepository() *AnalyticsRepository {
+	return &AnalyticsRepository{db: db.DB}
+}
+
+// purchasedCTE adalah item 'purchased' di household aktif user ($1) dalam rentang $2..$3 dengan nama
+// ternormalisasi (kunci), tanggal trip (hari), serta ejaan nama dan kategori terakhir per kunci
+const purchasedCTE = `
+	purchased AS (
+		SELECT
+			LOWER(TRIM(i.nama_item)) AS kunci,
+			FIRST_VALUE(i.nama_item) OVER w AS nama,
+			FIRST_VALUE(COALESCE(rk.nama_kategori, 'Tanpa Kategori')) OVER w AS kategori,
+			i.purchased_date,
+			i.purchased_date::date AS hari,
+			i.jumlah_item,
+			i.total_harga
+		FROM items i
+		LEFT JOIN referensi_kategori rk ON rk.id_kategori = i.id_kategori
+		WHERE i.id_household = household_of($1) AND i.status = 'purchased' AND i.deleted_at IS NULL
+		  AND i.purchased_date BETWEEN $2 AND $3
+		WINDOW w AS (PARTITION BY LOWER(TRIM(i.nama_item)) ORDER BY i.purchased_date DESC, i.id_item DESC)
+	)`
+
+// GetTopItems mengembalikan 'limit' item teratas menurut frekuensi (jumlah trip) atau
+// total belanja. Item dengan nilai sama mendapat peringkat yang sama.
+func (r *AnalyticsRepository) GetTopItems(ctx context.Context, userID int, startDate time.Time, endDate time.Time, orderBy string, limit int) ([]model.ItemStat, error) {
+	rankOrder := "trips DESC"
+	if orderBy == model.TopItemsBySpend {
+		rankOrder = "total DESC"
+	}
+
+	query := `
+		WITH ` + purchasedCTE + `,
+		stats AS (
+			SELECT kunci, nama, kategori,
+			       COUNT(DISTINCT hari) AS trips,
+			       SUM(jumlah_item) AS qty,
+			       SUM(total_harga) AS total,
+			       MAX(purchased_date) AS terakhir
+			FROM purchased
+			GROUP BY kunci, nama, kategori
+		)
+		SELECT
+			RANK() OVER (ORDER BY ` + rankOrder + `) AS peringkat,
+			nama, kategori, trips, qty, total,
+			COALESCE(total / NULLIF(qty, 0), 0) AS harga_rata_rata,
+			COALESCE(total * 100 / NULLIF(SUM(total) OVER (), 0), 0) AS persen,
+			terakhir
+		FROM stats
+		ORDER BY peringkat ASC, total DESC, nama ASC
+		LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate, limit)
+	if err != nil {
+		log.Printf("Error querying top items: %v", err)
+		return nil, fmt.Errorf("failed to get top items: %w", err)
+	}
+	defer rows.Close()
+
+	results := []model.ItemStat{}
+	for rows.Next() {
+		var s model.ItemStat
+		if err := rows.Scan(&s.Peringkat, &s.NamaItem, &s.Kategori, &s.JumlahTrip, &s.TotalKuantitas,
+			&s.TotalBelanja, &s.HargaRataRata, &s.PersenBelanja, &s.TerakhirDibeli); err != nil {
+			log.Printf("Error scanning top item: %v", err)
+			continue
+		}
+		results = append(results, s)
+	}
+	return results, rows.Err()
+}
+
+// GetBasketSummary menghitung ukuran keranjang setiap trip beserta rata-ratanya.
+// Rata-rata bergerak 4 trip dihitung di database; median dan rata-rata keseluruhan
+// diturunkan dari daftar trip.
+func (r *AnalyticsRepository) GetBasketSummary(ctx context.Context, userID int, startDate time.Time, endDate time.Time) (*model.BasketSummary, error) {
+	query := `
+		WITH ` + purchasedCTE + `,
+		trips AS (
+			SELECT hari, COUNT(DISTINCT kunci) AS jenis, SUM(jumlah_item) AS qty, SUM(total_harga) AS total
+			FROM purchased
+			GROUP BY hari
+		)
+		SELECT hari, jenis, qty, total,
+		       AVG(total) OVER (ORDER BY hari ROWS BETWEEN 3 PRECEDING AND CURRENT ROW) AS rata_rata_4
+		FROM trips
+		ORDER BY hari DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		log.Printf("Error querying basket summary: %v", err)
+		return nil, fmt.Errorf("failed to get basket summary: %w", err)
+	}
+	defer rows.Close()
+
+	summary := &model.BasketSummary{Trips: []model.BasketTrip{}}
+	for rows.Next() {
+		var t model.BasketTrip
+		if err := rows.Scan(&t.Tanggal, &t.JumlahItem, &t.TotalKuantitas, &t.Total, &t.RataRata4Trip); err != nil {
+			log.Printf("Error scanning basket trip: %v", err)
+			continue
+		}
+		summary.Trips = append(summary.Trips, t)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+
+	n := len(summary.Trips)
+	if n == 0 {
+		return summary, nil
+	}
+	totals := make([]float64, 0, n)
+	var items, qty, spend float64
+	for _, t := range summary.Trips {
+		items += float64(t.JumlahItem)
+		qty += float64(t.TotalKuantitas)
+		spend += t.Total
+		totals = append(totals, t.Total)
+	}
+	sort.Float64s(totals)
+
+	summary.JumlahTrip = n
+	summary.RataRataItem = items / float64(n)
+	summary.RataRataKuantitas = qty / float64(n)
+	summary.RataRataBelanja = spend / float64(n)
+	summary.TripTerbesar = totals[n-1]
+	if n%2 == 1 {
+		summary.MedianBelanja = totals[n/2]
+	} else {
+		summary.MedianBelanja = (totals[n/2-1] + totals[n/2]) / 2
+	}
+	return summary, nil
+}
+
+// GetItemPairs mengembalikan pasangan item yang paling sering dibeli pada trip yang sama,
+// minimal 'minCount' trip, beserta support, confidence dan lift-nya
+func (r *AnalyticsRepository) GetItemPairs(ctx context.Context, userID int, startDate time.Time, endDate time.Time, minCount int, limit int) ([]model.ItemPair, error) {
+	query := `
+		WITH ` + purchasedCTE + `,
+		basket AS (
+			SELECT hari, kunci, nama, COUNT(*) OVER (PARTITION BY kunci) AS trip_item
+			FROM (SELECT DISTINCT hari, kunci, nama FROM purchased) d
+		),
+		pairs AS (
+			SELECT a.nama AS nama_a, b.nama AS nama_b, a.trip_item AS trip_a, b.trip_item AS trip_b,
+			       COUNT(*) AS bersama
+			FROM basket a
+			JOIN basket b ON b.hari = a.hari AND b.kunci > a.kunci
+			GROUP BY a.kunci, b.kunci, a.nama, b.nama, a.trip_item, b.trip_item
+			HAVING COUNT(*) >= $4
+		)
+		SELECT p.nama_a, p.nama_b, p.bersama, p.trip_a, p.trip_b,
+		       (SELECT COUNT(DISTINCT hari) FROM basket) AS total_trip
+		FROM pairs p
+		ORDER BY p.bersama DESC, p.bersama::float / (p.trip_a * p.trip_b) DESC, p.nama_a ASC, p.nama_b ASC
+		LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate, minCount, limit)
+	if err != nil {
+		log.Printf("Error querying item pairs: %v", err)
+		return nil, fmt.Errorf("failed to get item pairs: %w", err)
+	}
+	defer rows.Close()
+
+	results := []model.ItemPair{}
+	for rows.Next() {
+		var p model.ItemPair
+		var tripA, tripB, totalTrips int
+		if err := rows.Scan(&p.ItemA, &p.ItemB, &p.JumlahBersama, &tripA, &tripB, &totalTrips); err != nil {
+			log.Printf("Error scanning item pair: %v", err)
+			continue
+		}
+		together := float64(p.JumlahBersama)
+		p.Support = together / float64(totalTrips)
+		p.ConfidenceAB = together / float64(tripA)
+		p.ConfidenceBA = together / float64(tripB)
+		p.Lift = together * float64(totalTrips) / (float64(tripA) * float64(tripB))
+		results = append(results, p)
+	}
+	return results, rows.Err()
+}
+
+// GetStaples mengembalikan item rutin, yaitu item yang dibeli pada minimal 'minTrips' trip
+// berbeda, beserta jumlah hari sejak terakhir dibeli (dihitung terhadap 'today') dan
+// rata-rata jarak antar pembelian. Item yang paling lewat dari jaraknya muncul lebih dulu.
+func (r *AnalyticsRepository) GetStaples(ctx context.Context, userID int, startDate time.Time, endDate time.Time, minTrips int, today time.Time) ([]model.StapleItem, error) {
+	query := `
+		WITH ` + purchasedCTE + `,
+		days AS (
+			SELECT DISTINCT kunci, nama, kategori, hari FROM purchased
+		),
+		gaps AS (
+			SELECT kunci, nama, kategori, hari,
+			       hari - LAG(hari) OVER (PARTITION BY kunci ORDER BY hari) AS jarak
+			FROM days
+		)
+		SELECT nama, kategori, COUNT(*) AS trips, MAX(hari) AS terakhir,
+		       $5::date - MAX(hari) AS hari_sejak,
+		       AVG(jarak)::float AS rata_rata_jarak
+		FROM gaps
+		GROUP BY kunci, nama, kategori
+		HAVING COUNT(*) >= $4
+		ORDER BY ($5::date - MAX(hari)) / AVG(jarak) DESC, nama ASC`
+
+	// 'today' dikirim sebagai tanggal agar tidak bergeser oleh zona waktu sesi database
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate, minTrips, today.Format(calendar.DateLayout))
+	if err != nil {
+		log.Printf("Error querying staple items: %v", err)
+		return nil, fmt.Errorf("failed to get staple items: %w", err)
+	}
+	defer rows.Close()
+
+	results := []model.StapleItem{}
+	for rows.Next() {
+		var s model.StapleItem
+		if err := rows.Scan(&s.NamaItem, &s.Kategori, &s.JumlahTrip, &s.TerakhirDibeli, &s.HariSejakTerakhir, &s.RataRataJarakHari); err != nil {
+			log.Printf("Error scanning staple item: %v", err)
+			continue
+		}
+		s.Terlambat = float64(s.HariSejakTerakhir) > s.RataRataJarakHari
+		results = append(results, s)
+	}
+	return results, rows.Err()
+}
+
+// GetProductMonths menjumlahkan kuantitas dan belanja setiap produk (nama ternormalisasi)
+// per bulan kalender dalam rentang tanggal; bahan indeks harga pribadi
+func (r *AnalyticsRepository) GetProductMonths(ctx context.Context, userID int, startDate time.Time, endDate time.Time) ([]model.ProductMonth, error) {
+	query := `
+		WITH ` + purchasedCTE + `
+		SELECT kunci, kategori, DATE_TRUNC('month', hari)::date AS bulan,
+		       SUM(jumlah_item) AS kuantitas, SUM(total_harga) AS total
+		FROM purchased
+		GROUP BY kunci, kategori, bulan
+		HAVING SUM(jumlah_item) > 0
+		ORDER BY bulan ASC, kunci ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		log.Printf("Error querying product months: %v", err)
+		return nil, fmt.Errorf("failed to get monthly product prices: %w", err)
+	}
+	defer rows.Close()
+
+	var results []model.ProductMonth
+	for rows.Next() {
+		var p model.ProductMonth
+		if err := rows.Scan(&p.Produk, &p.Kategori, &p.Bulan, &p.Kuantitas, &p.Total); err != nil {
+			log.Printf("Error scanning product month: %v", err)
+			continue
+		}
+		results = append(results, p)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/lib/pq"
+)
+
+// AnomalyRepository menangani riwayat yang dibutuhkan detektor anomali
+// dan tabel 'spending_anomalies'
+type AnomalyRepository struct {
+	db *sql.DB
+}
+
+// NewAnomalyRepository membuat instance AnomalyRepository baru
+func NewAnomalyRepository() *AnomalyRepository {
+	return &AnomalyRepository{db: db.DB}
+}
+
+// GetPriceHistory mengambil harga satuan dari 'limit' pembelian terakhir item dengan nama
+// (ternormalisasi) yang sama di household yang sama, yang dicatat sebelum item itemID.
+// Item itemID harus berada di householdID. Semua query detektor menerima household
+// secara eksplisit karena batch malam berjalan tanpa household aktif.
+func (r *AnomalyRepository) GetPriceHistory(ctx context.Context, householdID int, name string, itemID int, limit int) ([]float64, error) {
+	query := `
+		SELECT h.harga_satuan
+		FROM items h
+		JOIN items self ON self.id_item = $3 AND self.id_household = $1
+		WHERE h.id_household = self.id_household AND LOWER(TRIM(h.nama_item)) = $2 AND h.id_item <> self.id_item
+		  AND h.status = 'purchased' AND h.deleted_at IS NULL AND h.purchased_date < self.purchased_date
+		ORDER BY h.purchased_date DESC
+		LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID, name, itemID, limit)
+	if err != nil {
+		log.Printf("Error querying price history: %v", err)
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []float64
+	for rows.Next() {
+		var price float64
+		if err := rows.Scan(&price); err != nil {
+			log.Printf("Error scanning price history: %v", err)
+			continue
+		}
+		prices = append(prices, price)
+	}
+	return prices, rows.Err()
+}
+
+// GetItemsNear mengambil item lain dengan nama (ternormalisasi) yang sama yang dicatat
+// dalam selang 'window' sebelum atau sesudah item itemID; kandidat untuk pengecekan
+// duplikat. Waktu item itemID sendiri ikut dikembalikan agar keduanya bisa dibandingkan.
+func (r *AnomalyRepository) GetItemsNear(ctx context.Context, householdID int, name string, itemID int, window time.Duration) ([]model.Item, time.Time, error) {
+	query := `
+		SELECT o.id_item, o.id_user, o.nama_item, o.harga_satuan, o.purchased_date, self.purchased_date
+		FROM items o
+		JOIN items self ON self.id_item = $3 AND self.id_household = $1
+		WHERE o.id_household = self.id_household AND LOWER(TRIM(o.nama_item)) = $2 AND o.id_item <> self.id_item
+		  AND o.status = 'purchased' AND o.deleted_at IS NULL
+		  AND o.purchased_date BETWEEN self.purchased_date - make_interval(secs => $4)
+		                           AND self.purchased_date + make_interval(secs => $4)
+		ORDER BY o.purchased_date ASC`
+
+	var selfTime time.Time
+	rows, err := r.db.QueryContext(ctx, query, householdID, name, itemID, window.Seconds())
+	if err != nil {
+		log.Printf("Error querying duplicate candidates: %v", err)
+		return nil, selfTime, fmt.Errorf("failed to get duplicate candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.Item
+	for rows.Next() {
+		var item model.Item
+		if err := rows.Scan(&item.ID, &item.UserID, &item.ItemName, &item.UnitPrice, &item.PurchasedDate, &selfTime); err != nil {
+			log.Printf("Error scanning duplicate candidate: %v", err)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, selfTime, rows.Err()
+}
+
+// GetPurchaseDay mengembalikan tanggal pembelian item di household tersebut (bagian tanggal dari purchased_date,
+// sama dengan yang dipakai agregasi mingguan lainnya)
+func (r *AnomalyRepository) GetPurchaseDay(ctx context.Context, itemID int, householdID int) (time.Time, error) {
+	var day time.Time
+	err := r.db.QueryRowContext(ctx, `SELECT purchased_date::date FROM items WHERE id_item = $1 AND id_household = $2`,
+		itemID, householdID).Scan(&day)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return day, ErrNotFound
+		}
+		log.Printf("Error fetching purchase day of item %d: %v", itemID, err)
+		return day, fmt.Errorf("failed to get purchase day: %w", err)
+	}
+	return day, nil
+}
+
+// GetCategoryWeekTotals menghitung total belanja satu kategori (seluruh anggota household) per minggu (batas minggu
+// mengikuti kalender household) dalam rentang [from, until). Kunci map adalah awal minggu
+// berformat YYYY-MM-DD; minggu tanpa belanja tidak ada di map.
+func (r *AnomalyRepository) GetCategoryWeekTotals(ctx context.Context, householdID int, categoryID int, from time.Time, until time.Time, cal calendar.Calendar) (map[string]float64, error) {
+	query := `
+		SELECT
+			purchased_date::date - ((EXTRACT(DOW FROM purchased_date)::int - $5 + 7) % 7) AS awal_minggu,
+			SUM(total_harga) AS total
+		FROM items
+		WHERE id_household = $1 AND id_kategori = $2 AND status = 'purchased' AND deleted_at IS NULL
+		  AND purchased_date >= $3::date AND purchased_date < $4::date
+		GROUP BY awal_minggu`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID, categoryID,
+		from.Format(calendar.DateLayout), until.Format(calendar.DateLayout), int(cal.WeekStart()))
+	if err != nil {
+		log.Printf("Error querying category week totals: %v", err)
+		return nil, fmt.Errorf("failed to get category week totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]float64)
+	for rows.Next() {
+		var weekStart time.Time
+		var total float64
+		if err := rows.Scan(&weekStart, &total); err != nil {
+			log.Printf("Error scanning category week total: %v", err)
+			continue
+		}
+		totals[weekStart.Format(calendar.DateLayout)] = total
+	}
+	return totals, rows.Err()
+}
+
+// GetPurchasedItemsSince mengambil item 'purchased' semua household sejak 'since',
+// dikelompokkan per household; dipakai oleh batch deteksi malam. Batch tidak punya
+// household aktif, jadi hanya item yang pembuatnya masih anggota household-nya yang diambil.
+func (r *AnomalyRepository) GetPurchasedItemsSince(ctx context.Context, since time.Time) ([]model.Item, error) {
+	query := `
+		SELECT i.id_item, i.id_user, i.id_household, COALESCE(i.id_kategori, 0), i.nama_item, i.jumlah_item,
+		       i.harga_satuan, i.total_harga, i.purchased_date, i.status
+		FROM items i
+		WHERE i.status = 'purchased' AND i.deleted_at IS NULL AND i.purchased_date >= $1
+		  AND i.id_household IN (SELECT id_household FROM household_members WHERE id_user = i.id_user)
+		ORDER BY i.id_household ASC, i.purchased_date ASC, i.id_item ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		log.Printf("Error querying recent items for anomaly scan: %v", err)
+		return nil, fmt.Errorf("failed to get recent items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.Item
+	for rows.Next() {
+		var item model.Item
+		if err := rows.Scan(&item.ID, &item.UserID, &item.HouseholdID, &item.CategoryID, &item.ItemName, &item.Quantity,
+			&item.UnitPrice, &item.TotalCost, &item.PurchasedDate, &item.Status); err != nil {
+			log.Printf("Error scanning recent item: %v", err)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// SaveAnomaly mencatat anomali. Jika kuncinya sudah ada dan masih 'open', nilai dan
+// skornya diperbarui; jika sudah di-dismiss/confirm, tidak ada yang berubah.
+// Mengembalikan true hanya jika anomali baru dibuat.
+func (r *AnomalyRepository) SaveAnomaly(ctx context.Context, a *model.Anomaly) (bool, error) {
+	query := `
+		INSERT INTO spending_anomalies
+			(id_user, id_household, jenis, id_item, id_item_terkait, id_kategori, awal_minggu, nilai, median, skor, pesan, kunci)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::date, $8, $9, $10, $11, $12)
+		ON CONFLICT (id_household, kunci) DO UPDATE
+			SET nilai = EXCLUDED.nilai, median = EXCLUDED.median, skor = EXCLUDED.skor, pesan = EXCLUDED.pesan
+			WHERE spending_anomalies.status = 'open'
+		RETURNING id_anomali, status, created_at, (xmax = 0) AS inserted`
+
+	var weekStart any
+	if a.WeekStart != nil {
+		weekStart = a.WeekStart.Format(calendar.DateLayout)
+	}
+
+	var inserted bool
+	err := r.db.QueryRowContext(ctx, query, a.UserID, a.HouseholdID, a.Type, a.ItemID, a.RelatedItemID, a.CategoryID, weekStart,
+		a.Value, a.Median, a.Score, a.Message, a.Key).Scan(&a.ID, &a.Status, &a.CreatedAt, &inserted)
+	if err == sql.ErrNoRows {
+		// Sudah ditindaklanjuti anggota household
+		return false, nil
+	}
+	if err != nil {
+		log.Printf("Error saving anomaly: %v", err)
+		return false, fmt.Errorf("failed to save anomaly: %w", err)
+	}
+	return inserted, nil
+}
+
+// ClearOpenAnomaly menghapus anomali yang masih 'open' dengan kunci tersebut,
+// misalnya setelah item diperbaiki sehingga tidak lagi tampak janggal
+func (r *AnomalyRepository) ClearOpenAnomaly(ctx context.Context, householdID int, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM spending_anomalies WHERE id_household = $1 AND kunci = $2 AND status = 'open'`, householdID, key)
+	if err != nil {
+		log.Printf("Error clearing anomaly: %v", err)
+		return fmt.Errorf("failed to clear anomaly: %w", err)
+	}
+	return nil
+}
+
+// ClearStaleDuplicates menghapus tanda entri dobel 'open' yang melibatkan item tersebut
+// dan kuncinya tidak ada di 'keep', misalnya setelah nama atau harga item diubah
+// sehingga pasangannya tidak lagi tampak dobel
+func (r *AnomalyRepository) ClearStaleDuplicates(ctx context.Context, householdID int, itemID int, keep []string) error {
+	query := `DELETE FROM spending_anomalies
+	          WHERE id_household = $1 AND jenis = $2 AND status = 'open'
+	            AND (id_item = $3 OR id_item_terkait = $3)
+	            AND NOT (kunci = ANY($4))`
+
+	if keep == nil {
+		keep = []string{} // pq.Array(nil) menjadi NULL, dan NOT (kunci = ANY(NULL)) tidak pernah benar
+	}
+	if _, err := r.db.ExecContext(ctx, query, householdID, model.AnomalyDuplicate, itemID, pq.Array(keep)); err != nil {
+		log.Printf("Error clearing stale duplicate anomalies: %v", err)
+		return fmt.Errorf("failed to clear stale duplicate anomalies: %w", err)
+	}
+	return nil
+}
+
+// GetAnomalies mengambil anomali household aktif user (terbaru lebih dulu) beserta jumlah total untuk pagination.
+// 'status' kosong berarti semua status. Anomali milik item yang sudah dihapus tidak ditampilkan.
+func (r *AnomalyRepository) GetAnomalies(ctx context.Context, userID int, status string, limit int, offset int) ([]model.Anomaly, int, error) {
+	query := `
+		SELECT a.id_anomali, a.id_user, a.id_household, a.jenis, a.status, a.id_item, a.id_item_terkait, a.id_kategori,
+		       a.awal_minggu, a.nilai, a.median, a.skor, a.pesan, a.created_at, a.resolved_at,
+		       COALESCE(i.nama_item, ''), COALESCE(rk.nama_kategori, ''),
+		       COUNT(*) OVER () AS total_rows
+		FROM spending_anomalies a
+		LEFT JOIN items i ON i.id_item = a.id_item
+		LEFT JOIN referensi_kategori rk ON rk.id_kategori = COALESCE(a.id_kategori, i.id_kategori)
+		WHERE a.id_household = household_of($1) AND ($2::text = '' OR a.status = $2) AND i.deleted_at IS NULL
+		ORDER BY a.created_at DESC, a.id_anomali DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, status, limit, offset)
+	if err != nil {
+		log.Printf("Error querying anomalies for user %d: %v", userID, err)
+		return nil, 0, fmt.Errorf("failed to fetch anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		anomalies []model.Anomaly
+		total     int
+	)
+	for rows.Next() {
+		var a model.Anomaly
+		var itemID, relatedID, categoryID sql.NullInt64
+		var weekStart, resolvedAt sql.NullTime
+		if err := rows.Scan(&a.ID, &a.UserID, &a.HouseholdID, &a.Type, &a.Status, &itemID, &relatedID, &categoryID,
+			&weekStart, &a.Value, &a.Median, &a.Score, &a.Message, &a.CreatedAt, &resolvedAt,
+			&a.ItemName, &a.CategoryName, &total); err != nil {
+			log.Printf("Error scanning anomaly row: %v", err)
+			continue
+		}
+		a.ItemID = nullableInt(itemID)
+		a.RelatedItemID = nullableInt(relatedID)
+		a.CategoryID = nullableInt(categoryID)
+		if weekStart.Valid {
+			a.WeekStart = &weekStart.Time
+		}
+		if resolvedAt.Valid {
+			a.ResolvedAt = &resolvedAt.Time
+		}
+		anomalies = append(anomalies, a)
+	}
+	if rows.Err() != nil {
+		return nil, 0, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+
+	// Halaman di luar jangkauan tidak mengembalikan baris, hitung total secara terpisah
+	if len(anomalies) == 0 && offset > 0 {
+		countQuery := `
+			SELECT COUNT(*) FROM spending_anomalies a
+			LEFT JOIN items i ON i.id_item = a.id_item
+			WHERE a.id_household = household_of($1) AND ($2::text = '' OR a.status = $2) AND i.deleted_at IS NULL`
+		if err := r.db.QueryRowContext(ctx, countQuery, userID, status).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count anomalies: %w", err)
+		}
+	}
+
+	return anomalies, total, nil
+}
+
+// SetAnomalyStatus mencatat tindak lanjut user (dismissed/confirmed) atas anomali di household-nya.
+// Hanya owner/editor yang bisa; selain itu mengembalikan ErrNotFound.
+func (r *AnomalyRepository) SetAnomalyStatus(ctx context.Context, anomalyID int, userID int, status string) error {
+	query := `UPDATE spending_anomalies SET status = $1, resolved_at = NOW()
+	          WHERE id_anomali = $2 AND id_household = household_writable($3)`
+
+	result, err := r.db.ExecContext(ctx, query, status, anomalyID, userID)
+	if err != nil {
+		log.Printf("Error updating anomaly status: %v", err)
+		return fmt.Errorf("failed to update anomaly: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// nullableInt mengubah kolom INT yang boleh NULL menjadi *int
+func nullableInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	id := int(v.Int64)
+	return &id
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
 	"github.com/gusti3111/TKBMG/backend/internal/db"
 	"github.com/gusti3111/TKBMG/backend/internal/model" // Menggunakan 'model' bukan 'models'
 )
@@ -22,27 +23,12 @@ func NewBudgetRepository() *BudgetRepository {
 	return &BudgetRepository{db: db.DB}
 }
 
-// Helper function untuk mendapatkan rentang minggu (Asumsi Minggu s/d Sabtu)
-// Anda bisa sesuaikan logika ini jika minggu Anda dimulai hari Senin
-func getWeekRange(date time.Time) (time.Time, time.Time) {
-	// Temukan Minggu (hari 0)
-	startOfWeek := date.AddDate(0, 0, -int(date.Weekday()))
-	// Temukan Sabtu (hari 6)
-	endOfWeek := startOfWeek.AddDate(0, 0, 6)
-
-	// Format ke YYYY-MM-DD 00:00:00 dan 23:59:59 untuk perbandingan SQL
-	start := time.Date(startOfWeek.Year(), startOfWeek.Month(), startOfWeek.Day(), 0, 0, 0, 0, date.Location())
-	end := time.Date(endOfWeek.Year(), endOfWeek.Month(), endOfWeek.Day(), 23, 59, 59, 0, date.Location())
-
-	return start, end
-}
-
-// GetBudgetByDate mengambil budget yang aktif untuk user pada tanggal tertentu
-// Ini adalah fungsi yang akan dipanggil oleh GetDashboardSummary
+// GetBudgetByDate mengambil budget household aktif user yang berlaku pada tanggal tertentu
+// Mengembalikan ErrNotFound jika tidak ada budget yang aktif pada tanggal tersebut
 func (r *BudgetRepository) GetBudgetByDate(ctx context.Context, userID int, date time.Time) (*model.Budget, error) {
-	query := `SELECT id_anggaran, id_user, start_date, end_date, jumlah_anggaran
+	query := `SELECT id_anggaran, id_household, id_user, period_type, start_date, end_date, jumlah_anggaran
 	          FROM anggaran 
-	          WHERE id_user = $1 AND $2 BETWEEN start_date AND end_date
+	          WHERE id_household = household_of($1) AND deleted_at IS NULL AND $2 BETWEEN start_date AND end_date
 	          ORDER BY start_date DESC
 	          LIMIT 1`
 
@@ -51,7 +37,9 @@ func (r *BudgetRepository) GetBudgetByDate(ctx context.Context, userID int, date
 
 	err := row.Scan(
 		&budget.ID,
+		&budget.HouseholdID,
 		&budget.UserID,
+		&budget.PeriodType,
 		&budget.StartDate,
 		&budget.EndDate,
 		&budget.Amount,
@@ -60,56 +48,475 @@ func (r *BudgetRepository) GetBudgetByDate(ctx context.Context, userID int, date
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Ini bukan error fatal, hanya berarti tidak ada budget yang di-set
-			log.Printf("Info: No budget found for user %d in this date range", userID)
-			return nil, fmt.Errorf("no budget found for this date range")
+			return nil, ErrNotFound
 		}
 		log.Printf("Error scanning budget: %v", err)
 		return nil, fmt.Errorf("failed to scan budget: %w", err)
 	}
 
-	return &budget, nil
+	// Jumlah efektif = jumlah dasar + carry-over dari periode sebelumnya
+	status := model.BudgetStatus{Budget: budget}
+	if err := r.applyRollover(ctx, []*model.BudgetStatus{&status}); err != nil {
+		return nil, err
+	}
+
+	return &status.Budget, nil
 }
 
 // UpsertBudgetForCurrentWeek membuat atau memperbarui budget untuk minggu ini
 // Ini akan dipanggil oleh handler Halaman "Set Budget" (POST /api/v1/budgets)
-func (r *BudgetRepository) UpsertBudgetForCurrentWeek(ctx context.Context, userID int, amount float64) error {
-	// Tentukan awal dan akhir minggu ini
-	startOfWeek, endOfWeek := getWeekRange(time.Now())
+// Mengembalikan budget sebelum (nil jika baru dibuat) dan sesudah perubahan untuk log audit.
+// Jumlah budget yang sudah ada disesuaikan dengan mode alokasinya seperti pada UpdateBudget.
+func (r *BudgetRepository) UpsertBudgetForCurrentWeek(ctx context.Context, userID int, amount float64, cal calendar.Calendar) (*model.Budget, *model.Budget, error) {
+	// Tentukan awal dan akhir minggu ini sesuai kalender household (pengaturan owner, lihat CalendarMiddleware)
+	startOfWeek, endOfWeek := cal.WeekRange(cal.Now())
 
 	// 1. Cek apakah budget untuk minggu ini sudah ada
-	var existingID int
-	checkQuery := `SELECT id_anggaran FROM anggaran WHERE id_user = $1 AND start_date = $2`
+	var existing model.Budget
+	checkQuery := `SELECT id_anggaran, id_household, id_user, period_type, start_date, end_date, jumlah_anggaran
+	               FROM anggaran WHERE id_household = household_writable($1) AND start_date = $2 AND deleted_at IS NULL`
 
-	err := r.db.QueryRowContext(ctx, checkQuery, userID, startOfWeek).Scan(&existingID)
+	err := r.db.QueryRowContext(ctx, checkQuery, userID, startOfWeek).Scan(&existing.ID, &existing.HouseholdID, &existing.UserID,
+		&existing.PeriodType, &existing.StartDate, &existing.EndDate, &existing.Amount)
 
-	// 2. Jika tidak ada (ErrNoRows), INSERT
+	// 2. Jika tidak ada (ErrNoRows), INSERT (ditolak jika bertabrakan dengan budget lain)
 	if err == sql.ErrNoRows {
-		insertQuery := `INSERT INTO anggaran (id_user, start_date, end_date, jumlah_anggaran)
-		                VALUES ($1, $2, $3, $4)`
-		_, errInsert := r.db.ExecContext(ctx, insertQuery, userID, startOfWeek, endOfWeek, amount)
-		if errInsert != nil {
-			log.Printf("Error inserting new budget: %v", errInsert)
-			return fmt.Errorf("failed to insert budget: %w", errInsert)
+		budget := &model.Budget{
+			UserID:     userID,
+			PeriodType: model.PeriodWeekly,
+			StartDate:  startOfWeek,
+			EndDate:    endOfWeek,
+			Amount:     amount,
+		}
+		if errInsert := r.CreateBudget(ctx, budget); errInsert != nil {
+			return nil, nil, errInsert
 		}
 		log.Printf("Successfully INSERTED budget for user %d", userID)
-		return nil
+		return nil, budget, nil
 	}
 
 	// 3. Jika ada error lain saat mengecek
 	if err != nil {
 		log.Printf("Error checking existing budget: %v", err)
-		return fmt.Errorf("failed to check budget: %w", err)
+		return nil, nil, fmt.Errorf("failed to check budget: %w", err)
 	}
 
-	// 4. Jika ada (tidak error), UPDATE
-	updateQuery := `UPDATE anggaran SET jumlah_anggaran = $1, end_date = $2
-	                WHERE id_anggaran = $3 AND id_user = $4`
-	_, errUpdate := r.db.ExecContext(ctx, updateQuery, amount, endOfWeek, existingID, userID)
+	// 4. Jika ada (tidak error), UPDATE jumlahnya saja (periode budget tetap),
+	//    disesuaikan dengan mode alokasinya dalam satu transaksi
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	amount, err = reconcileBudgetAmount(ctx, tx, existing.ID, userID, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updateQuery := `UPDATE anggaran SET jumlah_anggaran = $1
+	                WHERE id_anggaran = $2 AND id_household = household_writable($3)`
+	_, errUpdate := tx.ExecContext(ctx, updateQuery, amount, existing.ID, userID)
 	if errUpdate != nil {
 		log.Printf("Error updating existing budget: %v", errUpdate)
-		return fmt.Errorf("failed to update budget: %w", errUpdate)
+		return nil, nil, fmt.Errorf("failed to update budget: %w", errUpdate)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit budget update: %w", err)
 	}
 
 	log.Printf("Successfully UPDATED budget for user %d", userID)
+	updated := existing
+	updated.Amount = amount
+	return &existing, &updated, nil
+}
+
+// DeleteBudget memindahkan budget di household aktif user ke trash (soft delete)
+func (r *BudgetRepository) DeleteBudget(ctx context.Context, budgetID int, userID int) error {
+	query := `UPDATE anggaran SET deleted_at = NOW() WHERE id_anggaran = $1 AND id_household = household_writable($2) AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, budgetID, userID)
+	if err != nil {
+		log.Printf("Error deleting budget: %v", err)
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateBudget menyimpan budget baru dengan periode eksplisit.
+// Mengembalikan ErrOverlap jika periodenya bertabrakan dengan budget lain di household yang sama.
+func (r *BudgetRepository) CreateBudget(ctx context.Context, budget *model.Budget) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkBudgetOverlap(ctx, tx, budget); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO anggaran (id_user, id_household, period_type, start_date, end_date, jumlah_anggaran)
+	          VALUES ($1, household_writable($1), $2, $3, $4, $5) RETURNING id_anggaran`
+	err = tx.QueryRowContext(ctx, query, budget.UserID, budget.PeriodType, budget.StartDate, budget.EndDate, budget.Amount).Scan(&budget.ID)
+	if err != nil {
+		log.Printf("Error inserting new budget: %v", err)
+		return fmt.Errorf("failed to insert budget: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateBudget mengubah periode dan jumlah budget di household aktif user (termasuk budget lampau).
+// Mengembalikan ErrOverlap jika periode barunya bertabrakan dengan budget lain, dan
+// ErrAllocationExceedsBudget jika jumlah baru lebih kecil dari total alokasi (mode 'cap').
+// Pada mode 'sum' budget.Amount diganti dengan total alokasi.
+func (r *BudgetRepository) UpdateBudget(ctx context.Context, budget *model.Budget) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkBudgetOverlap(ctx, tx, budget); err != nil {
+		return err
+	}
+
+	amount, err := reconcileBudgetAmount(ctx, tx, budget.ID, budget.UserID, budget.Amount)
+	if err != nil {
+		return err
+	}
+	budget.Amount = amount
+
+	query := `UPDATE anggaran SET period_type = $1, start_date = $2, end_date = $3, jumlah_anggaran = $4
+	          WHERE id_anggaran = $5 AND id_household = household_writable($6) AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, budget.PeriodType, budget.StartDate, budget.EndDate, budget.Amount, budget.ID, budget.UserID)
+	if err != nil {
+		log.Printf("Error updating budget: %v", err)
+		return fmt.Errorf("failed to update budget: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// GetBudgetByID mengambil satu budget di household aktif user
+func (r *BudgetRepository) GetBudgetByID(ctx context.Context, budgetID int, userID int) (*model.Budget, error) {
+	query := `SELECT id_anggaran, id_household, id_user, period_type, start_date, end_date, jumlah_anggaran
+	          FROM anggaran WHERE id_anggaran = $1 AND id_household = household_of($2) AND deleted_at IS NULL`
+
+	var budget model.Budget
+	err := r.db.QueryRowContext(ctx, query, budgetID, userID).Scan(
+		&budget.ID,
+		&budget.HouseholdID,
+		&budget.UserID,
+		&budget.PeriodType,
+		&budget.StartDate,
+		&budget.EndDate,
+		&budget.Amount,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error scanning budget: %v", err)
+		return nil, fmt.Errorf("failed to scan budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// checkBudgetOverlap mengunci budget household aktif user selama transaksi (advisory lock)
+// lalu memastikan tidak ada budget aktif lain yang periodenya beririsan.
+// budget.ID bernilai 0 untuk budget baru.
+func checkBudgetOverlap(ctx context.Context, tx *sql.Tx, budget *model.Budget) error {
+	// Lock per household agar dua request paralel (juga dari anggota lain) tidak lolos cek overlap bersamaan
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('anggaran'), COALESCE(household_writable($1), 0))`, budget.UserID); err != nil {
+		log.Printf("Error locking budgets for user %d: %v", budget.UserID, err)
+		return fmt.Errorf("failed to lock budgets: %w", err)
+	}
+
+	query := `SELECT EXISTS (
+	              SELECT 1 FROM anggaran
+	              WHERE id_household = household_writable($1) AND deleted_at IS NULL AND id_anggaran <> $2
+	                AND start_date <= $4 AND end_date >= $3
+	          )`
+	var overlap bool
+	if err := tx.QueryRowContext(ctx, query, budget.UserID, budget.ID, budget.StartDate, budget.EndDate).Scan(&overlap); err != nil {
+		log.Printf("Error checking budget overlap: %v", err)
+		return fmt.Errorf("failed to check budget overlap: %w", err)
+	}
+	if overlap {
+		return ErrOverlap
+	}
 	return nil
 }
+
+// budgetStatusQuery menghitung realisasi setiap budget dalam satu query (tanpa N+1):
+// total belanja per periode diambil lewat LATERAL join ke tabel items.
+// Kolom terakhir (COUNT OVER) adalah total baris sebelum LIMIT, untuk pagination.
+// Sisa dan persentase di sini relatif terhadap jumlah dasar; applyRollover
+// menghitungnya ulang terhadap jumlah efektif.
+const budgetStatusQuery = `
+	SELECT
+		a.id_anggaran, a.id_household, a.id_user, a.period_type, a.start_date, a.end_date, a.jumlah_anggaran,
+		COALESCE(s.total, 0) AS total_belanja,
+		COALESCE(sw.jumlah, 0) AS disisihkan,
+		a.jumlah_anggaran - COALESCE(s.total, 0) - COALESCE(sw.jumlah, 0) AS sisa_budget,
+		CASE WHEN a.jumlah_anggaran > 0
+			THEN ROUND((COALESCE(s.total, 0) / a.jumlah_anggaran * 100)::numeric, 2)
+			ELSE 0
+		END AS persen_terpakai,
+		COALESCE(s.total, 0) > a.jumlah_anggaran AS over_budget,
+		COUNT(*) OVER () AS total_rows
+	FROM anggaran a
+	LEFT JOIN LATERAL (
+		SELECT SUM(i.total_harga) AS total
+		FROM items i
+		WHERE i.id_household = a.id_household AND i.status = 'purchased' AND i.deleted_at IS NULL
+		  AND i.purchased_date BETWEEN a.start_date AND a.end_date
+	) s ON true
+	LEFT JOIN anggaran_sweeps sw ON sw.id_anggaran = a.id_anggaran`
+
+// GetBudgetHistory mengambil riwayat budget household aktif user (terbaru lebih dulu) beserta realisasinya.
+// Mengembalikan juga jumlah total budget untuk pagination.
+func (r *BudgetRepository) GetBudgetHistory(ctx context.Context, userID int, limit int, offset int) ([]model.BudgetStatus, int, error) {
+	query := budgetStatusQuery + `
+	WHERE a.id_household = household_of($1) AND a.deleted_at IS NULL
+	ORDER BY a.start_date DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		log.Printf("Error querying budget history for user %d: %v", userID, err)
+		return nil, 0, fmt.Errorf("failed to fetch budget history: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		history []model.BudgetStatus
+		total   int
+	)
+	for rows.Next() {
+		status, rowTotal, err := scanBudgetStatus(rows)
+		if err != nil {
+			log.Printf("Error scanning budget history row: %v", err)
+			continue
+		}
+		total = rowTotal
+		history = append(history, *status)
+	}
+	if rows.Err() != nil {
+		return nil, 0, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+
+	targets := make([]*model.BudgetStatus, len(history))
+	for i := range history {
+		targets[i] = &history[i]
+	}
+	if err := r.applyRollover(ctx, targets); err != nil {
+		return nil, 0, err
+	}
+
+	// Halaman di luar jangkauan tidak mengembalikan baris, hitung total secara terpisah
+	if len(history) == 0 && offset > 0 {
+		countQuery := `SELECT COUNT(*) FROM anggaran WHERE id_household = household_of($1) AND deleted_at IS NULL`
+		if err := r.db.QueryRowContext(ctx, countQuery, userID).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count budgets: %w", err)
+		}
+	}
+
+	return history, total, nil
+}
+
+// GetBudgetStatusByID mengambil satu budget di household aktif user beserta realisasinya
+func (r *BudgetRepository) GetBudgetStatusByID(ctx context.Context, budgetID int, userID int) (*model.BudgetStatus, error) {
+	query := budgetStatusQuery + `
+	WHERE a.id_anggaran = $1 AND a.id_household = household_of($2) AND a.deleted_at IS NULL`
+
+	status, _, err := scanBudgetStatus(r.db.QueryRowContext(ctx, query, budgetID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching budget status %d: %v", budgetID, err)
+		return nil, fmt.Errorf("failed to fetch budget: %w", err)
+	}
+	if err := r.applyRollover(ctx, []*model.BudgetStatus{status}); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetBudgetStatusByDate mengambil budget yang aktif pada tanggal tertentu beserta realisasinya
+func (r *BudgetRepository) GetBudgetStatusByDate(ctx context.Context, userID int, date time.Time) (*model.BudgetStatus, error) {
+	query := budgetStatusQuery + `
+	WHERE a.id_household = household_of($1) AND a.deleted_at IS NULL AND $2 BETWEEN a.start_date AND a.end_date
+	ORDER BY a.start_date DESC
+	LIMIT 1`
+
+	status, _, err := scanBudgetStatus(r.db.QueryRowContext(ctx, query, userID, date))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching current budget status for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch budget: %w", err)
+	}
+	if err := r.applyRollover(ctx, []*model.BudgetStatus{status}); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetBudgetStatusForHousehold mengambil satu budget household beserta realisasinya tanpa konteks user,
+// misalnya untuk scheduler. Carry-over dihitung dengan pengaturan periode household.
+func (r *BudgetRepository) GetBudgetStatusForHousehold(ctx context.Context, budgetID int, householdID int) (*model.BudgetStatus, error) {
+	query := budgetStatusQuery + `
+	WHERE a.id_anggaran = $1 AND a.id_household = $2 AND a.deleted_at IS NULL`
+
+	status, _, err := scanBudgetStatus(r.db.QueryRowContext(ctx, query, budgetID, householdID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching budget status %d: %v", budgetID, err)
+		return nil, fmt.Errorf("failed to fetch budget: %w", err)
+	}
+
+	settings, err := getHouseholdSettings(ctx, r.db, householdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.applyRolloverWith(ctx, settings, []*model.BudgetStatus{status}); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// applyRollover mengisi carry-over, jumlah efektif, dan sisa budget untuk 'targets'
+// sesuai kebijakan rollover household (pengaturan owner). Rantai dihitung ulang dari budget pertama setiap kali
+// dipanggil, sehingga perubahan item atau budget lampau langsung tercermin.
+func (r *BudgetRepository) applyRollover(ctx context.Context, targets []*model.BudgetStatus) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	settings, err := getHouseholdSettings(ctx, r.db, targets[0].HouseholdID)
+	if err != nil {
+		return err
+	}
+	return r.applyRolloverWith(ctx, settings, targets)
+}
+
+// applyRolloverWith menjalankan rollover dengan pengaturan yang sudah dimuat.
+// Semua 'targets' harus berasal dari household yang sama.
+func (r *BudgetRepository) applyRolloverWith(ctx context.Context, settings *model.UserSettings, targets []*model.BudgetStatus) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// Tanpa rollover setiap budget berdiri sendiri, tidak perlu memuat rantai
+	if settings.RolloverPolicy == model.RolloverNone {
+		for _, t := range targets {
+			chain := []model.BudgetStatus{*t}
+			model.ApplyRollover(*settings, chain)
+			*t = chain[0]
+		}
+		return nil
+	}
+
+	until := targets[0].StartDate
+	for _, t := range targets {
+		if t.StartDate.After(until) {
+			until = t.StartDate
+		}
+	}
+
+	chain, err := r.getBudgetChain(ctx, targets[0].HouseholdID, until)
+	if err != nil {
+		return err
+	}
+	model.ApplyRollover(*settings, chain)
+
+	byID := make(map[int]model.BudgetStatus, len(chain))
+	for _, b := range chain {
+		byID[b.ID] = b
+	}
+	for _, t := range targets {
+		if b, ok := byID[t.ID]; ok {
+			*t = b
+		}
+	}
+	return nil
+}
+
+// getBudgetChain mengambil semua budget aktif household yang dimulai paling lambat 'until',
+// urut dari yang paling lama, beserta realisasinya
+func (r *BudgetRepository) getBudgetChain(ctx context.Context, householdID int, until time.Time) ([]model.BudgetStatus, error) {
+	query := budgetStatusQuery + `
+	WHERE a.id_household = $1 AND a.deleted_at IS NULL AND a.start_date <= $2
+	ORDER BY a.start_date ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID, until)
+	if err != nil {
+		log.Printf("Error querying budget chain for household %d: %v", householdID, err)
+		return nil, fmt.Errorf("failed to fetch budget chain: %w", err)
+	}
+	defer rows.Close()
+
+	var chain []model.BudgetStatus
+	for rows.Next() {
+		status, _, err := scanBudgetStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget chain: %w", err)
+		}
+		chain = append(chain, *status)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return chain, nil
+}
+
+// rowScanner adalah antarmuka bersama *sql.Row dan *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBudgetStatus(row rowScanner) (*model.BudgetStatus, int, error) {
+	var s model.BudgetStatus
+	var total int
+	err := row.Scan(
+		&s.ID,
+		&s.HouseholdID,
+		&s.UserID,
+		&s.PeriodType,
+		&s.StartDate,
+		&s.EndDate,
+		&s.Amount,
+		&s.Spent,
+		&s.Swept,
+		&s.Remaining,
+		&s.PercentUsed,
+		&s.OverBudget,
+		&total,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &s, total, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gusti3111/TKBMG/backend/internal/db"
 	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/lib/pq"
 )
 
 // CategoryRepository menangani operasi database untuk 'referensi_kategori'.
@@ -21,32 +22,34 @@ func NewCategoryRepository() *CategoryRepository {
 	return &CategoryRepository{db: db.DB}
 }
 
-// CreateKategori menambahkan kategori baru ke database untuk user tertentu.
+// CreateKategori menambahkan kategori baru ke household aktif user tertentu.
 // Ini dipanggil oleh halaman 'Referensi Belanja'.
 func (r *CategoryRepository) CreateKategori(ctx context.Context, kategori *model.Category) error {
-	// file: catergory_repo.go (ganti baris 28-29)
-	query := `INSERT INTO referensi_kategori (id_user, nama_kategori)
-	          VALUES ($1, $2) RETURNING id_kategori`
-	// Asumsi Anda telah menambahkan kolom created_at di DB:
-	// query := `INSERT INTO referensi_kategori (id_user, nama_kategori, created_at)
-	// 	          VALUES ($1, $2, NOW()) RETURNING id_kategori`
+	if kategori.ParentID != nil {
+		if err := r.validateParent(ctx, kategori.UserID, 0, *kategori.ParentID); err != nil {
+			return err
+		}
+	}
+
+	query := `INSERT INTO referensi_kategori (id_user, id_household, nama_kategori, id_parent)
+	          VALUES ($1, household_writable($1), $2, $3) RETURNING id_kategori`
 
 	// Pastikan untuk mengambil UserID dari token di Handler dan mengisinya ke struct 'kategori'
-	err := r.db.QueryRowContext(ctx, query, kategori.UserID, kategori.CategoryName).Scan(&kategori.ID)
+	err := r.db.QueryRowContext(ctx, query, kategori.UserID, kategori.CategoryName, kategori.ParentID).Scan(&kategori.ID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
 		log.Printf("Error creating kategori: %v", err)
 		return fmt.Errorf("failed to save kategori: %w", err)
 	}
 	return nil
 }
 
-// GetKategoriByUserID mengambil semua kategori milik user tertentu.
+// GetKategoriByUserID mengambil semua kategori di household aktif user tertentu.
 // Ini dipanggil oleh halaman 'DaftarBelanja' (untuk dropdown) dan 'ReferensiBelanja'.
 func (r *CategoryRepository) GetKategoriByUserID(ctx context.Context, userID int) ([]model.Category, error) {
-	query := `SELECT id_kategori, id_user, nama_kategori FROM referensi_kategori WHERE id_user = $1 ORDER BY nama_kategori ASC`
-
-	// Sesuaikan query jika Anda menambahkan created_at
-	// query := `SELECT id_kategori, id_user, nama_kategori, created_at FROM referensi_kategori WHERE id_user = $1 ORDER BY nama_kategori ASC`
+	query := `SELECT id_kategori, id_user, nama_kategori, id_parent FROM referensi_kategori WHERE id_household = household_of($1) AND deleted_at IS NULL ORDER BY nama_kategori ASC`
 
 	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -58,12 +61,15 @@ func (r *CategoryRepository) GetKategoriByUserID(ctx context.Context, userID int
 	var kategoriList []model.Category
 	for rows.Next() {
 		var k model.Category
-		// Sesuaikan Scan jika Anda menambahkan created_at
-		// if err := rows.Scan(&k.ID, &k.UserID, &k.NamaKategori, &k.CreatedAt); err != nil { ... }
-		if err := rows.Scan(&k.ID, &k.UserID, &k.CategoryName); err != nil {
+		var parentID sql.NullInt64
+		if err := rows.Scan(&k.ID, &k.UserID, &k.CategoryName, &parentID); err != nil {
 			log.Printf("Error scanning kategori row: %v", err)
 			continue
 		}
+		if parentID.Valid {
+			id := int(parentID.Int64)
+			k.ParentID = &id
+		}
 		kategoriList = append(kategoriList, k)
 	}
 
@@ -74,12 +80,43 @@ func (r *CategoryRepository) GetKategoriByUserID(ctx context.Context, userID int
 	return kategoriList, nil
 }
 
-// UpdateKategori memperbarui nama kategori milik user tertentu.
+// GetKategoriByID mengambil satu kategori di household aktif user yang belum dihapus
+func (r *CategoryRepository) GetKategoriByID(ctx context.Context, kategoriID int, userID int) (*model.Category, error) {
+	query := `SELECT id_kategori, id_user, nama_kategori, id_parent FROM referensi_kategori
+	          WHERE id_kategori = $1 AND id_household = household_of($2) AND deleted_at IS NULL`
+
+	var k model.Category
+	var parentID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, kategoriID, userID).Scan(&k.ID, &k.UserID, &k.CategoryName, &parentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching kategori %d: %v", kategoriID, err)
+		return nil, fmt.Errorf("failed to fetch kategori: %w", err)
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		k.ParentID = &id
+	}
+	return &k, nil
+}
+
+// UpdateKategori memperbarui nama dan parent kategori di household aktif user tertentu.
 func (r *CategoryRepository) UpdateKategori(ctx context.Context, kategori *model.Category) error {
-	query := `UPDATE referensi_kategori SET nama_kategori = $1 WHERE id_kategori = $2 AND id_user = $3`
+	if kategori.ParentID != nil {
+		if err := r.validateParent(ctx, kategori.UserID, kategori.ID, *kategori.ParentID); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE referensi_kategori SET nama_kategori = $1, id_parent = $2 WHERE id_kategori = $3 AND id_household = household_writable($4) AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, kategori.CategoryName, kategori.ID, kategori.UserID)
+	result, err := r.db.ExecContext(ctx, query, kategori.CategoryName, kategori.ParentID, kategori.ID, kategori.UserID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
 		log.Printf("Error updating kategori: %v", err)
 		return fmt.Errorf("failed to update kategori: %w", err)
 	}
@@ -95,26 +132,203 @@ func (r *CategoryRepository) UpdateKategori(ctx context.Context, kategori *model
 	return nil
 }
 
-// DeleteKategori menghapus kategori milik user tertentu.
-func (r *CategoryRepository) DeleteKategori(ctx context.Context, kategoriID int, userID int) error {
-	// PERHATIAN: Query ini akan gagal jika 'id_kategori' masih digunakan di tabel 'items' (Constraint Error).
-	// Di 'Handler', Anda harus memeriksa dulu apakah kategori ini digunakan,
-	// atau set 'ON DELETE SET NULL' pada Foreign Key 'items.id_kategori'.
-	query := `DELETE FROM referensi_kategori WHERE id_kategori = $1 AND id_user = $2`
+// DeleteKategori memindahkan kategori di household aktif user tertentu ke trash (soft delete).
+// Semua item (dan entry template) yang memakai kategori ini dipindahkan ke 'reassignTo',
+// atau dibuat tanpa kategori jika 'reassignTo' bernilai nil. Semua dijalankan dalam satu transaksi.
+func (r *CategoryRepository) DeleteKategori(ctx context.Context, kategoriID int, userID int, reassignTo *int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockOwnedCategories(ctx, tx, userID, []int{kategoriID}); err != nil {
+		return err
+	}
+	if reassignTo != nil {
+		if *reassignTo == kategoriID {
+			return fmt.Errorf("target kategori must differ from the deleted kategori")
+		}
+		if err := lockOwnedCategories(ctx, tx, userID, []int{*reassignTo}); err != nil {
+			return err
+		}
+	}
+
+	if err := reassignItems(ctx, tx, userID, []int{kategoriID}, reassignTo); err != nil {
+		return err
+	}
+	// Sub-kategori naik satu tingkat ke parent dari kategori yang dihapus
+	reparent := `UPDATE referensi_kategori
+	             SET id_parent = (SELECT id_parent FROM referensi_kategori WHERE id_kategori = $1)
+	             WHERE id_household = household_writable($2) AND id_parent = $1`
+	if _, err := tx.ExecContext(ctx, reparent, kategoriID, userID); err != nil {
+		log.Printf("Error reparenting sub-kategori: %v", err)
+		return fmt.Errorf("failed to move sub-kategori: %w", err)
+	}
+	if err := softDeleteCategories(ctx, tx, userID, []int{kategoriID}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
-	result, err := r.db.ExecContext(ctx, query, kategoriID, userID)
+// MergeKategori menggabungkan beberapa kategori sumber ke satu kategori target secara atomik.
+// Item dan entry template dipindahkan ke target, lalu kategori sumber dipindahkan ke trash.
+func (r *CategoryRepository) MergeKategori(ctx context.Context, userID int, sourceIDs []int, targetID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return fmt.Errorf("target kategori cannot be one of the source kategori")
+		}
+	}
+
+	if err := lockOwnedCategories(ctx, tx, userID, append([]int{targetID}, sourceIDs...)); err != nil {
+		return err
+	}
+	if err := reassignItems(ctx, tx, userID, sourceIDs, &targetID); err != nil {
+		return err
+	}
+	// Sub-kategori dari kategori sumber pindah ke bawah target.
+	// Leluhur target tidak boleh ikut dipindah ke bawah target karena akan membentuk siklus.
+	reparent := `WITH RECURSIVE ancestors AS (
+	                 SELECT id_kategori, id_parent FROM referensi_kategori WHERE id_kategori = $1
+	                 UNION
+	                 SELECT rk.id_kategori, rk.id_parent FROM referensi_kategori rk
+	                 JOIN ancestors a ON rk.id_kategori = a.id_parent
+	             )
+	             UPDATE referensi_kategori SET id_parent = $1
+	             WHERE id_household = household_writable($2) AND id_parent = ANY($3)
+	               AND id_kategori NOT IN (SELECT id_kategori FROM ancestors)`
+	if _, err := tx.ExecContext(ctx, reparent, targetID, userID, pq.Array(sourceIDs)); err != nil {
+		log.Printf("Error reparenting sub-kategori: %v", err)
+		return fmt.Errorf("failed to move sub-kategori: %w", err)
+	}
+	// Jika target berada di bawah salah satu kategori sumber, naikkan target ke
+	// leluhur terdekat yang tidak ikut dihapus.
+	liftTarget := `WITH RECURSIVE ancestors AS (
+	                   SELECT id_kategori, id_parent, 0 AS depth FROM referensi_kategori WHERE id_kategori = $1
+	                   UNION ALL
+	                   SELECT rk.id_kategori, rk.id_parent, a.depth + 1 FROM referensi_kategori rk
+	                   JOIN ancestors a ON rk.id_kategori = a.id_parent
+	                   WHERE a.depth < 100
+	               )
+	               UPDATE referensi_kategori SET id_parent = (
+	                   SELECT id_kategori FROM ancestors
+	                   WHERE depth > 0 AND id_kategori <> ALL($2)
+	                   ORDER BY depth ASC LIMIT 1
+	               )
+	               WHERE id_kategori = $1 AND id_parent = ANY($2)`
+	if _, err := tx.ExecContext(ctx, liftTarget, targetID, pq.Array(sourceIDs)); err != nil {
+		log.Printf("Error reparenting target kategori: %v", err)
+		return fmt.Errorf("failed to move target kategori: %w", err)
+	}
+	if err := softDeleteCategories(ctx, tx, userID, sourceIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockOwnedCategories mengunci baris kategori (FOR UPDATE) dan memastikan semuanya
+// berada di household aktif user (yang boleh menulis) dan belum dihapus.
+// Mengembalikan ErrNotFound jika ada yang tidak valid.
+func lockOwnedCategories(ctx context.Context, tx *sql.Tx, userID int, ids []int) error {
+	query := `SELECT COUNT(*) FROM (
+	              SELECT id_kategori FROM referensi_kategori
+	              WHERE id_household = household_writable($1) AND id_kategori = ANY($2) AND deleted_at IS NULL
+	              FOR UPDATE
+	          ) locked`
+
+	unique := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, userID, pq.Array(ids)).Scan(&count); err != nil {
+		log.Printf("Error locking kategori: %v", err)
+		return fmt.Errorf("failed to check kategori: %w", err)
+	}
+	if count != len(unique) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// reassignItems memindahkan item dan entry template dari kategori 'fromIDs' ke 'to' (nil = tanpa kategori)
+func reassignItems(ctx context.Context, tx *sql.Tx, userID int, fromIDs []int, to *int) error {
+	queries := []string{
+		`UPDATE items SET id_kategori = $1 WHERE id_household = household_writable($2) AND id_kategori = ANY($3)`,
+		`UPDATE item_template_entries e SET id_kategori = $1
+		 FROM item_templates t
+		 WHERE e.id_template = t.id_template AND t.id_household = household_writable($2) AND e.id_kategori = ANY($3)`,
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, to, userID, pq.Array(fromIDs)); err != nil {
+			log.Printf("Error reassigning items: %v", err)
+			return fmt.Errorf("failed to reassign items: %w", err)
+		}
+	}
+	return nil
+}
+
+func softDeleteCategories(ctx context.Context, tx *sql.Tx, userID int, ids []int) error {
+	query := `UPDATE referensi_kategori SET deleted_at = NOW()
+	          WHERE id_household = household_writable($1) AND id_kategori = ANY($2) AND deleted_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, userID, pq.Array(ids)); err != nil {
 		log.Printf("Error deleting kategori: %v", err)
 		return fmt.Errorf("failed to delete kategori: %w", err)
 	}
+	return nil
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to check rows affected: %w", err)
+// validateParent memastikan parent berada di household yang sama, belum dihapus,
+// dan bukan kategori itu sendiri atau salah satu turunannya (mencegah siklus).
+// selfID bernilai 0 untuk kategori baru.
+func (r *CategoryRepository) validateParent(ctx context.Context, userID int, selfID int, parentID int) error {
+	if parentID == selfID {
+		return ErrInvalidParent
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("kategori not found or user not authorized")
+
+	// Telusuri leluhur parent; jika selfID ditemukan, parent adalah turunan dari kategori ini.
+	// UNION (bukan UNION ALL) menghentikan rekursi jika data lama sudah memiliki siklus.
+	query := `WITH RECURSIVE ancestors AS (
+	              SELECT id_kategori, id_parent FROM referensi_kategori
+	              WHERE id_kategori = $1 AND id_household = household_of($2) AND deleted_at IS NULL
+	              UNION
+	              SELECT rk.id_kategori, rk.id_parent FROM referensi_kategori rk
+	              JOIN ancestors a ON rk.id_kategori = a.id_parent
+	          )
+	          SELECT COUNT(*) > 0, COALESCE(BOOL_OR(id_kategori = $3), false) FROM ancestors`
+
+	var parentExists, cycle bool
+	if err := r.db.QueryRowContext(ctx, query, parentID, userID, selfID).Scan(&parentExists, &cycle); err != nil {
+		log.Printf("Error validating parent kategori: %v", err)
+		return fmt.Errorf("failed to validate parent kategori: %w", err)
 	}
+	if !parentExists || cycle {
+		return ErrInvalidParent
+	}
+	return nil
+}
+
+// SeedDefaultKategori membuat kategori bawaan di household aktif user baru.
+// Nama yang sudah ada (tanpa membedakan huruf besar/kecil) dilewati.
+func (r *CategoryRepository) SeedDefaultKategori(ctx context.Context, userID int, names []string) error {
+	query := `INSERT INTO referensi_kategori (id_user, id_household, nama_kategori)
+	          VALUES ($1, household_writable($1), $2)
+	          ON CONFLICT (id_household, LOWER(nama_kategori)) WHERE deleted_at IS NULL DO NOTHING`
 
+	for _, name := range names {
+		if _, err := r.db.ExecContext(ctx, query, userID, name); err != nil {
+			log.Printf("Error seeding kategori %q for user %d: %v", name, userID, err)
+			return fmt.Errorf("failed to seed kategori: %w", err)
+		}
+	}
 	return nil
 }
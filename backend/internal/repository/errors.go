@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrNotFound dikembalikan jika baris tidak ditemukan atau bukan milik user.
+// Handler dapat memeriksanya dengan errors.Is untuk mengirim respons 404.
+var ErrNotFound = errors.New("data not found or user not authorized")
+
+// ErrConflict dikembalikan jika data melanggar constraint unik (misal nama kategori ganda).
+// Handler dapat memeriksanya dengan errors.Is untuk mengirim respons 409.
+var ErrConflict = errors.New("data already exists")
+
+// isUniqueViolation memeriksa apakah error dari PostgreSQL adalah unique_violation (23505)
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// ErrInvalidParent dikembalikan jika parent kategori tidak ditemukan
+// atau akan membentuk siklus (kategori menjadi leluhur dirinya sendiri).
+var ErrInvalidParent = errors.New("parent kategori not found or would create a cycle")
+
+// ErrOverlap dikembalikan jika periode budget bertabrakan dengan budget lain milik user.
+var ErrOverlap = errors.New("budget period overlaps an existing budget")
+
+// ErrAllocationExceedsBudget dikembalikan jika total alokasi melebihi budget pada mode 'cap'.
+var ErrAllocationExceedsBudget = errors.New("total allocations exceed budget amount")
+
+// ErrForbidden dikembalikan jika role user di household tidak mengizinkan aksi tersebut.
+// Handler dapat memeriksanya dengan errors.Is untuk mengirim respons 403.
+var ErrForbidden = errors.New("household role does not permit this action")
+
+// ErrLastOwner dikembalikan jika aksi akan membuat household tanpa owner.
+var ErrLastOwner = errors.New("household must keep at least one owner")
+
+// ErrInvitationInvalid dikembalikan jika undangan sudah kedaluwarsa, dicabut atau dipakai.
+var ErrInvitationInvalid = errors.New("invitation expired, revoked or already used")
+
+// ErrInsufficientSavings dikembalikan jika penarikan melebihi saldo tujuan tabungan.
+var ErrInsufficientSavings = errors.New("withdrawal exceeds saved amount")
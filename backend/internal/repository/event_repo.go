@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// EventRepository menangani tabel events (log audit append-only).
+// Tidak ada method update/delete; tabelnya juga menolak keduanya lewat trigger.
+type EventRepository struct {
+	db *sql.DB
+}
+
+// NewEventRepository membuat instance EventRepository baru
+func NewEventRepository() *EventRepository {
+	return &EventRepository{db: db.DB}
+}
+
+// CreateEvent menambahkan satu event ke log audit
+func (r *EventRepository) CreateEvent(ctx context.Context, e *model.Event) error {
+	query := `
+		INSERT INTO events (id_actor, aksi, entitas, id_entitas, sebelum, sesudah, request_id, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id_event, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, e.ActorID, e.Action, e.Entity, e.EntityID,
+		nullableJSON(e.Before), nullableJSON(e.After), e.RequestID, e.IPAddress).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		log.Printf("Error inserting audit event: %v", err)
+		return fmt.Errorf("failed to save audit event: %w", err)
+	}
+	return nil
+}
+
+// eventFilterClause adalah kondisi WHERE bersama untuk daftar dan hitungan event;
+// parameter $1..$7 mengikuti urutan eventFilterArgs
+const eventFilterClause = `
+	WHERE ($1 = 0 OR e.id_actor = $1)
+	  AND ($2::text = '' OR e.aksi = $2)
+	  AND ($3::text = '' OR e.entitas = $3)
+	  AND ($4 = 0 OR e.id_entitas = $4)
+	  AND ($5::text = '' OR e.request_id = $5)
+	  AND ($6::timestamptz IS NULL OR e.created_at >= $6)
+	  AND ($7::timestamptz IS NULL OR e.created_at <= $7)`
+
+func eventFilterArgs(f model.EventFilter) []any {
+	return []any{f.ActorID, f.Action, f.Entity, f.EntityID, f.RequestID, nullableTime(f.From), nullableTime(f.To)}
+}
+
+// GetEvents mengambil event sesuai filter (terbaru lebih dulu) beserta jumlah totalnya
+// untuk pagination. Feed aktivitas user memakai filter ActorID; audit admin tanpa batas user.
+func (r *EventRepository) GetEvents(ctx context.Context, f model.EventFilter, limit int, offset int) ([]model.Event, int, error) {
+	query := `
+		SELECT e.id_event, e.id_actor, e.aksi, e.entitas, e.id_entitas, e.sebelum, e.sesudah,
+		       e.request_id, e.ip_address, e.created_at, COALESCE(u.username, ''),
+		       COUNT(*) OVER () AS total_rows
+		FROM events e
+		LEFT JOIN "User" u ON u.id_user = e.id_actor` + eventFilterClause + `
+		ORDER BY e.created_at DESC, e.id_event DESC
+		LIMIT $8 OFFSET $9`
+
+	args := append(eventFilterArgs(f), limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying audit events: %v", err)
+		return nil, 0, fmt.Errorf("failed to fetch audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		events []model.Event
+		total  int
+	)
+	for rows.Next() {
+		var e model.Event
+		var actorID, entityID sql.NullInt64
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &actorID, &e.Action, &e.Entity, &entityID, &before, &after,
+			&e.RequestID, &e.IPAddress, &e.CreatedAt, &e.ActorUsername, &total); err != nil {
+			log.Printf("Error scanning audit event row: %v", err)
+			continue
+		}
+		e.ActorID = nullableInt(actorID)
+		e.EntityID = nullableInt(entityID)
+		e.Before, e.After = before, after
+		events = append(events, e)
+	}
+	if rows.Err() != nil {
+		return nil, 0, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+
+	// Halaman di luar jangkauan tidak mengembalikan baris, hitung total secara terpisah
+	if len(events) == 0 && offset > 0 {
+		countQuery := `SELECT COUNT(*) FROM events e` + eventFilterClause
+		if err := r.db.QueryRowContext(ctx, countQuery, eventFilterArgs(f)...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+		}
+	}
+
+	return events, total, nil
+}
+
+// nullableJSON mengubah JSON kosong menjadi NULL di database
+func nullableJSON(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// nullableTime mengubah waktu kosong (zero value) menjadi NULL di database
+func nullableTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,517 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// HouseholdRepository menangani operasi database untuk 'households', anggota dan undangannya
+type HouseholdRepository struct {
+	db *sql.DB
+}
+
+// NewHouseholdRepository membuat instance HouseholdRepository baru
+func NewHouseholdRepository() *HouseholdRepository {
+	return &HouseholdRepository{db: db.DB}
+}
+
+// householdColumns dipakai bersama oleh query yang mengembalikan model.Household untuk user $1
+const householdColumns = `h.id_household, h.nama_household, h.created_by, h.created_at, m.role,
+	(SELECT COUNT(*) FROM household_members c WHERE c.id_household = h.id_household),
+	COALESCE(u.id_household_aktif = h.id_household, false)`
+
+const householdFrom = `
+	FROM households h
+	JOIN household_members m ON m.id_household = h.id_household AND m.id_user = $1
+	JOIN "User" u ON u.id_user = m.id_user`
+
+// CreateHousehold membuat household baru dengan userID sebagai owner lalu menjadikannya
+// household aktif user tersebut
+func (r *HouseholdRepository) CreateHousehold(ctx context.Context, userID int, name string) (*model.Household, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	h, err := createHousehold(ctx, tx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit household: %w", err)
+	}
+	return h, nil
+}
+
+// CreatePersonalHousehold membuat household pribadi untuk user baru ("Rumah <username>")
+func (r *HouseholdRepository) CreatePersonalHousehold(ctx context.Context, userID int, username string) (*model.Household, error) {
+	return r.CreateHousehold(ctx, userID, "Rumah "+username)
+}
+
+func createHousehold(ctx context.Context, tx *sql.Tx, userID int, name string) (*model.Household, error) {
+	h := &model.Household{Name: name, CreatedBy: &userID, Role: model.HouseholdRoleOwner, Members: 1, Active: true}
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO households (nama_household, created_by) VALUES ($1, $2) RETURNING id_household, created_at`,
+		name, userID).Scan(&h.ID, &h.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating household for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to create household: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO household_members (id_household, id_user, role) VALUES ($1, $2, $3)`,
+		h.ID, userID, model.HouseholdRoleOwner); err != nil {
+		log.Printf("Error adding owner to household %d: %v", h.ID, err)
+		return nil, fmt.Errorf("failed to add household owner: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE "User" SET id_household_aktif = $1 WHERE id_user = $2`, h.ID, userID); err != nil {
+		log.Printf("Error activating household %d for user %d: %v", h.ID, userID, err)
+		return nil, fmt.Errorf("failed to activate household: %w", err)
+	}
+	return h, nil
+}
+
+// GetActiveHousehold mengambil household aktif user beserta role-nya.
+// Mengembalikan ErrNotFound jika user tidak punya household aktif atau sudah bukan anggotanya.
+func (r *HouseholdRepository) GetActiveHousehold(ctx context.Context, userID int) (*model.Household, error) {
+	query := `SELECT ` + householdColumns + householdFrom + ` WHERE h.id_household = u.id_household_aktif`
+
+	h, err := scanHousehold(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error fetching active household of user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch active household: %w", err)
+	}
+	return h, nil
+}
+
+// EnsureActiveHousehold mengembalikan household aktif user. Jika user sudah dikeluarkan
+// dari household aktifnya, household lain tempat ia menjadi anggota diaktifkan; jika
+// tidak ada sama sekali, household pribadi baru dibuat.
+func (r *HouseholdRepository) EnsureActiveHousehold(ctx context.Context, userID int) (*model.Household, error) {
+	h, err := r.GetActiveHousehold(ctx, userID)
+	if err != ErrNotFound {
+		return h, err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE "User" SET id_household_aktif = (
+		     SELECT id_household FROM household_members WHERE id_user = $1 ORDER BY joined_at ASC, id_household ASC LIMIT 1
+		 ) WHERE id_user = $1`, userID)
+	if err != nil {
+		log.Printf("Error reactivating household for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to activate household: %w", err)
+	}
+	if err := expectAffected(result); err != nil {
+		return nil, err
+	}
+	if h, err = r.GetActiveHousehold(ctx, userID); err != ErrNotFound {
+		return h, err
+	}
+
+	var username string
+	if err := r.db.QueryRowContext(ctx, `SELECT username FROM "User" WHERE id_user = $1`, userID).Scan(&username); err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	return r.CreatePersonalHousehold(ctx, userID, username)
+}
+
+// GetHouseholdsByUserID mengambil semua household tempat user menjadi anggota
+func (r *HouseholdRepository) GetHouseholdsByUserID(ctx context.Context, userID int) ([]model.Household, error) {
+	query := `SELECT ` + householdColumns + householdFrom + ` ORDER BY h.nama_household ASC, h.id_household ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("Error querying households of user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch households: %w", err)
+	}
+	defer rows.Close()
+
+	households := []model.Household{}
+	for rows.Next() {
+		h, err := scanHousehold(rows)
+		if err != nil {
+			log.Printf("Error scanning household row: %v", err)
+			continue
+		}
+		households = append(households, *h)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return households, nil
+}
+
+// SetActiveHousehold memindahkan household aktif user. User harus anggota household tersebut.
+func (r *HouseholdRepository) SetActiveHousehold(ctx context.Context, userID int, householdID int) error {
+	query := `UPDATE "User" SET id_household_aktif = $2
+	          WHERE id_user = $1 AND EXISTS (
+	              SELECT 1 FROM household_members WHERE id_household = $2 AND id_user = $1
+	          )`
+
+	result, err := r.db.ExecContext(ctx, query, userID, householdID)
+	if err != nil {
+		log.Printf("Error activating household %d for user %d: %v", householdID, userID, err)
+		return fmt.Errorf("failed to activate household: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// RenameHousehold mengganti nama household. Hanya owner yang boleh.
+func (r *HouseholdRepository) RenameHousehold(ctx context.Context, householdID int, userID int, name string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := requireOwner(ctx, tx, householdID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE households SET nama_household = $1 WHERE id_household = $2`, name, householdID); err != nil {
+		log.Printf("Error renaming household %d: %v", householdID, err)
+		return fmt.Errorf("failed to rename household: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetMembers mengambil anggota household. User yang meminta harus anggota household tersebut.
+func (r *HouseholdRepository) GetMembers(ctx context.Context, householdID int, userID int) ([]model.HouseholdMember, error) {
+	query := `SELECT m.id_household, m.id_user, u.username, u.nama, m.role, m.joined_at
+	          FROM household_members m
+	          JOIN "User" u ON u.id_user = m.id_user
+	          WHERE m.id_household = $1
+	            AND EXISTS (SELECT 1 FROM household_members s WHERE s.id_household = $1 AND s.id_user = $2)
+	          ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, u.username ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID, userID)
+	if err != nil {
+		log.Printf("Error querying members of household %d: %v", householdID, err)
+		return nil, fmt.Errorf("failed to fetch household members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []model.HouseholdMember
+	for rows.Next() {
+		var m model.HouseholdMember
+		if err := rows.Scan(&m.HouseholdID, &m.UserID, &m.Username, &m.Name, &m.Role, &m.JoinedAt); err != nil {
+			log.Printf("Error scanning household member: %v", err)
+			continue
+		}
+		members = append(members, m)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	if len(members) == 0 {
+		return nil, ErrNotFound
+	}
+	return members, nil
+}
+
+// UpdateMemberRole mengubah role anggota. Hanya owner yang boleh, dan owner terakhir
+// tidak bisa diturunkan (ErrLastOwner).
+func (r *HouseholdRepository) UpdateMemberRole(ctx context.Context, householdID int, actorID int, memberID int, role string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := requireOwner(ctx, tx, householdID, actorID); err != nil {
+		return err
+	}
+	current, owners, err := lockMember(ctx, tx, householdID, memberID)
+	if err != nil {
+		return err
+	}
+	if current == model.HouseholdRoleOwner && role != model.HouseholdRoleOwner && owners <= 1 {
+		return ErrLastOwner
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE household_members SET role = $1 WHERE id_household = $2 AND id_user = $3`,
+		role, householdID, memberID); err != nil {
+		log.Printf("Error updating role of user %d in household %d: %v", memberID, householdID, err)
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RemoveMember mengeluarkan anggota dari household. Owner boleh mengeluarkan siapa pun;
+// anggota lain hanya boleh keluar sendiri. Owner terakhir tidak bisa keluar (ErrLastOwner).
+// Jika household itu sedang aktif bagi anggota tersebut, household lain miliknya diaktifkan.
+func (r *HouseholdRepository) RemoveMember(ctx context.Context, householdID int, actorID int, memberID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if actorID != memberID {
+		if err := requireOwner(ctx, tx, householdID, actorID); err != nil {
+			return err
+		}
+	}
+	current, owners, err := lockMember(ctx, tx, householdID, memberID)
+	if err != nil {
+		return err
+	}
+	if current == model.HouseholdRoleOwner && owners <= 1 {
+		return ErrLastOwner
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM household_members WHERE id_household = $1 AND id_user = $2`, householdID, memberID); err != nil {
+		log.Printf("Error removing user %d from household %d: %v", memberID, householdID, err)
+		return fmt.Errorf("failed to remove household member: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE "User" SET id_household_aktif = (
+		     SELECT id_household FROM household_members WHERE id_user = $1 ORDER BY joined_at ASC, id_household ASC LIMIT 1
+		 ) WHERE id_user = $1 AND id_household_aktif = $2`, memberID, householdID); err != nil {
+		log.Printf("Error switching active household of user %d: %v", memberID, err)
+		return fmt.Errorf("failed to switch active household: %w", err)
+	}
+	return tx.Commit()
+}
+
+// CreateInvitation membuat link undangan dengan role dan masa berlaku tertentu.
+// Hanya owner yang boleh. Token asli hanya dikembalikan di sini; database menyimpan hash-nya.
+func (r *HouseholdRepository) CreateInvitation(ctx context.Context, householdID int, actorID int, role string, expiresAt time.Time) (*model.HouseholdInvitation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := requireOwner(ctx, tx, householdID, actorID); err != nil {
+		return nil, err
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	inv := &model.HouseholdInvitation{HouseholdID: householdID, Role: role, CreatedBy: &actorID, ExpiresAt: expiresAt, Token: token}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO household_invitations (id_household, token_hash, role, created_by, expires_at)
+		 VALUES ($1, $2, $3, $4, $5) RETURNING id_undangan, created_at`,
+		householdID, hashSecretToken(token), role, actorID, expiresAt).Scan(&inv.ID, &inv.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating invitation for household %d: %v", householdID, err)
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// GetInvitations mengambil semua undangan household, terbaru lebih dulu. Hanya owner yang boleh.
+func (r *HouseholdRepository) GetInvitations(ctx context.Context, householdID int, actorID int) ([]model.HouseholdInvitation, error) {
+	if err := requireOwner(ctx, r.db, householdID, actorID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id_undangan, id_household, role, created_by, created_at, expires_at, accepted_by, accepted_at, revoked_at
+	          FROM household_invitations WHERE id_household = $1 ORDER BY created_at DESC, id_undangan DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID)
+	if err != nil {
+		log.Printf("Error querying invitations of household %d: %v", householdID, err)
+		return nil, fmt.Errorf("failed to fetch invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []model.HouseholdInvitation{}
+	for rows.Next() {
+		var inv model.HouseholdInvitation
+		var createdBy, acceptedBy sql.NullInt64
+		var acceptedAt, revokedAt sql.NullTime
+		if err := rows.Scan(&inv.ID, &inv.HouseholdID, &inv.Role, &createdBy, &inv.CreatedAt, &inv.ExpiresAt,
+			&acceptedBy, &acceptedAt, &revokedAt); err != nil {
+			log.Printf("Error scanning invitation row: %v", err)
+			continue
+		}
+		inv.CreatedBy = nullableInt(createdBy)
+		inv.AcceptedBy = nullableInt(acceptedBy)
+		if acceptedAt.Valid {
+			inv.AcceptedAt = &acceptedAt.Time
+		}
+		if revokedAt.Valid {
+			inv.RevokedAt = &revokedAt.Time
+		}
+		invitations = append(invitations, inv)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation mencabut undangan yang belum dipakai. Hanya owner yang boleh.
+func (r *HouseholdRepository) RevokeInvitation(ctx context.Context, householdID int, invitationID int, actorID int) error {
+	if err := requireOwner(ctx, r.db, householdID, actorID); err != nil {
+		return err
+	}
+
+	query := `UPDATE household_invitations SET revoked_at = NOW()
+	          WHERE id_undangan = $1 AND id_household = $2 AND accepted_at IS NULL AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, invitationID, householdID)
+	if err != nil {
+		log.Printf("Error revoking invitation %d: %v", invitationID, err)
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// AcceptInvitation memakai token undangan: user menjadi anggota dengan role undangan
+// dan household itu menjadi household aktifnya. Setiap undangan hanya bisa dipakai sekali.
+// Mengembalikan ErrNotFound jika token tidak dikenal, ErrInvitationInvalid jika undangan
+// sudah kedaluwarsa/dicabut/dipakai, dan ErrConflict jika user sudah menjadi anggota.
+func (r *HouseholdRepository) AcceptInvitation(ctx context.Context, token string, userID int) (*model.Household, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var invitationID, householdID int
+	var role string
+	var valid bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT id_undangan, id_household, role,
+		        accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		 FROM household_invitations WHERE token_hash = $1 FOR UPDATE`,
+		hashSecretToken(token)).Scan(&invitationID, &householdID, &role, &valid)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error fetching invitation: %v", err)
+		return nil, fmt.Errorf("failed to fetch invitation: %w", err)
+	}
+	if !valid {
+		return nil, ErrInvitationInvalid
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO household_members (id_household, id_user, role) VALUES ($1, $2, $3)`,
+		householdID, userID, role); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrConflict
+		}
+		log.Printf("Error adding user %d to household %d: %v", userID, householdID, err)
+		return nil, fmt.Errorf("failed to join household: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE household_invitations SET accepted_by = $1, accepted_at = NOW() WHERE id_undangan = $2`,
+		userID, invitationID); err != nil {
+		log.Printf("Error marking invitation %d accepted: %v", invitationID, err)
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE "User" SET id_household_aktif = $1 WHERE id_user = $2`, householdID, userID); err != nil {
+		log.Printf("Error activating household %d for user %d: %v", householdID, userID, err)
+		return nil, fmt.Errorf("failed to activate household: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit invitation: %w", err)
+	}
+	return r.GetActiveHousehold(ctx, userID)
+}
+
+// queryRower adalah *sql.DB atau *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// requireOwner mengembalikan ErrNotFound jika user bukan anggota household,
+// atau ErrForbidden jika user anggota tetapi bukan owner
+func requireOwner(ctx context.Context, q queryRower, householdID int, userID int) error {
+	var role string
+	err := q.QueryRowContext(ctx,
+		`SELECT role FROM household_members WHERE id_household = $1 AND id_user = $2`,
+		householdID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error fetching role of user %d in household %d: %v", userID, householdID, err)
+		return fmt.Errorf("failed to fetch household role: %w", err)
+	}
+	if role != model.HouseholdRoleOwner {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// lockMember mengunci semua anggota household (agar cek owner terakhir tidak balapan)
+// lalu mengembalikan role anggota memberID dan jumlah owner saat ini
+func lockMember(ctx context.Context, tx *sql.Tx, householdID int, memberID int) (role string, owners int, err error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id_user, role FROM household_members WHERE id_household = $1 FOR UPDATE`, householdID)
+	if err != nil {
+		log.Printf("Error locking members of household %d: %v", householdID, err)
+		return "", 0, fmt.Errorf("failed to lock household members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var r string
+		if err := rows.Scan(&id, &r); err != nil {
+			return "", 0, fmt.Errorf("failed to scan household member: %w", err)
+		}
+		if r == model.HouseholdRoleOwner {
+			owners++
+		}
+		if id == memberID {
+			role = r
+		}
+	}
+	if rows.Err() != nil {
+		return "", 0, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	if role == "" {
+		return "", 0, ErrNotFound
+	}
+	return role, owners, nil
+}
+
+func scanHousehold(row rowScanner) (*model.Household, error) {
+	var h model.Household
+	var createdBy sql.NullInt64
+	if err := row.Scan(&h.ID, &h.Name, &createdBy, &h.CreatedAt, &h.Role, &h.Members, &h.Active); err != nil {
+		return nil, err
+	}
+	h.CreatedBy = nullableInt(createdBy)
+	return &h, nil
+}
+
+// newSecretToken membuat token acak 32 byte (hex), misal untuk link undangan atau tiket stream
+func newSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashSecretToken adalah bentuk token yang disimpan di database
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -27,18 +27,24 @@ func (r *ItemRepository) CreateItem(ctx context.Context, item *model.Item) error
 	// ==================== PERBAIKAN DI SINI ====================
 	// Query sebelumnya hanya menyimpan 3 kolom.
 	// Query baru ini menyimpan semua 7 kolom yang relevan.
-	query := `INSERT INTO items (id_user, id_kategori, nama_item, jumlah_item, harga_satuan, total_harga, purchased_date)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
-
-	_, err := r.db.ExecContext(ctx, query,
-		item.UserID,        // $1
-		item.CategoryID,    // $2
-		item.ItemName,      // $3
-		item.Quantity,      // $4
-		item.UnitPrice,     // $5
-		item.TotalCost,     // $6
-		item.PurchasedDate, // $7
-	)
+	// Item masuk ke household aktif user; household_writable NULL (ditolak) untuk viewer
+	query := `INSERT INTO items (id_user, id_household, id_kategori, nama_item, jumlah_item, harga_satuan, total_harga, purchased_date, status)
+	          VALUES ($1, household_writable($1), $2, $3, $4, $5, $6, $7, $8) RETURNING id_item`
+
+	if item.Status == "" {
+		item.Status = model.ItemStatusPurchased
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		item.UserID,                         // $1
+		nullableCategoryID(item.CategoryID), // $2
+		item.ItemName,                       // $3
+		item.Quantity,                       // $4
+		item.UnitPrice,                      // $5
+		item.TotalCost,                      // $6
+		item.PurchasedDate,                  // $7
+		item.Status,                         // $8
+	).Scan(&item.ID)
 	// =========================================================
 
 	if err != nil {
@@ -51,7 +57,7 @@ func (r *ItemRepository) CreateItem(ctx context.Context, item *model.Item) error
 // GetItemsByUserID fetches all shopping items for a specific user within a timeframe (simple version)
 func (r *ItemRepository) GetItemsByUserID(ctx context.Context, userID int) ([]model.Item, error) {
 	// Query ini bisa dioptimalkan dengan filter tanggal di masa depan (TK4 Rework)
-	query := `SELECT id_item, id_kategori, nama_item, jumlah_item, harga_satuan,total_harga FROM items WHERE id_user = $1 ORDER BY purchased_date DESC`
+	query := `SELECT id_item, id_kategori, nama_item, jumlah_item, harga_satuan, total_harga, purchased_date, status FROM items WHERE id_household = household_of($1) AND deleted_at IS NULL ORDER BY purchased_date DESC`
 
 	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
@@ -63,19 +69,23 @@ func (r *ItemRepository) GetItemsByUserID(ctx context.Context, userID int) ([]mo
 	var items []model.Item
 	for rows.Next() {
 		var item model.Item
+		var categoryID sql.NullInt64 // NULL jika item tidak memiliki kategori
 		err := rows.Scan(
 			&item.ID,
-			&item.CategoryID,
+			&categoryID,
 
 			&item.ItemName,
 			&item.Quantity,
 			&item.UnitPrice,
-			&item.TotalCost, // Meskipun tidak diambil di query, siapkan untuk penggunaan di masa depan
+			&item.TotalCost,
+			&item.PurchasedDate,
+			&item.Status,
 		)
 		if err != nil {
 			log.Printf("Error scanning item row: %v", err)
 			continue
 		}
+		item.CategoryID = int(categoryID.Int64)
 		items = append(items, item)
 	}
 
@@ -86,24 +96,44 @@ func (r *ItemRepository) GetItemsByUserID(ctx context.Context, userID int) ([]mo
 	return items, nil
 }
 
+// GetItemByID mengambil satu item di household aktif user yang belum dihapus
+func (r *ItemRepository) GetItemByID(ctx context.Context, itemID int, userID int) (*model.Item, error) {
+	query := `SELECT id_item, id_user, id_kategori, nama_item, jumlah_item, harga_satuan, total_harga, purchased_date, status
+	          FROM items WHERE id_item = $1 AND id_household = household_of($2) AND deleted_at IS NULL`
+
+	var item model.Item
+	var categoryID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, itemID, userID).Scan(&item.ID, &item.UserID, &categoryID,
+		&item.ItemName, &item.Quantity, &item.UnitPrice, &item.TotalCost, &item.PurchasedDate, &item.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching item %d: %v", itemID, err)
+		return nil, fmt.Errorf("failed to fetch item: %w", err)
+	}
+	item.CategoryID = int(categoryID.Int64)
+	return &item, nil
+}
+
 // --- FUNGSI BARU YANG DIMINTA ---
 
-// GetTotalSpendingByDateRange menghitung total pengeluaran user dalam rentang waktu
+// GetTotalSpendingByDateRange menghitung total pengeluaran user dalam rentang tanggal (inklusif)
 // Fungsi ini dipanggil oleh DashboardHandler
 
 func (r *ItemRepository) GetTotalSpendingByDateRange(ctx context.Context, userID int, startDate time.Time, endDate time.Time) (float64, error) {
 	// COALESCE digunakan untuk memastikan 0 dikembalikan jika tidak ada data (SUM = NULL)
+	// Item yang masih 'planned' belum dihitung sebagai pengeluaran.
+	// purchased_date menyimpan jam, jadi dibandingkan per tanggal agar belanja di hari
+	// terakhir rentang ikut terhitung, sama seperti grafik harian dashboard.
 	query := `SELECT COALESCE(SUM(total_harga), 0) 
 	          FROM items 
-	          WHERE id_user = $1 AND purchased_date BETWEEN $2 AND $3`
+	          WHERE id_household = household_of($1) AND status = 'purchased' AND deleted_at IS NULL
+	            AND purchased_date::date BETWEEN $2::date AND $3::date`
 
 	var totalSpending float64
 
-	// Format tanggal ke string YYYY-MM-DD untuk query SQL
-	startDateStr := startDate.Format("2006-01-02")
-	endDateStr := endDate.Format("2006-01-02")
-
-	err := r.db.QueryRowContext(ctx, query, userID, startDateStr, endDateStr).Scan(&totalSpending)
+	err := r.db.QueryRowContext(ctx, query, userID, startDate, endDate).Scan(&totalSpending)
 	if err != nil {
 		// ErrNoRows tidak akan terjadi karena COALESCE, tapi kita tangani error lain
 		log.Printf("Error calculating total spending for user %d: %v", userID, err)
@@ -112,9 +142,25 @@ func (r *ItemRepository) GetTotalSpendingByDateRange(ctx context.Context, userID
 
 	return totalSpending, nil
 }
+
+// GetPlannedTotalByDateRange menghitung total item 'planned' (belum dibeli) dalam rentang tanggal (inklusif)
+func (r *ItemRepository) GetPlannedTotalByDateRange(ctx context.Context, userID int, startDate time.Time, endDate time.Time) (float64, error) {
+	query := `SELECT COALESCE(SUM(total_harga), 0)
+	          FROM items
+	          WHERE id_household = household_of($1) AND status = 'planned' AND deleted_at IS NULL
+	            AND purchased_date::date BETWEEN $2::date AND $3::date`
+
+	var total float64
+	if err := r.db.QueryRowContext(ctx, query, userID, startDate, endDate).Scan(&total); err != nil {
+		log.Printf("Error calculating planned total for user %d: %v", userID, err)
+		return 0, fmt.Errorf("failed to calculate planned total: %w", err)
+	}
+	return total, nil
+}
+
 func (r *ItemRepository) CategoryExists(ctx context.Context, categoryID int, userID int) (bool, error) {
 
-	query := `SELECT COUNT(1) FROM referensi_kategori WHERE id_kategori = $1 AND id_user = $2`
+	query := `SELECT COUNT(1) FROM referensi_kategori WHERE id_kategori = $1 AND id_household = household_of($2) AND deleted_at IS NULL`
 	var count int
 	err := r.db.QueryRowContext(ctx, query, categoryID, userID).Scan(&count)
 	if err != nil {
@@ -123,19 +169,25 @@ func (r *ItemRepository) CategoryExists(ctx context.Context, categoryID int, use
 	}
 	return count > 0, nil
 }
+
+// UpdateItem memperbarui item. Status kosong berarti status lama dipertahankan,
+// agar item 'planned' dari template tidak berubah menjadi 'purchased' tanpa sengaja.
+// Mengembalikan ErrNotFound jika item tidak ada di household user.
 func (r *ItemRepository) UpdateItem(ctx context.Context, item *model.Item) error {
 	query := `UPDATE items 
-	          SET id_kategori = $1, nama_item = $2, jumlah_item = $3, harga_satuan = $4, total_harga = $5, purchased_date = $6
-	          WHERE id_item = $7 AND id_user = $8`
+	          SET id_kategori = $1, nama_item = $2, jumlah_item = $3, harga_satuan = $4, total_harga = $5, purchased_date = $6,
+	              status = COALESCE(NULLIF($7, ''), status)
+	          WHERE id_item = $8 AND id_household = household_writable($9) AND deleted_at IS NULL`
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := r.db.ExecContext(ctx, query,
 
-		item.CategoryID,
+		nullableCategoryID(item.CategoryID),
 		item.ItemName,
 		item.Quantity,
 		item.UnitPrice,
 		item.TotalCost,
 		item.PurchasedDate,
+		item.Status,
 		item.ID,
 		item.UserID,
 	)
@@ -143,18 +195,82 @@ func (r *ItemRepository) UpdateItem(ctx context.Context, item *model.Item) error
 		log.Printf("Error updating item: %v", err)
 		return fmt.Errorf("failed to update item")
 	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		// Item sudah dihapus di antara pengecekan dan update, atau role user tidak boleh menulis
+		return ErrNotFound
+	}
 	return nil
 }
 
+// DeleteItem memindahkan item ke trash (soft delete).
+// Item dihapus permanen oleh retention job setelah periode retensi.
 func (r *ItemRepository) DeleteItem(ctx context.Context, itemID int, userID int) error {
-	query := `DELETE FROM items WHERE id_item = $1 AND id_user = $2`
-	_, err := r.db.ExecContext(ctx, query, itemID, userID)
+	query := `UPDATE items SET deleted_at = NOW() WHERE id_item = $1 AND id_household = household_writable($2) AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, itemID, userID)
 	if err != nil {
 		log.Printf("Error deleting item: %v", err)
 		return fmt.Errorf("failed to delete item")
 	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
 	return nil
 }
 
+// GetRecentItems mengambil item yang terakhir dicatat di household user (terbaru lebih dulu),
+// termasuk item yang masih 'planned', untuk daftar aktivitas terakhir di dasbor
+func (r *ItemRepository) GetRecentItems(ctx context.Context, userID int, limit int) ([]model.Item, error) {
+	query := `
+		SELECT i.id_item, i.id_user, i.id_kategori, i.nama_item, i.jumlah_item, i.harga_satuan, i.total_harga,
+		       i.purchased_date, i.status, rk.nama_kategori
+		FROM items i
+		LEFT JOIN referensi_kategori rk ON rk.id_kategori = i.id_kategori
+		WHERE i.id_household = household_of($1) AND i.deleted_at IS NULL
+		ORDER BY i.id_item DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		log.Printf("Error querying recent items for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch recent items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []model.Item{}
+	for rows.Next() {
+		var item model.Item
+		var categoryID sql.NullInt64
+		if err := rows.Scan(&item.ID, &item.UserID, &categoryID, &item.ItemName, &item.Quantity, &item.UnitPrice,
+			&item.TotalCost, &item.PurchasedDate, &item.Status, &item.CategoryName); err != nil {
+			log.Printf("Error scanning recent item row: %v", err)
+			continue
+		}
+		item.CategoryID = int(categoryID.Int64)
+		items = append(items, item)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return items, nil
+}
+
+// nullableCategoryID mengubah ID kategori 0 (tidak diisi) menjadi NULL di database
+func nullableCategoryID(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
 // Note: Repository untuk Budget, Category, dan Report akan dibuat di tahap selanjutnya
 // karena fokus awal adalah pada fitur dasar (Login/Register/Tambah Item) dan Rework Laporan.
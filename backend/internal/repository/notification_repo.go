@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/lib/pq"
+)
+
+// NotificationRepository menangani operasi database untuk 'notifications'
+// dan ambang peringatan budget ('anggaran.alert_thresholds', 'anggaran_alerts')
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository membuat instance NotificationRepository baru
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{db: db.DB}
+}
+
+// CreateNotification menyimpan notifikasi baru dan mengisi ID serta waktu pembuatannya
+func (r *NotificationRepository) CreateNotification(ctx context.Context, n *model.Notification) error {
+	return insertNotification(ctx, r.db, n)
+}
+
+func insertNotification(ctx context.Context, q queryRower, n *model.Notification) error {
+	query := `INSERT INTO notifications (id_user, tipe, judul, pesan, id_anggaran)
+	          VALUES ($1, $2, $3, $4, $5) RETURNING id_notifikasi, created_at`
+
+	err := q.QueryRowContext(ctx, query, n.UserID, n.Type, n.Title, n.Message, n.BudgetID).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating notification: %v", err)
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+	return nil
+}
+
+// GetNotifications mengambil notifikasi user (terbaru lebih dulu) beserta jumlah total untuk pagination.
+// Jika unreadOnly true, hanya notifikasi yang belum dibaca.
+func (r *NotificationRepository) GetNotifications(ctx context.Context, userID int, unreadOnly bool, limit int, offset int) ([]model.Notification, int, error) {
+	query := `SELECT id_notifikasi, id_user, tipe, judul, pesan, id_anggaran, is_read, created_at, read_at,
+	                 COUNT(*) OVER () AS total_rows
+	          FROM notifications
+	          WHERE id_user = $1 AND ($2 = false OR is_read = false)
+	          ORDER BY created_at DESC, id_notifikasi DESC
+	          LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		log.Printf("Error querying notifications for user %d: %v", userID, err)
+		return nil, 0, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		notifications []model.Notification
+		total         int
+	)
+	for rows.Next() {
+		var n model.Notification
+		var budgetID sql.NullInt64
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Message, &budgetID, &n.IsRead, &n.CreatedAt, &readAt, &total); err != nil {
+			log.Printf("Error scanning notification row: %v", err)
+			continue
+		}
+		if budgetID.Valid {
+			id := int(budgetID.Int64)
+			n.BudgetID = &id
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+	if rows.Err() != nil {
+		return nil, 0, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+
+	// Halaman di luar jangkauan tidak mengembalikan baris, hitung total secara terpisah
+	if len(notifications) == 0 && offset > 0 {
+		countQuery := `SELECT COUNT(*) FROM notifications WHERE id_user = $1 AND ($2 = false OR is_read = false)`
+		if err := r.db.QueryRowContext(ctx, countQuery, userID, unreadOnly).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+		}
+	}
+
+	return notifications, total, nil
+}
+
+// CountUnread menghitung notifikasi user yang belum dibaca
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE id_user = $1 AND is_read = false`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		log.Printf("Error counting unread notifications: %v", err)
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead menandai satu notifikasi milik user sebagai sudah dibaca
+func (r *NotificationRepository) MarkRead(ctx context.Context, notificationID int, userID int) error {
+	query := `UPDATE notifications SET is_read = true, read_at = COALESCE(read_at, NOW())
+	          WHERE id_notifikasi = $1 AND id_user = $2`
+
+	result, err := r.db.ExecContext(ctx, query, notificationID, userID)
+	if err != nil {
+		log.Printf("Error marking notification read: %v", err)
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkAllRead menandai semua notifikasi user sebagai sudah dibaca dan mengembalikan jumlahnya
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	query := `UPDATE notifications SET is_read = true, read_at = NOW() WHERE id_user = $1 AND is_read = false`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("Error marking all notifications read: %v", err)
+		return 0, fmt.Errorf("failed to update notifications: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetAlertThresholds mengambil ambang peringatan budget di household aktif user (urut naik)
+func (r *NotificationRepository) GetAlertThresholds(ctx context.Context, budgetID int, userID int) ([]int, error) {
+	query := `SELECT alert_thresholds FROM anggaran WHERE id_anggaran = $1 AND id_household = household_of($2) AND deleted_at IS NULL`
+
+	var thresholds pq.Int64Array
+	if err := r.db.QueryRowContext(ctx, query, budgetID, userID).Scan(&thresholds); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching alert thresholds for budget %d: %v", budgetID, err)
+		return nil, fmt.Errorf("failed to fetch alert thresholds: %w", err)
+	}
+
+	result := make([]int, len(thresholds))
+	for i, t := range thresholds {
+		result[i] = int(t)
+	}
+	return result, nil
+}
+
+// SetAlertThresholds mengganti ambang peringatan budget di household aktif user
+func (r *NotificationRepository) SetAlertThresholds(ctx context.Context, budgetID int, userID int, thresholds []int) error {
+	query := `UPDATE anggaran SET alert_thresholds = $1 WHERE id_anggaran = $2 AND id_household = household_writable($3) AND deleted_at IS NULL`
+
+	values := make(pq.Int64Array, len(thresholds))
+	for i, t := range thresholds {
+		values[i] = int64(t)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, values, budgetID, userID)
+	if err != nil {
+		log.Printf("Error updating alert thresholds for budget %d: %v", budgetID, err)
+		return fmt.Errorf("failed to update alert thresholds: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimThresholds mencatat ambang yang sudah terlewati untuk budget ini dan, dalam transaksi
+// yang sama, menyimpan notifikasi in-app untuk setiap anggota household pemilik budget.
+// Notifikasi dibuat oleh 'build' untuk ambang tertinggi yang baru tercatat; ambang yang sudah
+// pernah dicatat dilewati. Jika penyimpanan notifikasi gagal, klaim ambang ikut dibatalkan
+// sehingga peringatan dicoba lagi pada pemeriksaan berikutnya.
+// Mengembalikan notifikasi yang tersimpan (kosong jika tidak ada ambang baru).
+func (r *NotificationRepository) ClaimThresholds(ctx context.Context, budgetID int, thresholds []int, build func(threshold int) model.Notification) ([]model.Notification, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO anggaran_alerts (id_anggaran, threshold) VALUES ($1, $2)
+	          ON CONFLICT (id_anggaran, threshold) DO NOTHING`
+
+	highest := 0
+	for _, t := range thresholds {
+		result, err := tx.ExecContext(ctx, query, budgetID, t)
+		if err != nil {
+			log.Printf("Error claiming threshold %d for budget %d: %v", t, budgetID, err)
+			return nil, fmt.Errorf("failed to record budget alert: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check rows affected: %w", err)
+		}
+		if rowsAffected > 0 && t > highest {
+			highest = t
+		}
+	}
+	if highest == 0 {
+		return nil, tx.Commit()
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT m.id_user FROM household_members m
+		 JOIN anggaran a ON a.id_household = m.id_household
+		 WHERE a.id_anggaran = $1
+		 ORDER BY m.id_user`, budgetID)
+	if err != nil {
+		log.Printf("Error querying members for budget %d: %v", budgetID, err)
+		return nil, fmt.Errorf("failed to fetch household members: %w", err)
+	}
+	var members []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan household member: %w", err)
+		}
+		members = append(members, id)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+
+	notifications := make([]model.Notification, 0, len(members))
+	for _, memberID := range members {
+		n := build(highest)
+		n.UserID = memberID
+		if err := insertNotification(ctx, tx, &n); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit budget alert: %w", err)
+	}
+	return notifications, nil
+}
@@ -22,25 +22,50 @@ func NewReportRepository() *ReportRepository {
 }
 
 // GetSpendingByCategory menghitung total pengeluaran per kategori
-// Ini dipanggil oleh GetDashboardCharts untuk Pie Chart
-func (r *ReportRepository) GetSpendingByCategory(ctx context.Context, userID int, startDate time.Time, endDate time.Time) ([]model.SpendingByCategory, error) {
-	// (Query ini mengasumsikan Anda memiliki tabel 'referensi_kategori' sesuai ERD TK2)
+// Ini dipanggil oleh DashboardService.Charts untuk Pie Chart.
+//
+// 'depth' menentukan tingkat agregasi pada kategori bertingkat:
+// 0 = setiap kategori dihitung sendiri (tanpa rollup), 1 = digabung ke kategori teratas
+// (misal "Dapur"), 2 = digabung sampai tingkat kedua (misal "Dapur > Bumbu"), dst.
+func (r *ReportRepository) GetSpendingByCategory(ctx context.Context, userID int, startDate time.Time, endDate time.Time, depth int) ([]model.SpendingByCategory, error) {
+	// Recursive CTE 'tree' membangun path nama dari kategori teratas ke setiap kategori.
+	// Kategori yang parent-nya sudah dihapus diperlakukan sebagai kategori teratas.
 	query := `
+		WITH RECURSIVE tree AS (
+			SELECT rk.id_kategori, ARRAY[rk.nama_kategori::text] AS names
+			FROM referensi_kategori rk
+			WHERE rk.id_household = household_of($1) AND rk.deleted_at IS NULL
+			  AND NOT EXISTS (
+			      SELECT 1 FROM referensi_kategori p
+			      WHERE p.id_kategori = rk.id_parent AND p.deleted_at IS NULL
+			  )
+			UNION ALL
+			SELECT c.id_kategori, t.names || c.nama_kategori::text
+			FROM referensi_kategori c
+			JOIN tree t ON c.id_parent = t.id_kategori
+			WHERE c.deleted_at IS NULL AND array_length(t.names, 1) < 100
+		)
 		SELECT 
-			COALESCE(rk.nama_kategori, 'Tanpa Kategori') as nama_kategori, 
+			COALESCE(
+				CASE WHEN $4 > 0
+					THEN array_to_string(t.names[1:LEAST($4, array_length(t.names, 1))], ' > ')
+					ELSE t.names[array_length(t.names, 1)]
+				END,
+				'Tanpa Kategori'
+			) as nama_kategori, 
 			SUM(i.total_harga) as total
 		FROM 
 			items i
 		LEFT JOIN 
-			referensi_kategori rk ON i.id_kategori = rk.id_kategori
+			tree t ON i.id_kategori = t.id_kategori
 		WHERE 
-			i.id_user = $1 AND i.purchased_date BETWEEN $2 AND $3
+			i.id_household = household_of($1) AND i.status = 'purchased' AND i.deleted_at IS NULL AND i.purchased_date BETWEEN $2 AND $3
 		GROUP BY 
-			rk.nama_kategori
+			1
 		ORDER BY 
 			total DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate, depth)
 	if err != nil {
 		log.Printf("Error querying spending by category: %v", err)
 		return nil, fmt.Errorf("failed to get pie chart data: %w", err)
@@ -59,45 +84,79 @@ func (r *ReportRepository) GetSpendingByCategory(ctx context.Context, userID int
 	return results, rows.Err()
 }
 
-// GetSpendingByWeek menghitung total pengeluaran per minggu (4 minggu terakhir)
-// Ini dipanggil oleh GetDashboardCharts untuk Bar Chart
-func (r *ReportRepository) GetSpendingByWeek(ctx context.Context, userID int, numWeeks int) ([]model.SpendingByWeek, error) {
-	// Query ini spesifik untuk PostgreSQL (menggunakan TO_CHAR).
-	// Jika Anda menggunakan MySQL, ganti 'TO_CHAR' dengan 'WEEK' atau 'DATE_FORMAT'
-	// Jika Anda menggunakan SQLite, ini akan lebih rumit (perlu 'strftime')
-	// Asumsi PostgreSQL:
+// GetSpendingByDay menghitung total pengeluaran per hari dalam rentang tanggal (inklusif).
+// Hari tanpa belanja tidak dikembalikan.
+func (r *ReportRepository) GetSpendingByDay(ctx context.Context, userID int, startDate time.Time, endDate time.Time) ([]model.SpendingByDay, error) {
 	query := `
-		SELECT 
-			TO_CHAR(purchased_date, 'YYYY-WW') as minggu, 
-			SUM(total_harga) as total
-		FROM 
-			items
-		WHERE 
-			id_user = $1 AND purchased_date >= $2
-		GROUP BY 
-			minggu
-		ORDER BY 
-			minggu DESC
-		LIMIT $3`
-
-	// Tentukan tanggal mulai (misal: 4 minggu * 7 hari = 28 hari lalu)
-	startDate := time.Now().AddDate(0, 0, -(numWeeks * 7))
+		SELECT purchased_date::date AS tanggal, SUM(total_harga) AS total
+		FROM items
+		WHERE id_household = household_of($1) AND status = 'purchased' AND deleted_at IS NULL
+		  AND purchased_date::date BETWEEN $2::date AND $3::date
+		GROUP BY tanggal
+		ORDER BY tanggal ASC`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, startDate, numWeeks)
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
 	if err != nil {
-		log.Printf("Error querying spending by week: %v", err)
-		return nil, fmt.Errorf("failed to get bar chart data: %w", err)
+		log.Printf("Error querying spending by day: %v", err)
+		return nil, fmt.Errorf("failed to get daily spending: %w", err)
 	}
 	defer rows.Close()
 
-	var results []model.SpendingByWeek
+	var results []model.SpendingByDay
 	for rows.Next() {
-		var item model.SpendingByWeek
-		if err := rows.Scan(&item.MingguKe, &item.Total); err != nil {
-			log.Printf("Error scanning weekly spending: %v", err)
+		var item model.SpendingByDay
+		if err := rows.Scan(&item.Tanggal, &item.Total); err != nil {
+			log.Printf("Error scanning daily spending: %v", err)
 			continue
 		}
 		results = append(results, item)
 	}
 	return results, rows.Err()
 }
+
+// StreamPurchasedItems membaca item yang sudah dibeli dalam rentang tanggal (inklusif),
+// urut dari yang terlama, dan memanggil fn untuk setiap baris langsung dari cursor database
+// tanpa menampung seluruh hasil di memori. Iterasi berhenti jika fn mengembalikan error.
+func (r *ReportRepository) StreamPurchasedItems(ctx context.Context, userID int, startDate time.Time, endDate time.Time, fn func(model.ReportItem) error) error {
+	query := `
+		SELECT i.purchased_date, i.nama_item, COALESCE(rk.nama_kategori, 'Tanpa Kategori'),
+		       i.jumlah_item, i.harga_satuan, i.total_harga
+		FROM items i
+		LEFT JOIN referensi_kategori rk ON rk.id_kategori = i.id_kategori
+		WHERE i.id_household = household_of($1) AND i.status = 'purchased' AND i.deleted_at IS NULL
+		  AND i.purchased_date BETWEEN $2 AND $3
+		ORDER BY i.purchased_date ASC, i.id_item ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		log.Printf("Error querying report items: %v", err)
+		return fmt.Errorf("failed to get report items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.ReportItem
+		if err := rows.Scan(&item.Tanggal, &item.NamaItem, &item.Kategori, &item.Jumlah, &item.HargaSatuan, &item.Total); err != nil {
+			return fmt.Errorf("failed to scan report item: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetBudgetTotalByDateRange menjumlahkan budget dasar yang periodenya beririsan dengan rentang tanggal
+func (r *ReportRepository) GetBudgetTotalByDateRange(ctx context.Context, userID int, startDate time.Time, endDate time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(jumlah_anggaran), 0)
+		FROM anggaran
+		WHERE id_household = household_of($1) AND deleted_at IS NULL AND start_date <= $3 AND end_date >= $2`
+
+	var total float64
+	if err := r.db.QueryRowContext(ctx, query, userID, startDate, endDate).Scan(&total); err != nil {
+		log.Printf("Error querying budget total: %v", err)
+		return 0, fmt.Errorf("failed to get budget total: %w", err)
+	}
+	return total, nil
+}
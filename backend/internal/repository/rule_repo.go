@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// RuleRepository menangani operasi database untuk 'kategori_rules'
+// dan riwayat pemberian kategori yang dipakai oleh categorizer.
+type RuleRepository struct {
+	db *sql.DB
+}
+
+// NewRuleRepository membuat instance RuleRepository baru
+func NewRuleRepository() *RuleRepository {
+	return &RuleRepository{db: db.DB}
+}
+
+// CreateRule menyimpan aturan kategori baru di household user. Kategori harus aktif
+// dan berada di household yang sama; selain itu (termasuk role viewer) mengembalikan ErrNotFound.
+func (r *RuleRepository) CreateRule(ctx context.Context, rule *model.CategoryRule) error {
+	query := `INSERT INTO kategori_rules (id_user, id_household, id_kategori, match_type, pattern, priority)
+	          SELECT $1, rk.id_household, rk.id_kategori, $3, $4, $5
+	          FROM referensi_kategori rk
+	          WHERE rk.id_kategori = $2 AND rk.id_household = household_writable($1) AND rk.deleted_at IS NULL
+	          RETURNING id_rule, id_household`
+
+	err := r.db.QueryRowContext(ctx, query, rule.UserID, rule.CategoryID, rule.MatchType, rule.Pattern, rule.Priority).Scan(&rule.ID, &rule.HouseholdID)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error creating rule: %v", err)
+		return fmt.Errorf("failed to save rule: %w", err)
+	}
+	return nil
+}
+
+// GetRules mengambil aturan household aktif user yang kategorinya masih aktif
+func (r *RuleRepository) GetRules(ctx context.Context, userID int) ([]model.CategoryRule, error) {
+	query := `SELECT r.id_rule, r.id_user, r.id_household, r.id_kategori, r.match_type, r.pattern, r.priority
+	          FROM kategori_rules r
+	          JOIN referensi_kategori rk ON rk.id_kategori = r.id_kategori AND rk.deleted_at IS NULL
+	          WHERE r.id_household = household_of($1)
+	          ORDER BY r.priority DESC, r.id_rule ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("Error querying rules for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []model.CategoryRule
+	for rows.Next() {
+		var rule model.CategoryRule
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.HouseholdID, &rule.CategoryID, &rule.MatchType, &rule.Pattern, &rule.Priority); err != nil {
+			log.Printf("Error scanning rule row: %v", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return rules, nil
+}
+
+// UpdateRule memperbarui aturan di household user. rule.UserID adalah user yang mengubah;
+// pembuat aturan tidak ikut berubah. Kategori baru harus berada di household yang sama.
+func (r *RuleRepository) UpdateRule(ctx context.Context, rule *model.CategoryRule) error {
+	query := `UPDATE kategori_rules r SET id_kategori = $1, match_type = $2, pattern = $3, priority = $4
+	          FROM referensi_kategori rk
+	          WHERE r.id_rule = $5 AND r.id_household = household_writable($6)
+	            AND rk.id_kategori = $1 AND rk.id_household = r.id_household AND rk.deleted_at IS NULL
+	          RETURNING r.id_user, r.id_household`
+
+	err := r.db.QueryRowContext(ctx, query, rule.CategoryID, rule.MatchType, rule.Pattern, rule.Priority, rule.ID, rule.UserID).Scan(&rule.UserID, &rule.HouseholdID)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error updating rule: %v", err)
+		return fmt.Errorf("failed to update rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule menghapus aturan di household user
+func (r *RuleRepository) DeleteRule(ctx context.Context, ruleID int, userID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM kategori_rules WHERE id_rule = $1 AND id_household = household_writable($2)`, ruleID, userID)
+	if err != nil {
+		log.Printf("Error deleting rule: %v", err)
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetAssignmentHistory merangkum berapa kali setiap nama item diberi kategori tertentu
+// di household aktif user (hanya kategori yang masih aktif). Dibatasi pada nama yang paling sering dipakai.
+func (r *RuleRepository) GetAssignmentHistory(ctx context.Context, userID int) ([]model.CategoryAssignment, error) {
+	query := `SELECT LOWER(i.nama_item), i.id_kategori, COUNT(*) AS jumlah
+	          FROM items i
+	          JOIN referensi_kategori rk ON rk.id_kategori = i.id_kategori AND rk.deleted_at IS NULL
+	          WHERE i.id_household = household_of($1) AND i.deleted_at IS NULL
+	          GROUP BY LOWER(i.nama_item), i.id_kategori
+	          ORDER BY jumlah DESC
+	          LIMIT 1000`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("Error querying assignment history for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch assignment history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []model.CategoryAssignment
+	for rows.Next() {
+		var h model.CategoryAssignment
+		if err := rows.Scan(&h.ItemName, &h.CategoryID, &h.Count); err != nil {
+			log.Printf("Error scanning assignment history: %v", err)
+			continue
+		}
+		history = append(history, h)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return history, nil
+}
@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// SavingsRepository menangani operasi database untuk 'savings_goals' dan setorannya
+type SavingsRepository struct {
+	db *sql.DB
+}
+
+// NewSavingsRepository membuat instance SavingsRepository baru
+func NewSavingsRepository() *SavingsRepository {
+	return &SavingsRepository{db: db.DB}
+}
+
+// savingsGoalQuery mengambil goal beserta total setorannya
+const savingsGoalQuery = `
+	SELECT g.id_goal, g.id_household, g.id_user, g.nama_goal, g.target_amount, g.deadline, g.auto_sweep, g.created_at,
+	       COALESCE(SUM(c.jumlah), 0) AS terkumpul
+	FROM savings_goals g
+	LEFT JOIN savings_contributions c ON c.id_goal = g.id_goal`
+
+// CreateGoal menyimpan tujuan tabungan baru di household aktif user
+func (r *SavingsRepository) CreateGoal(ctx context.Context, g *model.SavingsGoal) error {
+	query := `INSERT INTO savings_goals (id_user, id_household, nama_goal, target_amount, deadline, auto_sweep)
+	          VALUES ($1, household_writable($1), $2, $3, $4, $5) RETURNING id_goal, id_household, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, g.UserID, g.Name, g.Target, g.Deadline, g.AutoSweep).Scan(&g.ID, &g.HouseholdID, &g.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating savings goal: %v", err)
+		return fmt.Errorf("failed to save savings goal: %w", err)
+	}
+	return nil
+}
+
+// GetGoalsByUserID mengambil semua tujuan tabungan di household aktif user
+func (r *SavingsRepository) GetGoalsByUserID(ctx context.Context, userID int) ([]model.SavingsGoal, error) {
+	return r.queryGoals(ctx, `WHERE g.id_household = household_of($1)`, userID)
+}
+
+// GetGoalsByHouseholdID mengambil semua tujuan tabungan household tanpa konteks user (scheduler)
+func (r *SavingsRepository) GetGoalsByHouseholdID(ctx context.Context, householdID int) ([]model.SavingsGoal, error) {
+	return r.queryGoals(ctx, `WHERE g.id_household = $1`, householdID)
+}
+
+func (r *SavingsRepository) queryGoals(ctx context.Context, where string, arg int) ([]model.SavingsGoal, error) {
+	query := savingsGoalQuery + `
+	` + where + `
+	GROUP BY g.id_goal
+	ORDER BY g.deadline ASC NULLS LAST, g.id_goal ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		log.Printf("Error querying savings goals: %v", err)
+		return nil, fmt.Errorf("failed to fetch savings goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []model.SavingsGoal
+	for rows.Next() {
+		g, err := scanSavingsGoal(rows)
+		if err != nil {
+			log.Printf("Error scanning savings goal: %v", err)
+			continue
+		}
+		goals = append(goals, *g)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return goals, nil
+}
+
+// GetGoalByID mengambil satu tujuan tabungan di household aktif user
+func (r *SavingsRepository) GetGoalByID(ctx context.Context, goalID int, userID int) (*model.SavingsGoal, error) {
+	query := savingsGoalQuery + `
+	WHERE g.id_goal = $1 AND g.id_household = household_of($2)
+	GROUP BY g.id_goal`
+
+	g, err := scanSavingsGoal(r.db.QueryRowContext(ctx, query, goalID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching savings goal %d: %v", goalID, err)
+		return nil, fmt.Errorf("failed to fetch savings goal: %w", err)
+	}
+	return g, nil
+}
+
+// UpdateGoal mengubah nama, target, deadline, dan auto sweep goal di household aktif user
+func (r *SavingsRepository) UpdateGoal(ctx context.Context, g *model.SavingsGoal) error {
+	query := `UPDATE savings_goals SET nama_goal = $1, target_amount = $2, deadline = $3, auto_sweep = $4
+	          WHERE id_goal = $5 AND id_household = household_writable($6)`
+
+	result, err := r.db.ExecContext(ctx, query, g.Name, g.Target, g.Deadline, g.AutoSweep, g.ID, g.UserID)
+	if err != nil {
+		log.Printf("Error updating savings goal: %v", err)
+		return fmt.Errorf("failed to update savings goal: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// DeleteGoal menghapus goal di household aktif user beserta seluruh setorannya
+func (r *SavingsRepository) DeleteGoal(ctx context.Context, goalID int, userID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM savings_goals WHERE id_goal = $1 AND id_household = household_writable($2)`, goalID, userID)
+	if err != nil {
+		log.Printf("Error deleting savings goal: %v", err)
+		return fmt.Errorf("failed to delete savings goal: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// AddContribution mencatat setoran manual ke goal di household aktif user
+func (r *SavingsRepository) AddContribution(ctx context.Context, userID int, c *model.SavingsContribution) error {
+	query := `INSERT INTO savings_contributions (id_goal, jumlah, sumber, catatan)
+	          SELECT id_goal, $1, $2, NULLIF($3, '') FROM savings_goals WHERE id_goal = $4 AND id_household = household_writable($5)
+	          RETURNING id_kontribusi, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, c.Amount, c.Source, c.Note, c.GoalID, userID).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		log.Printf("Error adding savings contribution: %v", err)
+		return fmt.Errorf("failed to save contribution: %w", err)
+	}
+	return nil
+}
+
+// Withdraw mencatat penarikan dari goal di household aktif user sebagai setoran negatif.
+// Goal dikunci selama transaksi agar dua penarikan paralel tidak membuat saldonya minus;
+// mengembalikan ErrInsufficientSavings jika jumlahnya melebihi saldo goal.
+func (r *SavingsRepository) Withdraw(ctx context.Context, userID int, c *model.SavingsContribution) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var goalID int
+	err = tx.QueryRowContext(ctx,
+		`SELECT id_goal FROM savings_goals WHERE id_goal = $1 AND id_household = household_writable($2) FOR UPDATE`,
+		c.GoalID, userID).Scan(&goalID)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error locking savings goal %d: %v", c.GoalID, err)
+		return fmt.Errorf("failed to fetch savings goal: %w", err)
+	}
+
+	var saved float64
+	if err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(jumlah), 0) FROM savings_contributions WHERE id_goal = $1`, goalID).Scan(&saved); err != nil {
+		return fmt.Errorf("failed to fetch saved amount: %w", err)
+	}
+	if c.Amount > saved {
+		return ErrInsufficientSavings
+	}
+
+	query := `INSERT INTO savings_contributions (id_goal, jumlah, sumber, catatan)
+	          VALUES ($1, $2, $3, NULLIF($4, '')) RETURNING id_kontribusi, created_at`
+	if err := tx.QueryRowContext(ctx, query, goalID, -c.Amount, model.ContributionWithdrawal, c.Note).Scan(&c.ID, &c.CreatedAt); err != nil {
+		log.Printf("Error saving withdrawal: %v", err)
+		return fmt.Errorf("failed to save withdrawal: %w", err)
+	}
+	c.Amount = -c.Amount
+	c.Source = model.ContributionWithdrawal
+
+	return tx.Commit()
+}
+
+// GetContributions mengambil riwayat setoran sebuah goal (terbaru lebih dulu)
+func (r *SavingsRepository) GetContributions(ctx context.Context, goalID int) ([]model.SavingsContribution, error) {
+	query := `SELECT id_kontribusi, id_goal, jumlah, sumber, id_anggaran, COALESCE(catatan, ''), created_at
+	          FROM savings_contributions WHERE id_goal = $1
+	          ORDER BY created_at DESC, id_kontribusi DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, goalID)
+	if err != nil {
+		log.Printf("Error querying contributions for goal %d: %v", goalID, err)
+		return nil, fmt.Errorf("failed to fetch contributions: %w", err)
+	}
+	defer rows.Close()
+
+	contributions := []model.SavingsContribution{}
+	for rows.Next() {
+		var c model.SavingsContribution
+		var budgetID sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.GoalID, &c.Amount, &c.Source, &budgetID, &c.Note, &c.CreatedAt); err != nil {
+			log.Printf("Error scanning contribution: %v", err)
+			continue
+		}
+		if budgetID.Valid {
+			id := int(budgetID.Int64)
+			c.BudgetID = &id
+		}
+		contributions = append(contributions, c)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return contributions, nil
+}
+
+// GetAverageDailySurplus menghitung rata-rata surplus harian (jumlah anggaran dikurangi belanja)
+// dari maksimal 'periods' budget terakhir yang sudah selesai sebelum 'before'.
+func (r *SavingsRepository) GetAverageDailySurplus(ctx context.Context, userID int, before time.Time, periods int) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(b.jumlah_anggaran - b.total) / NULLIF(SUM(b.hari), 0), 0)
+		FROM (
+			SELECT a.jumlah_anggaran,
+			       COALESCE(s.total, 0) AS total,
+			       (a.end_date::date - a.start_date::date + 1) AS hari
+			FROM anggaran a
+			LEFT JOIN LATERAL (
+				SELECT SUM(i.total_harga) AS total
+				FROM items i
+				WHERE i.id_household = a.id_household AND i.status = 'purchased' AND i.deleted_at IS NULL
+				  AND i.purchased_date BETWEEN a.start_date AND a.end_date
+			) s ON true
+			WHERE a.id_household = household_of($1) AND a.deleted_at IS NULL AND a.end_date < $2
+			ORDER BY a.start_date DESC
+			LIMIT $3
+		) b`
+
+	var surplus float64
+	if err := r.db.QueryRowContext(ctx, query, userID, before, periods).Scan(&surplus); err != nil {
+		log.Printf("Error calculating average surplus for user %d: %v", userID, err)
+		return 0, fmt.Errorf("failed to calculate average surplus: %w", err)
+	}
+	return surplus, nil
+}
+
+// GetAutoSweepHouseholdIDs mengambil household yang punya minimal satu goal auto-sweep
+func (r *SavingsRepository) GetAutoSweepHouseholdIDs(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT id_household FROM savings_goals WHERE auto_sweep = true`)
+	if err != nil {
+		log.Printf("Error querying auto sweep households: %v", err)
+		return nil, fmt.Errorf("failed to fetch auto sweep households: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan household id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetUnsweptClosedBudgetIDs mengambil budget household yang periodenya sudah selesai sebelum 'now',
+// belum pernah di-sweep, dan berakhir setelah goal auto-sweep pertama household dibuat
+// (urut dari yang paling lama). Surplus budget bersama hanya masuk ke goal household yang sama.
+func (r *SavingsRepository) GetUnsweptClosedBudgetIDs(ctx context.Context, householdID int, now time.Time) ([]int, error) {
+	query := `
+		SELECT a.id_anggaran
+		FROM anggaran a
+		WHERE a.id_household = $1 AND a.deleted_at IS NULL AND a.end_date < $2
+		  AND a.end_date >= (SELECT MIN(created_at) FROM savings_goals WHERE id_household = $1 AND auto_sweep = true)
+		  AND NOT EXISTS (SELECT 1 FROM anggaran_sweeps sw WHERE sw.id_anggaran = a.id_anggaran)
+		ORDER BY a.start_date ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, householdID, now)
+	if err != nil {
+		log.Printf("Error querying closed budgets for household %d: %v", householdID, err)
+		return nil, fmt.Errorf("failed to fetch closed budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan budget id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SweepBudget mencatat sweep untuk budget dan menyimpan setoran ke goal dalam satu transaksi.
+// Idempotent: jika budget sudah pernah di-sweep, tidak ada yang disimpan dan 'swept' bernilai false.
+func (r *SavingsRepository) SweepBudget(ctx context.Context, budgetID int, amounts map[int]float64) (swept bool, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var total float64
+	for _, amount := range amounts {
+		total += amount
+	}
+
+	// Klaim budget ini. Jika baris sudah ada, budget sudah diproses.
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO anggaran_sweeps (id_anggaran, jumlah) VALUES ($1, $2) ON CONFLICT (id_anggaran) DO NOTHING`,
+		budgetID, total)
+	if err != nil {
+		log.Printf("Error claiming budget sweep: %v", err)
+		return false, fmt.Errorf("failed to record budget sweep: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	query := `INSERT INTO savings_contributions (id_goal, jumlah, sumber, id_anggaran) VALUES ($1, $2, $3, $4)`
+	for goalID, amount := range amounts {
+		if _, err := tx.ExecContext(ctx, query, goalID, amount, model.ContributionSweep, budgetID); err != nil {
+			log.Printf("Error inserting sweep contribution: %v", err)
+			return false, fmt.Errorf("failed to save sweep contribution: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit budget sweep: %w", err)
+	}
+	return true, nil
+}
+
+func scanSavingsGoal(row rowScanner) (*model.SavingsGoal, error) {
+	var g model.SavingsGoal
+	var deadline sql.NullTime
+	if err := row.Scan(&g.ID, &g.HouseholdID, &g.UserID, &g.Name, &g.Target, &deadline, &g.AutoSweep, &g.CreatedAt, &g.Saved); err != nil {
+		return nil, err
+	}
+	if deadline.Valid {
+		g.Deadline = &deadline.Time
+	}
+	return &g, nil
+}
+
+// expectAffected mengembalikan ErrNotFound jika query tidak mengubah baris apa pun
+func expectAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// SettingsRepository menangani operasi database untuk 'user_settings'
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+// NewSettingsRepository membuat instance SettingsRepository baru
+func NewSettingsRepository() *SettingsRepository {
+	return &SettingsRepository{db: db.DB}
+}
+
+// GetSettings mengambil pengaturan user. User yang belum menyimpan
+// pengaturan mendapat nilai bawaan.
+func (r *SettingsRepository) GetSettings(ctx context.Context, userID int) (*model.UserSettings, error) {
+	return getUserSettings(ctx, r.db, userID)
+}
+
+// SaveSettings menyimpan (insert atau update) pengaturan user
+func (r *SettingsRepository) SaveSettings(ctx context.Context, s *model.UserSettings) error {
+	query := `INSERT INTO user_settings (id_user, rollover_policy, rollover_cap, notify_email, timezone, week_start, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	          ON CONFLICT (id_user) DO UPDATE
+	          SET rollover_policy = EXCLUDED.rollover_policy,
+	              rollover_cap = EXCLUDED.rollover_cap,
+	              notify_email = EXCLUDED.notify_email,
+	              timezone = EXCLUDED.timezone,
+	              week_start = EXCLUDED.week_start,
+	              updated_at = NOW()`
+
+	if _, err := r.db.ExecContext(ctx, query, s.UserID, s.RolloverPolicy, s.RolloverCap, s.NotifyEmail, s.Timezone, s.WeekStart); err != nil {
+		log.Printf("Error saving settings for user %d: %v", s.UserID, err)
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
+
+// GetCalendar mengambil kalender (zona waktu dan awal minggu) milik user
+func (r *SettingsRepository) GetCalendar(ctx context.Context, userID int) (calendar.Calendar, error) {
+	return getUserCalendar(ctx, r.db, userID)
+}
+
+// GetHouseholdCalendar mengambil kalender periode budget bersama household
+// (pengaturan owner yang paling lama menjadi anggota), misalnya untuk scheduler
+func (r *SettingsRepository) GetHouseholdCalendar(ctx context.Context, householdID int) (calendar.Calendar, error) {
+	settings, err := getHouseholdSettings(ctx, r.db, householdID)
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+	return settings.Calendar(), nil
+}
+
+// getUserCalendar membaca kalender pribadi user (untuk data milik user sendiri, misal laporan email)
+func getUserCalendar(ctx context.Context, q *sql.DB, userID int) (calendar.Calendar, error) {
+	settings, err := getUserSettings(ctx, q, userID)
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+	return settings.Calendar(), nil
+}
+
+// getUserSettings dipakai bersama oleh repository lain yang perlu membaca pengaturan user
+func getUserSettings(ctx context.Context, q *sql.DB, userID int) (*model.UserSettings, error) {
+	query := `SELECT rollover_policy, rollover_cap, notify_email, timezone, week_start FROM user_settings WHERE id_user = $1`
+
+	settings := model.DefaultUserSettings(userID)
+	err := q.QueryRowContext(ctx, query, userID).Scan(
+		&settings.RolloverPolicy,
+		&settings.RolloverCap,
+		&settings.NotifyEmail,
+		&settings.Timezone,
+		&settings.WeekStart,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error fetching settings for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// getHouseholdSettings membaca pengaturan yang berlaku untuk data bersama household
+// (periode budget dan rollover): milik household_owner, sehingga semua anggota
+// melihat periode dan carry-over yang sama. Household tanpa owner memakai nilai bawaan.
+func getHouseholdSettings(ctx context.Context, q *sql.DB, householdID int) (*model.UserSettings, error) {
+	var ownerID sql.NullInt64
+	if err := q.QueryRowContext(ctx, `SELECT household_owner($1)`, householdID).Scan(&ownerID); err != nil {
+		log.Printf("Error resolving owner of household %d: %v", householdID, err)
+		return nil, fmt.Errorf("failed to resolve household owner: %w", err)
+	}
+	if !ownerID.Valid {
+		settings := model.DefaultUserSettings(0)
+		return &settings, nil
+	}
+	return getUserSettings(ctx, q, int(ownerID.Int64))
+}
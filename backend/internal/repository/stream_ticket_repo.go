@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+)
+
+// StreamTicketRepository menangani tabel 'stream_tickets'
+type StreamTicketRepository struct {
+	db *sql.DB
+}
+
+// NewStreamTicketRepository membuat instance StreamTicketRepository baru.
+func NewStreamTicketRepository() *StreamTicketRepository {
+	return &StreamTicketRepository{db: db.DB}
+}
+
+// CreateTicket membuat tiket stream untuk user yang berlaku selama ttl. Tiket asli
+// hanya dikembalikan di sini; database menyimpan hash-nya. Tiket kedaluwarsa milik
+// siapa pun ikut dibersihkan.
+func (r *StreamTicketRepository) CreateTicket(ctx context.Context, userID int, ttl time.Duration) (string, time.Time, error) {
+	ticket, err := newSecretToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM stream_tickets WHERE expires_at <= NOW()`); err != nil {
+		// Bukan error fatal, tiket lama hanya menumpuk sampai pembersihan berikutnya
+		log.Printf("Error purging expired stream tickets: %v", err)
+	}
+
+	var expiresAt time.Time
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO stream_tickets (ticket_hash, id_user, expires_at) VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')
+		 RETURNING expires_at`,
+		hashSecretToken(ticket), userID, ttl.Seconds()).Scan(&expiresAt)
+	if err != nil {
+		log.Printf("Error creating stream ticket: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to create stream ticket: %w", err)
+	}
+	return ticket, expiresAt, nil
+}
+
+// RedeemTicket memakai tiket stream dan mengembalikan pemiliknya. Tiket langsung
+// dihapus sehingga hanya bisa dipakai sekali. Mengembalikan ErrNotFound jika tiket
+// tidak dikenal, sudah dipakai atau kedaluwarsa.
+func (r *StreamTicketRepository) RedeemTicket(ctx context.Context, ticket string) (int, error) {
+	var userID int
+	var valid bool
+	err := r.db.QueryRowContext(ctx,
+		`DELETE FROM stream_tickets WHERE ticket_hash = $1 RETURNING id_user, expires_at > NOW()`,
+		hashSecretToken(ticket)).Scan(&userID, &valid)
+	if err == sql.ErrNoRows || (err == nil && !valid) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Error redeeming stream ticket: %v", err)
+		return 0, fmt.Errorf("failed to redeem stream ticket: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// reportJobLockNamespace adalah kunci pertama pg_try_advisory_xact_lock(int, int)
+// untuk kiriman laporan; kunci kedua adalah id_job
+const reportJobLockNamespace = 43
+
+// SubscriptionRepository menangani operasi database untuk 'report_subscriptions'
+// dan antrean kirimannya 'report_jobs'
+type SubscriptionRepository struct {
+	db *sql.DB
+}
+
+// NewSubscriptionRepository membuat instance SubscriptionRepository baru
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{db: db.DB}
+}
+
+const subscriptionColumns = `s.id_langganan, s.id_user, s.format, s.frekuensi, s.hari, to_char(s.jam, 'HH24:MI'),
+	s.aktif, s.next_run_at, s.last_run_at, s.created_at`
+
+// CreateSubscription menyimpan langganan laporan baru
+func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, s *model.ReportSubscription) error {
+	query := `INSERT INTO report_subscriptions (id_user, format, frekuensi, hari, jam, aktif, next_run_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id_langganan, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, s.UserID, s.Format, s.Frequency, s.Day, s.Clock, s.Active, s.NextRunAt).
+		Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating report subscription: %v", err)
+		return fmt.Errorf("failed to save report subscription: %w", err)
+	}
+	return nil
+}
+
+// GetSubscriptionsByUserID mengambil semua langganan laporan milik user
+func (r *SubscriptionRepository) GetSubscriptionsByUserID(ctx context.Context, userID int) ([]model.ReportSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM report_subscriptions s
+	          WHERE s.id_user = $1 ORDER BY s.id_langganan ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("Error querying report subscriptions for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch report subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []model.ReportSubscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			log.Printf("Error scanning report subscription: %v", err)
+			continue
+		}
+		subs = append(subs, *s)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return subs, nil
+}
+
+// GetSubscriptionByID mengambil satu langganan milik user
+func (r *SubscriptionRepository) GetSubscriptionByID(ctx context.Context, subID int, userID int) (*model.ReportSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM report_subscriptions s
+	          WHERE s.id_langganan = $1 AND s.id_user = $2`
+
+	s, err := scanSubscription(r.db.QueryRowContext(ctx, query, subID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error fetching report subscription %d: %v", subID, err)
+		return nil, fmt.Errorf("failed to fetch report subscription: %w", err)
+	}
+	return s, nil
+}
+
+// UpdateSubscription mengubah jadwal, format dan status aktif langganan milik user
+func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, s *model.ReportSubscription) error {
+	query := `UPDATE report_subscriptions
+	          SET format = $1, frekuensi = $2, hari = $3, jam = $4, aktif = $5, next_run_at = $6
+	          WHERE id_langganan = $7 AND id_user = $8`
+
+	result, err := r.db.ExecContext(ctx, query, s.Format, s.Frequency, s.Day, s.Clock, s.Active, s.NextRunAt, s.ID, s.UserID)
+	if err != nil {
+		log.Printf("Error updating report subscription: %v", err)
+		return fmt.Errorf("failed to update report subscription: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// DeleteSubscription menghapus langganan milik user beserta antrean kirimannya
+func (r *SubscriptionRepository) DeleteSubscription(ctx context.Context, subID int, userID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM report_subscriptions WHERE id_langganan = $1 AND id_user = $2`, subID, userID)
+	if err != nil {
+		log.Printf("Error deleting report subscription: %v", err)
+		return fmt.Errorf("failed to delete report subscription: %w", err)
+	}
+	return expectAffected(result)
+}
+
+// GetDueSubscriptions mengambil langganan aktif yang jadwal kirimnya sudah tiba
+func (r *SubscriptionRepository) GetDueSubscriptions(ctx context.Context, now time.Time) ([]model.ReportSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM report_subscriptions s
+	          WHERE s.aktif AND s.next_run_at <= $1 ORDER BY s.next_run_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		log.Printf("Error querying due report subscriptions: %v", err)
+		return nil, fmt.Errorf("failed to fetch due report subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []model.ReportSubscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			log.Printf("Error scanning report subscription: %v", err)
+			continue
+		}
+		subs = append(subs, *s)
+	}
+	return subs, rows.Err()
+}
+
+// EnqueueRun mencatat kiriman untuk jadwal s.NextRunAt ke report_jobs lalu memajukan
+// jadwal langganan ke nextRunAt, dalam satu transaksi. Jika replika lain sudah
+// menjadwalkannya lebih dulu, tidak ada yang berubah.
+func (r *SubscriptionRepository) EnqueueRun(ctx context.Context, s *model.ReportSubscription, nextRunAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO report_jobs (id_langganan, run_at) VALUES ($1, $2) ON CONFLICT (id_langganan, run_at) DO NOTHING`,
+		s.ID, s.NextRunAt)
+	if err != nil {
+		log.Printf("Error enqueueing report job: %v", err)
+		return fmt.Errorf("failed to enqueue report job: %w", err)
+	}
+
+	// Hanya maju jika jadwal belum diubah oleh replika lain atau oleh user
+	_, err = tx.ExecContext(ctx,
+		`UPDATE report_subscriptions SET next_run_at = $1 WHERE id_langganan = $2 AND next_run_at = $3`,
+		nextRunAt, s.ID, s.NextRunAt)
+	if err != nil {
+		log.Printf("Error advancing report subscription: %v", err)
+		return fmt.Errorf("failed to advance report subscription: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit report job: %w", err)
+	}
+	return nil
+}
+
+// GetPendingJobIDs mengambil ID kiriman yang belum selesai, terlama lebih dulu.
+// Kiriman 'sending' yang klaimnya sudah lewat ReportJobClaimTimeout ikut diambil.
+func (r *SubscriptionRepository) GetPendingJobIDs(ctx context.Context, limit int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id_job FROM report_jobs
+		 WHERE status = $1 OR (status = $2 AND claimed_at < $3)
+		 ORDER BY run_at ASC, id_job ASC LIMIT $4`,
+		model.ReportJobPending, model.ReportJobSending, time.Now().Add(-model.ReportJobClaimTimeout), limit)
+	if err != nil {
+		log.Printf("Error querying pending report jobs: %v", err)
+		return nil, fmt.Errorf("failed to fetch pending report jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan report job: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClaimJob menandai kiriman sebagai 'sending' dan menambah hitungan percobaan, dengan
+// jaminan hanya satu replika yang memprosesnya. Klaim dilakukan di transaksi pendek
+// yang memegang advisory lock; replika lain yang gagal mengambil kunci melewatinya, dan
+// status dibaca ulang setelah kunci didapat sehingga kiriman yang sudah diklaim atau
+// selesai tidak dijalankan lagi. Email dikirim pemanggil setelah klaim di-commit, lalu
+// hasilnya dicatat dengan FinishJob. Mengembalikan nil jika kiriman dilewati.
+func (r *SubscriptionRepository) ClaimJob(ctx context.Context, jobID int) (*model.ReportJob, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1, $2)`, reportJobLockNamespace, jobID).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to lock report job: %w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	query := `SELECT j.id_job, j.run_at, j.attempts, ` + subscriptionColumns + `
+	          FROM report_jobs j
+	          JOIN report_subscriptions s ON s.id_langganan = j.id_langganan
+	          WHERE j.id_job = $1 AND (j.status = $2 OR (j.status = $3 AND j.claimed_at < $4))`
+
+	var job model.ReportJob
+	var lastRun sql.NullTime
+	sub := &job.Subscription
+	err = tx.QueryRowContext(ctx, query, jobID, model.ReportJobPending, model.ReportJobSending,
+		time.Now().Add(-model.ReportJobClaimTimeout)).Scan(
+		&job.ID, &job.RunAt, &job.Attempts,
+		&sub.ID, &sub.UserID, &sub.Format, &sub.Frequency, &sub.Day, &sub.Clock,
+		&sub.Active, &sub.NextRunAt, &lastRun, &sub.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil // Sudah diklaim atau diselesaikan replika lain
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch report job: %w", err)
+	}
+	if lastRun.Valid {
+		sub.LastRunAt = &lastRun.Time
+	}
+
+	// Klaim yang terhenti sudah memakai jatah percobaan terakhir: tandai gagal
+	if job.Attempts >= model.MaxReportJobAttempts {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE report_jobs SET status = $1, last_error = 'interrupted while sending', finished_at = NOW() WHERE id_job = $2`,
+			model.ReportJobFailed, jobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fail report job: %w", err)
+		}
+		return nil, tx.Commit()
+	}
+
+	err = tx.QueryRowContext(ctx,
+		`UPDATE report_jobs SET status = $1, attempts = attempts + 1, claimed_at = NOW()
+		 WHERE id_job = $2 RETURNING attempts, claimed_at`,
+		model.ReportJobSending, jobID).Scan(&job.Attempts, &job.ClaimedAt)
+	if err != nil {
+		log.Printf("Error claiming report job %d: %v", jobID, err)
+		return nil, fmt.Errorf("failed to claim report job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit report job claim: %w", err)
+	}
+	return &job, nil
+}
+
+// FinishJob mencatat hasil percobaan kiriman yang diklaim ClaimJob. Jika runErr tidak nil,
+// kiriman dicoba lagi pada putaran berikutnya sampai MaxReportJobAttempts. Hasil hanya
+// dicatat jika klaim masih milik pemanggil (belum diambil ulang karena timeout).
+func (r *SubscriptionRepository) FinishJob(ctx context.Context, job *model.ReportJob, runErr error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var result sql.Result
+	if runErr != nil {
+		status := job.StatusAfterFailure()
+		result, err = tx.ExecContext(ctx,
+			`UPDATE report_jobs SET last_error = $1, status = $2, claimed_at = NULL,
+			        finished_at = CASE WHEN $2 = 'failed' THEN NOW() END
+			 WHERE id_job = $3 AND status = $4 AND claimed_at = $5`,
+			runErr.Error(), status, job.ID, model.ReportJobSending, job.ClaimedAt)
+	} else {
+		result, err = tx.ExecContext(ctx,
+			`UPDATE report_jobs SET last_error = NULL, status = $1, claimed_at = NULL, finished_at = NOW()
+			 WHERE id_job = $2 AND status = $3 AND claimed_at = $4`,
+			model.ReportJobDone, job.ID, model.ReportJobSending, job.ClaimedAt)
+		if err == nil {
+			_, err = tx.ExecContext(ctx,
+				`UPDATE report_subscriptions SET last_run_at = $1 WHERE id_langganan = $2`, job.RunAt, job.Subscription.ID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return fmt.Errorf("report job %d claim expired: %w", job.ID, err)
+	}
+	return tx.Commit()
+}
+
+func scanSubscription(row rowScanner) (*model.ReportSubscription, error) {
+	var s model.ReportSubscription
+	var lastRun sql.NullTime
+	if err := row.Scan(&s.ID, &s.UserID, &s.Format, &s.Frequency, &s.Day, &s.Clock,
+		&s.Active, &s.NextRunAt, &lastRun, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if lastRun.Valid {
+		s.LastRunAt = &lastRun.Time
+	}
+	return &s, nil
+}
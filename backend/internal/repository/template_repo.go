@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// TemplateRepository menangani operasi database untuk 'item_templates'
+type TemplateRepository struct {
+	db *sql.DB
+}
+
+// NewTemplateRepository membuat instance TemplateRepository baru
+func NewTemplateRepository() *TemplateRepository {
+	return &TemplateRepository{db: db.DB}
+}
+
+// CreateTemplate menyimpan template beserta seluruh entry-nya dalam satu transaksi
+func (r *TemplateRepository) CreateTemplate(ctx context.Context, t *model.ItemTemplate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO item_templates (id_user, id_household, nama_template, recurrence, start_date)
+	          VALUES ($1, household_writable($1), $2, $3, $4) RETURNING id_template`
+	if err := tx.QueryRowContext(ctx, query, t.UserID, t.Name, t.Recurrence, t.StartDate).Scan(&t.ID); err != nil {
+		log.Printf("Error creating template: %v", err)
+		return fmt.Errorf("failed to save template: %w", err)
+	}
+
+	if err := insertTemplateEntries(ctx, tx, t); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetTemplatesByUserID mengambil semua template di household aktif user beserta entry-nya
+func (r *TemplateRepository) GetTemplatesByUserID(ctx context.Context, userID int) ([]model.ItemTemplate, error) {
+	query := `SELECT id_template, id_household, id_user, nama_template, recurrence, start_date
+	          FROM item_templates WHERE id_household = household_of($1) ORDER BY nama_template ASC`
+
+	templates, err := r.queryTemplates(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if templates[i].Entries, err = r.getEntries(ctx, templates[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// GetTemplateByID mengambil satu template di household aktif user
+func (r *TemplateRepository) GetTemplateByID(ctx context.Context, templateID int, userID int) (*model.ItemTemplate, error) {
+	query := `SELECT id_template, id_household, id_user, nama_template, recurrence, start_date
+	          FROM item_templates WHERE id_template = $1 AND id_household = household_of($2)`
+
+	var t model.ItemTemplate
+	err := r.db.QueryRowContext(ctx, query, templateID, userID).Scan(&t.ID, &t.HouseholdID, &t.UserID, &t.Name, &t.Recurrence, &t.StartDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("template not found or user not authorized")
+		}
+		log.Printf("Error fetching template %d: %v", templateID, err)
+		return nil, fmt.Errorf("failed to fetch template: %w", err)
+	}
+
+	if t.Entries, err = r.getEntries(ctx, t.ID); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UpdateTemplate mengganti data template dan seluruh entry-nya
+func (r *TemplateRepository) UpdateTemplate(ctx context.Context, t *model.ItemTemplate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE item_templates SET nama_template = $1, recurrence = $2, start_date = $3
+	          WHERE id_template = $4 AND id_household = household_writable($5)`
+	result, err := tx.ExecContext(ctx, query, t.Name, t.Recurrence, t.StartDate, t.ID, t.UserID)
+	if err != nil {
+		log.Printf("Error updating template: %v", err)
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("template not found or user not authorized")
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_template_entries WHERE id_template = $1`, t.ID); err != nil {
+		log.Printf("Error clearing template entries: %v", err)
+		return fmt.Errorf("failed to update template entries: %w", err)
+	}
+	if err := insertTemplateEntries(ctx, tx, t); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteTemplate menghapus template di household aktif user (entry ikut terhapus via CASCADE)
+func (r *TemplateRepository) DeleteTemplate(ctx context.Context, templateID int, userID int) error {
+	query := `DELETE FROM item_templates WHERE id_template = $1 AND id_household = household_writable($2)`
+
+	result, err := r.db.ExecContext(ctx, query, templateID, userID)
+	if err != nil {
+		log.Printf("Error deleting template: %v", err)
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("template not found or user not authorized")
+	}
+	return nil
+}
+
+// ApplyTemplate membuat item 'planned' dari template untuk minggu (menurut kalender user)
+// yang memuat 'date'. Fungsi ini idempotent: jika template sudah pernah di-generate untuk
+// minggu tersebut, tidak ada item yang dibuat dan 'created' bernilai false.
+func (r *TemplateRepository) ApplyTemplate(ctx context.Context, t *model.ItemTemplate, cal calendar.Calendar, date time.Time) (created bool, err error) {
+	weekStart := cal.WeekStartOf(date)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Klaim minggu ini untuk template. Jika baris sudah ada, minggu ini sudah diproses.
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO item_template_runs (id_template, period_start) VALUES ($1, $2)
+		 ON CONFLICT (id_template, period_start) DO NOTHING`,
+		t.ID, weekStart)
+	if err != nil {
+		log.Printf("Error claiming template run: %v", err)
+		return false, fmt.Errorf("failed to record template run: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	// Item masuk ke household pemilik template, bukan household yang sedang aktif
+	query := `INSERT INTO items (id_user, id_household, id_kategori, nama_item, jumlah_item, harga_satuan, total_harga, purchased_date, status, id_template)
+	          VALUES ($1, (SELECT id_household FROM item_templates WHERE id_template = $9), $2, $3, $4, $5, $6, $7, $8, $9)`
+	for _, e := range t.Entries {
+		_, err := tx.ExecContext(ctx, query,
+			t.UserID,
+			e.CategoryID,
+			e.ItemName,
+			e.Quantity,
+			e.UnitPrice,
+			float64(e.Quantity)*e.UnitPrice,
+			weekStart,
+			model.ItemStatusPlanned,
+			t.ID,
+		)
+		if err != nil {
+			log.Printf("Error inserting planned item from template %d: %v", t.ID, err)
+			return false, fmt.Errorf("failed to create planned items: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit template run: %w", err)
+	}
+	return true, nil
+}
+
+// GenerateRecurring membuat item 'planned' untuk semua template berulang
+// yang jatuh tempo pada minggu yang memuat 'now'. Dipanggil oleh scheduler.
+func (r *TemplateRepository) GenerateRecurring(ctx context.Context, now time.Time) (int, error) {
+	query := `SELECT id_template, id_household, id_user, nama_template, recurrence, start_date
+	          FROM item_templates WHERE recurrence <> 'none' AND start_date <= $1`
+
+	templates, err := r.queryTemplates(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	// Minggu berjalan dihitung per household (pengaturan owner) karena zona waktu dan
+	// awal minggu bisa berbeda, sama seperti periode budget household
+	calendars := make(map[int]calendar.Calendar)
+	generated := 0
+	for i := range templates {
+		t := &templates[i]
+		cal, ok := calendars[t.HouseholdID]
+		if !ok {
+			settings, err := getHouseholdSettings(ctx, r.db, t.HouseholdID)
+			if err != nil {
+				return generated, err
+			}
+			cal = settings.Calendar()
+			calendars[t.HouseholdID] = cal
+		}
+
+		weekStart, weekEnd := cal.WeekRange(now)
+		if !t.IsDueInWeek(weekStart, weekEnd) {
+			continue
+		}
+		if t.Entries, err = r.getEntries(ctx, t.ID); err != nil {
+			return generated, err
+		}
+		created, err := r.ApplyTemplate(ctx, t, cal, now)
+		if err != nil {
+			return generated, err
+		}
+		if created {
+			generated++
+		}
+	}
+	return generated, nil
+}
+
+func (r *TemplateRepository) queryTemplates(ctx context.Context, query string, args ...any) ([]model.ItemTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error querying templates: %v", err)
+		return nil, fmt.Errorf("failed to fetch templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []model.ItemTemplate
+	for rows.Next() {
+		var t model.ItemTemplate
+		if err := rows.Scan(&t.ID, &t.HouseholdID, &t.UserID, &t.Name, &t.Recurrence, &t.StartDate); err != nil {
+			log.Printf("Error scanning template row: %v", err)
+			continue
+		}
+		templates = append(templates, t)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return templates, nil
+}
+
+func (r *TemplateRepository) getEntries(ctx context.Context, templateID int) ([]model.TemplateEntry, error) {
+	query := `SELECT id_entry, id_kategori, nama_item, jumlah_item, harga_satuan
+	          FROM item_template_entries WHERE id_template = $1 ORDER BY id_entry ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		log.Printf("Error querying template entries: %v", err)
+		return nil, fmt.Errorf("failed to fetch template entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []model.TemplateEntry{}
+	for rows.Next() {
+		var e model.TemplateEntry
+		var categoryID sql.NullInt64
+		if err := rows.Scan(&e.ID, &categoryID, &e.ItemName, &e.Quantity, &e.UnitPrice); err != nil {
+			log.Printf("Error scanning template entry: %v", err)
+			continue
+		}
+		if categoryID.Valid {
+			id := int(categoryID.Int64)
+			e.CategoryID = &id
+		}
+		entries = append(entries, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return entries, nil
+}
+
+func insertTemplateEntries(ctx context.Context, tx *sql.Tx, t *model.ItemTemplate) error {
+	query := `INSERT INTO item_template_entries (id_template, id_kategori, nama_item, jumlah_item, harga_satuan)
+	          VALUES ($1, $2, $3, $4, $5) RETURNING id_entry`
+	for i := range t.Entries {
+		e := &t.Entries[i]
+		if err := tx.QueryRowContext(ctx, query, t.ID, e.CategoryID, e.ItemName, e.Quantity, e.UnitPrice).Scan(&e.ID); err != nil {
+			log.Printf("Error inserting template entry: %v", err)
+			return fmt.Errorf("failed to save template entry: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/db"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// TrashRepository menangani data yang sudah di-soft delete (items, kategori, anggaran)
+type TrashRepository struct {
+	db *sql.DB
+}
+
+// NewTrashRepository membuat instance TrashRepository baru
+func NewTrashRepository() *TrashRepository {
+	return &TrashRepository{db: db.DB}
+}
+
+// trashTables memetakan jenis trash ke tabel dan kolom primary key-nya
+var trashTables = map[string]struct{ table, idColumn string }{
+	model.TrashTypeItem:     {"items", "id_item"},
+	model.TrashTypeCategory: {"referensi_kategori", "id_kategori"},
+	model.TrashTypeBudget:   {"anggaran", "id_anggaran"},
+}
+
+// GetTrashByUserID mengambil semua data household aktif user yang ada di trash, terbaru lebih dulu
+func (r *TrashRepository) GetTrashByUserID(ctx context.Context, userID int) ([]model.TrashEntry, error) {
+	query := `
+		SELECT 'item' AS type, id_item AS id, nama_item AS name, deleted_at
+		FROM items WHERE id_household = household_of($1) AND deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'kategori', id_kategori, nama_kategori, deleted_at
+		FROM referensi_kategori WHERE id_household = household_of($1) AND deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'budget', id_anggaran, TO_CHAR(start_date, 'YYYY-MM-DD') || ' s/d ' || TO_CHAR(end_date, 'YYYY-MM-DD'), deleted_at
+		FROM anggaran WHERE id_household = household_of($1) AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Printf("Error querying trash for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch trash: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []model.TrashEntry
+	for rows.Next() {
+		var e model.TrashEntry
+		if err := rows.Scan(&e.Type, &e.ID, &e.Name, &e.DeletedAt); err != nil {
+			log.Printf("Error scanning trash row: %v", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error during row iteration: %w", rows.Err())
+	}
+	return entries, nil
+}
+
+// Restore memulihkan satu baris dari trash household aktif user
+func (r *TrashRepository) Restore(ctx context.Context, entryType string, id int, userID int) error {
+	t, ok := trashTables[entryType]
+	if !ok {
+		return fmt.Errorf("unknown trash type %q", entryType)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Budget yang dipulihkan tidak boleh bertabrakan dengan budget aktif lain
+	if entryType == model.TrashTypeBudget {
+		budget := &model.Budget{ID: id, UserID: userID}
+		err := tx.QueryRowContext(ctx,
+			`SELECT start_date, end_date FROM anggaran WHERE id_anggaran = $1 AND id_household = household_writable($2) AND deleted_at IS NOT NULL`,
+			id, userID).Scan(&budget.StartDate, &budget.EndDate)
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch budget: %w", err)
+		}
+		if err := checkBudgetOverlap(ctx, tx, budget); err != nil {
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET deleted_at = NULL WHERE %s = $1 AND id_household = household_writable($2) AND deleted_at IS NOT NULL`, t.table, t.idColumn)
+	result, err := tx.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		log.Printf("Error restoring %s %d: %v", entryType, id, err)
+		return fmt.Errorf("failed to restore %s: %w", entryType, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
+
+// Purge menghapus permanen semua baris yang sudah di trash sebelum 'before'.
+// Kategori yang masih dipakai oleh item (termasuk item di trash) tidak ikut dihapus.
+func (r *TrashRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	queries := []string{
+		`DELETE FROM items WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		`DELETE FROM referensi_kategori rk WHERE rk.deleted_at IS NOT NULL AND rk.deleted_at < $1
+		 AND NOT EXISTS (SELECT 1 FROM items i WHERE i.id_kategori = rk.id_kategori)`,
+		`DELETE FROM anggaran WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+	}
+
+	var total int64
+	for _, query := range queries {
+		result, err := r.db.ExecContext(ctx, query, before)
+		if err != nil {
+			log.Printf("Error purging trash: %v", err)
+			return total, fmt.Errorf("failed to purge trash: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			total += n
+		}
+	}
+	return total, nil
+}
@@ -44,16 +44,40 @@ func (r *UserRepository) GetUserByUsername(ctx context.Context, username string)
 	return user, nil
 }
 
-// CreateUser saves a new user to the database
-func (r *UserRepository) CreateUser(ctx context.Context, req *model.RegisterRequest, hashedPassword string) error {
-	query := `INSERT INTO "User" (username, password, nama, email, role) VALUES ($1, $2, $3, $4, 'member')`
+// CreateUser saves a new user to the database and returns the new user ID
+func (r *UserRepository) CreateUser(ctx context.Context, req *model.RegisterRequest, hashedPassword string) (int, error) {
+	query := `INSERT INTO "User" (username, password, nama, email, role) VALUES ($1, $2, $3, $4, 'member') RETURNING id_user`
 
-	_, err := r.db.ExecContext(ctx, query, req.Username, hashedPassword, req.Name, req.Email)
+	var userID int
+	err := r.db.QueryRowContext(ctx, query, req.Username, hashedPassword, req.Name, req.Email).Scan(&userID)
 	if err != nil {
 		// Specific error handling for UNIQUE constraint violation (e.g., username/email already exists)
 		// This requires more complex error checking depending on the DB driver, but for simplicity:
 		log.Printf("Error creating user: %v", err)
-		return fmt.Errorf("failed to create user")
+		return 0, fmt.Errorf("failed to create user")
 	}
-	return nil
+	return userID, nil
+}
+
+// GetUserByID fetches a user by their ID
+func (r *UserRepository) GetUserByID(ctx context.Context, userID int) (*model.User, error) {
+	query := `SELECT id_user, username, password, nama, email, role FROM "User" WHERE id_user = $1`
+	user := new(model.User)
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Password,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		log.Printf("Error querying user by id: %v", err)
+		return nil, fmt.Errorf("database query error")
+	}
+	return user, nil
 }
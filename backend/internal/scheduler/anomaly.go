@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// AnomalyScanJob memeriksa ulang item yang dibeli beberapa hari terakhir, termasuk
+// item yang tidak lewat handler (misalnya hasil template berulang). Aman dijalankan
+// berkali-kali karena setiap anomali punya kunci unik.
+func AnomalyScanJob(svc *service.AnomalyService, interval time.Duration) Job {
+	return Job{
+		Name:     "anomaly-scan",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			created, err := svc.ScanRecent(ctx, time.Now().Add(-service.AnomalyScanLookback))
+			if err != nil {
+				return err
+			}
+			if created > 0 {
+				log.Printf("[Scheduler] %d anomali belanja baru ditandai", created)
+			}
+			return nil
+		},
+	}
+}
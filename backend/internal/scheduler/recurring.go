@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// RecurringItemsJob membuat item 'planned' dari template berulang
+// untuk minggu berjalan. Aman dijalankan berkali-kali (idempotent).
+func RecurringItemsJob(repo *repository.TemplateRepository, interval time.Duration) Job {
+	return Job{
+		Name:     "recurring-items",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			generated, err := repo.GenerateRecurring(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if generated > 0 {
+				log.Printf("[Scheduler] %d template berulang di-generate untuk minggu ini", generated)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// ReportMailJob mengirim laporan berkala sesuai langganan user. Aman dijalankan
+// di beberapa replika sekaligus: setiap kiriman dikunci di database (lihat
+// SubscriptionRepository.RunJob) sehingga hanya terkirim sekali.
+func ReportMailJob(svc *service.ReportMailService, interval time.Duration) Job {
+	return Job{
+		Name:     "report-mail",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			sent, err := svc.Run(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if sent > 0 {
+				log.Printf("[Scheduler] %d laporan terjadwal dikirim", sent)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/service"
+)
+
+// SavingsSweepJob menyisihkan surplus budget yang sudah tutup periode ke
+// tujuan tabungan auto-sweep. Aman dijalankan berkali-kali (idempotent).
+func SavingsSweepJob(svc *service.SavingsService, interval time.Duration) Job {
+	return Job{
+		Name:     "savings-sweep",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			swept, err := svc.SweepClosedBudgets(ctx)
+			if err != nil {
+				return err
+			}
+			if swept > 0 {
+				log.Printf("[Scheduler] Surplus %d budget disisihkan ke tujuan tabungan", swept)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job adalah pekerjaan latar belakang yang dijalankan berkala oleh Scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler menjalankan Job secara berkala di dalam proses server.
+// Setiap Job dijalankan sekali saat Start, lalu setiap Interval.
+// Job harus idempotent karena bisa terpanggil ulang setelah restart.
+type Scheduler struct {
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+// New membuat Scheduler kosong
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register menambahkan Job ke scheduler. Harus dipanggil sebelum Start.
+func (s *Scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start menjalankan semua Job di goroutine masing-masing sampai ctx dibatalkan
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait menunggu semua Job selesai setelah ctx dibatalkan
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	log.Printf("[Scheduler] Job %q dimulai (interval %s)", job.Name, job.Interval)
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			log.Printf("[Scheduler] Job %q dihentikan", job.Name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Scheduler] Job %q panic: %v", job.Name, r)
+		}
+	}()
+	if err := job.Run(ctx); err != nil {
+		log.Printf("[Scheduler] Job %q gagal: %v", job.Name, err)
+	}
+}
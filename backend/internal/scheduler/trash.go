@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// TrashPurgeJob menghapus permanen data di trash yang lebih tua dari 'retention'.
+func TrashPurgeJob(repo *repository.TrashRepository, retention time.Duration, interval time.Duration) Job {
+	return Job{
+		Name:     "trash-purge",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			purged, err := repo.Purge(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				log.Printf("[Scheduler] %d baris trash dihapus permanen", purged)
+			}
+			return nil
+		},
+	}
+}
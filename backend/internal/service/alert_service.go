@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/notifier"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// AlertService memeriksa ambang peringatan budget dan memberi tahu semua anggota household.
+// Notifikasi in-app disimpan bersama klaim ambangnya, lalu diteruskan lewat channel
+// terdaftar (misal email). Setiap ambang hanya diberitahukan sekali per periode budget.
+type AlertService struct {
+	budgetRepo       *repository.BudgetRepository
+	notificationRepo *repository.NotificationRepository
+	userRepo         *repository.UserRepository
+	settingsRepo     *repository.SettingsRepository
+	channels         []notifier.Channel
+}
+
+// NewAlertService adalah constructor untuk AlertService.
+func NewAlertService(
+	budgetRepo *repository.BudgetRepository,
+	notificationRepo *repository.NotificationRepository,
+	userRepo *repository.UserRepository,
+	settingsRepo *repository.SettingsRepository,
+	channels ...notifier.Channel,
+) *AlertService {
+	return &AlertService{
+		budgetRepo:       budgetRepo,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		settingsRepo:     settingsRepo,
+		channels:         channels,
+	}
+}
+
+// CheckBudgetAt mengevaluasi ambang budget yang aktif pada 'date'.
+// Dipanggil setelah item dibuat, diubah, atau dihapus. Tidak ada budget bukan error.
+func (s *AlertService) CheckBudgetAt(ctx context.Context, userID int, date time.Time) error {
+	status, err := s.budgetRepo.GetBudgetStatusByDate(ctx, userID, date)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.checkBudget(ctx, userID, status)
+}
+
+// CheckBudget mengevaluasi ambang untuk satu budget, misalnya setelah ambangnya diubah
+func (s *AlertService) CheckBudget(ctx context.Context, budgetID int, userID int) error {
+	status, err := s.budgetRepo.GetBudgetStatusByID(ctx, budgetID, userID)
+	if err != nil {
+		return err
+	}
+	return s.checkBudget(ctx, userID, status)
+}
+
+// checkBudget mengklaim ambang yang terlewati dan memberi tahu anggota household.
+// Ambang dibaca lewat household aktif anggota yang memicu pemeriksaan (userID), bukan pembuat budget.
+func (s *AlertService) checkBudget(ctx context.Context, userID int, status *model.BudgetStatus) error {
+	thresholds, err := s.notificationRepo.GetAlertThresholds(ctx, status.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	// Klaim semua ambang yang terlewati; jika beberapa terlewati sekaligus,
+	// cukup kirim satu notifikasi untuk ambang tertinggi yang baru.
+	crossed := model.CrossedThresholds(thresholds, status.PercentUsed)
+	if len(crossed) == 0 {
+		return nil
+	}
+	notifications, err := s.notificationRepo.ClaimThresholds(ctx, status.ID, crossed, func(threshold int) model.Notification {
+		return *budgetThresholdNotification(status, threshold)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Notifikasi in-app sudah tersimpan; kegagalan channel lain tidak membatalkannya
+	var failed int
+	for i := range notifications {
+		if err := s.notify(ctx, &notifications[i]); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to deliver budget alert to %d member(s)", failed)
+	}
+	return nil
+}
+
+// notify meneruskan notifikasi ke penerimanya lewat semua channel. Kegagalan satu channel
+// tidak menghentikan channel lain.
+func (s *AlertService) notify(ctx context.Context, n *model.Notification) error {
+	if len(s.channels) == 0 {
+		return nil
+	}
+	user, err := s.userRepo.GetUserByID(ctx, n.UserID)
+	if err != nil {
+		return err
+	}
+	settings, err := s.settingsRepo.GetSettings(ctx, n.UserID)
+	if err != nil {
+		return err
+	}
+
+	recipient := notifier.Recipient{User: *user, Settings: *settings}
+	var failed []string
+	for _, ch := range s.channels {
+		if err := ch.Send(ctx, recipient, n); err != nil {
+			log.Printf("[AlertService] Gagal mengirim notifikasi via %s untuk user %d: %v", ch.Name(), n.UserID, err)
+			failed = append(failed, ch.Name())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to deliver notification via %v", failed)
+	}
+	return nil
+}
+
+func budgetThresholdNotification(status *model.BudgetStatus, threshold int) *model.Notification {
+	budgetID := status.ID
+	title := fmt.Sprintf("Budget sudah terpakai %d%%", threshold)
+	if threshold >= 100 {
+		title = "Budget sudah habis"
+	}
+	message := fmt.Sprintf("Belanja periode %s sudah mencapai %s dari budget %s (%.0f%%). Sisa budget: %s.",
+		helper.FormatPeriode(status.StartDate, status.EndDate),
+		helper.FormatRupiah(status.Spent),
+		helper.FormatRupiah(status.EffectiveAmount),
+		status.PercentUsed,
+		helper.FormatRupiah(status.Remaining),
+	)
+	return &model.Notification{
+		UserID:   status.UserID,
+		Type:     model.NotificationBudgetThreshold,
+		Title:    title,
+		Message:  message,
+		BudgetID: &budgetID,
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/anomaly"
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// AnomalyScanLookback adalah rentang item yang diperiksa ulang oleh batch malam.
+// Lebih dari sehari agar item yang dicatat saat batch sebelumnya gagal tetap terperiksa.
+const AnomalyScanLookback = 48 * time.Hour
+
+// AnomalyService menjalankan detektor anomali terhadap item belanja dan
+// mencatat hasilnya. Dipanggil setiap kali item ditulis dan oleh batch malam.
+type AnomalyService struct {
+	repo         *repository.AnomalyRepository
+	settingsRepo *repository.SettingsRepository
+}
+
+// NewAnomalyService adalah constructor untuk AnomalyService.
+func NewAnomalyService(repo *repository.AnomalyRepository, settingsRepo *repository.SettingsRepository) *AnomalyService {
+	return &AnomalyService{repo: repo, settingsRepo: settingsRepo}
+}
+
+// CheckItem memeriksa satu item: harga terhadap riwayatnya sendiri, kemungkinan
+// entri dobel, dan total minggu kategorinya. item.HouseholdID harus diisi: household
+// aktif saat dipanggil dari request, household item itu sendiri oleh batch malam.
+// Mengembalikan jumlah anomali baru.
+func (s *AnomalyService) CheckItem(ctx context.Context, cal calendar.Calendar, item *model.Item) (int, error) {
+	if item.ID == 0 || item.HouseholdID == 0 {
+		return 0, nil
+	}
+	if item.Status != model.ItemStatusPurchased {
+		// Item rencana belum dibelanjakan; tanda harga dan entri dobel lama tidak berlaku lagi
+		if err := s.repo.ClearOpenAnomaly(ctx, item.HouseholdID, priceKey(item.ID)); err != nil {
+			return 0, err
+		}
+		return 0, s.repo.ClearStaleDuplicates(ctx, item.HouseholdID, item.ID, nil)
+	}
+
+	created := 0
+	for _, check := range []func(context.Context, calendar.Calendar, *model.Item) (int, error){
+		s.checkPrice, s.checkDuplicate, s.checkCategoryWeek,
+	} {
+		n, err := check(ctx, cal, item)
+		if err != nil {
+			return created, err
+		}
+		created += n
+	}
+	return created, nil
+}
+
+// ScanRecent adalah batch malam: memeriksa ulang semua item yang dibeli sejak 'since'.
+// Kegagalan pada satu item tidak menghentikan item lain.
+func (s *AnomalyService) ScanRecent(ctx context.Context, since time.Time) (int, error) {
+	items, err := s.repo.GetPurchasedItemsSince(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	calendars := make(map[int]calendar.Calendar)
+	for i := range items {
+		if ctx.Err() != nil {
+			return created, ctx.Err()
+		}
+		item := &items[i]
+		cal, ok := calendars[item.HouseholdID]
+		if !ok {
+			cal, err = s.settingsRepo.GetHouseholdCalendar(ctx, item.HouseholdID)
+			if err != nil {
+				log.Printf("[AnomalyService] Gagal memuat kalender household %d: %v", item.HouseholdID, err)
+				cal = calendar.Default()
+			}
+			calendars[item.HouseholdID] = cal
+		}
+
+		n, err := s.CheckItem(ctx, cal, item)
+		if err != nil {
+			log.Printf("[AnomalyService] Gagal memeriksa item %d: %v", item.ID, err)
+			continue
+		}
+		created += n
+	}
+	return created, nil
+}
+
+// checkPrice menandai harga satuan yang jauh di atas harga item yang sama sebelumnya
+func (s *AnomalyService) checkPrice(ctx context.Context, cal calendar.Calendar, item *model.Item) (int, error) {
+	history, err := s.repo.GetPriceHistory(ctx, item.HouseholdID, anomaly.NormalizeName(item.ItemName), item.ID, anomaly.PriceHistoryLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	key := priceKey(item.ID)
+	res := anomaly.Score(history, item.UnitPrice)
+	if !res.Flagged {
+		return 0, s.repo.ClearOpenAnomaly(ctx, item.HouseholdID, key)
+	}
+
+	itemID := item.ID
+	return s.save(ctx, &model.Anomaly{
+		UserID:      item.UserID,
+		HouseholdID: item.HouseholdID,
+		Type:        model.AnomalyPrice,
+		ItemID:      &itemID,
+		Value:       item.UnitPrice,
+		Median:      res.Median,
+		Score:       res.Score,
+		Message: fmt.Sprintf("Harga %s %s jauh di atas biasanya (median %d pembelian terakhir %s)",
+			item.ItemName, helper.FormatRupiah(item.UnitPrice), len(history), helper.FormatRupiah(res.Median)),
+		Key: key,
+	})
+}
+
+// checkDuplicate menandai item lain dengan nama dan harga sama yang dicatat dalam
+// selang beberapa menit. Pasangan dicatat sekali, apa pun urutan pengecekannya.
+// Tanda dobel lama yang pasangannya tidak lagi cocok (item diubah) dihapus.
+func (s *AnomalyService) checkDuplicate(ctx context.Context, cal calendar.Calendar, item *model.Item) (int, error) {
+	candidates, at, err := s.repo.GetItemsNear(ctx, item.HouseholdID, anomaly.NormalizeName(item.ItemName), item.ID, anomaly.DuplicateWindow)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	var keys []string
+	self := anomaly.Entry{Name: item.ItemName, UnitPrice: item.UnitPrice, Time: at}
+	for _, other := range candidates {
+		if !anomaly.IsDuplicate(self, anomaly.Entry{Name: other.ItemName, UnitPrice: other.UnitPrice, Time: other.PurchasedDate}) {
+			continue
+		}
+
+		// Item yang lebih baru ditandai sebagai kemungkinan dobel dari item yang lebih lama
+		first, second := other.ID, item.ID
+		if first > second {
+			first, second = second, first
+		}
+		key := fmt.Sprintf("duplicate:%d:%d", first, second)
+		keys = append(keys, key)
+		n, err := s.save(ctx, &model.Anomaly{
+			UserID:        item.UserID,
+			HouseholdID:   item.HouseholdID,
+			Type:          model.AnomalyDuplicate,
+			ItemID:        &second,
+			RelatedItemID: &first,
+			Value:         item.UnitPrice,
+			Median:        item.UnitPrice,
+			Message: fmt.Sprintf("%s %s dicatat dua kali dalam %d menit, kemungkinan entri dobel",
+				item.ItemName, helper.FormatRupiah(item.UnitPrice), int(anomaly.DuplicateWindow.Minutes())),
+			Key: key,
+		})
+		if err != nil {
+			return created, err
+		}
+		created += n
+	}
+	return created, s.repo.ClearStaleDuplicates(ctx, item.HouseholdID, item.ID, keys)
+}
+
+// checkCategoryWeek menandai minggu yang total belanja kategorinya jauh di atas
+// minggu-minggu sebelumnya. Kategori yang jarang dibeli (kurang dari
+// anomaly.MinHistory minggu aktif di jendela) dilewati agar tidak berisik.
+func (s *AnomalyService) checkCategoryWeek(ctx context.Context, cal calendar.Calendar, item *model.Item) (int, error) {
+	if item.CategoryID == 0 {
+		return 0, nil
+	}
+
+	day, err := s.repo.GetPurchaseDay(ctx, item.ID, item.HouseholdID)
+	if err != nil {
+		return 0, err
+	}
+	weekStart := cal.WeekStartOf(time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, cal.Location()))
+	windowStart := weekStart.AddDate(0, 0, -7*anomaly.CategoryWeeks)
+	totals, err := s.repo.GetCategoryWeekTotals(ctx, item.HouseholdID, item.CategoryID, windowStart, weekStart.AddDate(0, 0, 7), cal)
+	if err != nil {
+		return 0, err
+	}
+
+	history := make([]float64, 0, anomaly.CategoryWeeks)
+	active := 0
+	for i := 0; i < anomaly.CategoryWeeks; i++ {
+		total := totals[windowStart.AddDate(0, 0, 7*i).Format(calendar.DateLayout)]
+		if total > 0 {
+			active++
+		}
+		history = append(history, total)
+	}
+	current := totals[weekStart.Format(calendar.DateLayout)]
+
+	key := fmt.Sprintf("category_week:%d:%s", item.CategoryID, weekStart.Format(calendar.DateLayout))
+	res := anomaly.Score(history, current)
+	if active < anomaly.MinHistory || !res.Flagged {
+		return 0, s.repo.ClearOpenAnomaly(ctx, item.HouseholdID, key)
+	}
+
+	categoryID := item.CategoryID
+	return s.save(ctx, &model.Anomaly{
+		UserID:      item.UserID,
+		HouseholdID: item.HouseholdID,
+		Type:        model.AnomalyCategoryWeek,
+		CategoryID:  &categoryID,
+		WeekStart:   &weekStart,
+		Value:       current,
+		Median:      res.Median,
+		Score:       res.Score,
+		Message: fmt.Sprintf("Belanja kategori ini minggu %s sebesar %s, jauh di atas median %d minggu sebelumnya (%s)",
+			cal.WeekLabel(weekStart), helper.FormatRupiah(current), anomaly.CategoryWeeks, helper.FormatRupiah(res.Median)),
+		Key: key,
+	})
+}
+
+func (s *AnomalyService) save(ctx context.Context, a *model.Anomaly) (int, error) {
+	created, err := s.repo.SaveAnomaly(ctx, a)
+	if err != nil || !created {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func priceKey(itemID int) string {
+	return fmt.Sprintf("price:%d", itemID)
+}
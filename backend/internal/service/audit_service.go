@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gusti3111/TKBMG/backend/internal/audit"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// AuditService mencatat perubahan data dan login ke log audit (tabel events).
+type AuditService struct {
+	repo *repository.EventRepository
+}
+
+// NewAuditService adalah constructor untuk AuditService.
+func NewAuditService(repo *repository.EventRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record melengkapi e dengan diff 'before' dan 'after' lalu menyimpannya. 'before' nil untuk
+// create dan 'after' nil untuk delete. Update yang tidak mengubah apa pun tidak dicatat.
+// Penyimpanan tidak ikut dibatalkan jika request dibatalkan, karena perubahannya
+// sendiri sudah terjadi.
+func (s *AuditService) Record(ctx context.Context, e model.Event, before, after any) error {
+	var err error
+	e.Before, e.After, err = audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if e.Action == model.EventUpdate && e.Before == nil && e.After == nil {
+		return nil
+	}
+	return s.repo.CreateEvent(context.WithoutCancel(ctx), &e)
+}
@@ -15,14 +15,18 @@ import (
 
 // AuthService menangani logika bisnis terkait otentikasi
 type AuthService struct {
-	userRepo *repository.UserRepository
+	userRepo      *repository.UserRepository
+	categoryRepo  *repository.CategoryRepository
+	householdRepo *repository.HouseholdRepository
 }
 
 // NewAuthService adalah constructor untuk AuthService.
-// INI FUNGSI YANG HILANG.
 func NewAuthService() *AuthService {
-	// Kita asumsikan NewUserRepository() ada di paket repository Anda
-	return &AuthService{userRepo: repository.NewUserRepository()}
+	return &AuthService{
+		userRepo:      repository.NewUserRepository(),
+		categoryRepo:  repository.NewCategoryRepository(),
+		householdRepo: repository.NewHouseholdRepository(),
+	}
 }
 
 // === FUNGSI HELPER PASSWORD (HILANG) ===
@@ -53,8 +57,24 @@ func (s *AuthService) RegisterUser(ctx context.Context, req *model.RegisterReque
 		return fmt.Errorf("gagal memproses password")
 	}
 
-	// Kita asumsikan CreateUser ada di repo Anda
-	return s.userRepo.CreateUser(ctx, req, hashedPassword)
+	userID, err := s.userRepo.CreateUser(ctx, req, hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	// Household pribadi tempat item, kategori dan budget user disimpan. Jika gagal,
+	// HouseholdMiddleware akan membuatnya saat request pertama.
+	if _, err := s.householdRepo.CreatePersonalHousehold(ctx, userID, req.Username); err != nil {
+		log.Printf("Warning: gagal membuat household untuk user %d: %v", userID, err)
+		return nil
+	}
+
+	// Kategori bawaan agar user baru bisa langsung menambah item.
+	// Kegagalan seeding tidak membatalkan registrasi.
+	if err := s.categoryRepo.SeedDefaultKategori(ctx, userID, config.DefaultCategories); err != nil {
+		log.Printf("Warning: gagal membuat kategori bawaan untuk user %d: %v", userID, err)
+	}
+	return nil
 }
 
 // Login memvalidasi kredensial dan mengembalikan token JWT
@@ -88,7 +108,7 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 		return nil, fmt.Errorf("gagal membuat token")
 	}
 
-	return &model.LoginResponse{Token: tokenString, Role: user.Role}, nil
+	return &model.LoginResponse{Token: tokenString, Role: user.Role, UserID: user.ID}, nil
 }
 
 // GetUserByUsername mengambil data user (tanpa password)
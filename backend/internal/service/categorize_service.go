@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/categorizer"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// CategorizeService memuat aturan dan riwayat user lalu meneruskannya ke categorizer.
+// Semua jalur pembuatan item tanpa id_kategori harus melewati service ini.
+//
+// Aturan dimiliki household, jadi aturan yang sudah dikompilasi di-cache per household
+// dan dipakai bersama oleh semua anggota. Riwayat hanya dimuat jika tidak ada aturan yang cocok.
+type CategorizeService struct {
+	ruleRepo *repository.RuleRepository
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[int]cachedRules // id_household -> aturan
+	gen   uint64              // Naik setiap invalidasi, agar hasil muat yang sudah basi tidak disimpan
+}
+
+type cachedRules struct {
+	rules    *categorizer.RuleSet
+	loadedAt time.Time
+}
+
+// NewCategorizeService adalah constructor untuk CategorizeService. ttl membatasi umur
+// cache aturan; 0 berarti aturan selalu dimuat ulang.
+func NewCategorizeService(ruleRepo *repository.RuleRepository, ttl time.Duration) *CategorizeService {
+	return &CategorizeService{ruleRepo: ruleRepo, ttl: ttl, cache: make(map[int]cachedRules)}
+}
+
+// Suggest menebak kategori untuk nama item milik user di household aktifnya.
+// Mengembalikan nil (tanpa error) jika tidak ada aturan atau riwayat yang cocok.
+func (s *CategorizeService) Suggest(ctx context.Context, householdID int, userID int, itemName string) (*model.CategorySuggestion, error) {
+	rules, err := s.rules(ctx, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion := rules.Match(itemName); suggestion != nil {
+		return suggestion, nil
+	}
+
+	history, err := s.ruleRepo.GetAssignmentHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return categorizer.FromHistory(itemName, history), nil
+}
+
+// Apply mengisi CategoryID item jika masih kosong.
+// Mengembalikan saran yang dipakai, atau nil jika item sudah berkategori / tidak ada saran.
+func (s *CategorizeService) Apply(ctx context.Context, householdID int, item *model.Item) (*model.CategorySuggestion, error) {
+	if item.CategoryID != 0 {
+		return nil, nil
+	}
+	suggestion, err := s.Suggest(ctx, householdID, item.UserID, item.ItemName)
+	if err != nil || suggestion == nil {
+		return nil, err
+	}
+	item.CategoryID = suggestion.CategoryID
+	return suggestion, nil
+}
+
+// InvalidateHousehold membuang cache aturan household. Dipanggil setelah aturan
+// dibuat, diubah atau dihapus, dan setelah kategori household dihapus, digabung atau
+// dipulihkan, karena aturan yang menunjuk kategori di trash tidak dipakai.
+func (s *CategorizeService) InvalidateHousehold(householdID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	delete(s.cache, householdID)
+}
+
+func (s *CategorizeService) rules(ctx context.Context, householdID int, userID int) (*categorizer.RuleSet, error) {
+	s.mu.Lock()
+	entry, ok := s.cache[householdID]
+	gen := s.gen
+	s.mu.Unlock()
+	if ok && time.Since(entry.loadedAt) < s.ttl {
+		return entry.rules, nil
+	}
+
+	list, err := s.ruleRepo.GetRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entry = cachedRules{rules: categorizer.Compile(list), loadedAt: time.Now()}
+
+	s.mu.Lock()
+	if s.gen == gen {
+		s.cache[householdID] = entry
+	}
+	s.mu.Unlock()
+	return entry.rules, nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+	"golang.org/x/sync/errgroup"
+)
+
+// Batas granularitas otomatis: rentang sampai 14 hari per hari, sampai 93 hari per minggu,
+// selebihnya per bulan
+const (
+	autoDailyMaxDays  = 14
+	autoWeeklyMaxDays = 93
+)
+
+// DashboardService menyusun isi dasbor: ringkasan, chart, aktivitas terakhir dan progres
+// budget. Query yang tidak saling bergantung dijalankan bersamaan; jika salah satu gagal
+// atau request dibatalkan, sisanya ikut dihentikan lewat context.
+type DashboardService struct {
+	itemRepo   *repository.ItemRepository
+	budgetRepo *repository.BudgetRepository
+	reportRepo *repository.ReportRepository
+}
+
+// NewDashboardService adalah constructor untuk DashboardService.
+func NewDashboardService(itemRepo *repository.ItemRepository, budgetRepo *repository.BudgetRepository, reportRepo *repository.ReportRepository) *DashboardService {
+	return &DashboardService{itemRepo: itemRepo, budgetRepo: budgetRepo, reportRepo: reportRepo}
+}
+
+// Resolve melengkapi From/To dan granularitas q. Untuk range 'budget' periode diambil dari
+// budget aktif (dikembalikan juga, nil jika belum ada) dan jatuh ke minggu berjalan jika
+// user belum punya budget. Granularitas kosong dipilih otomatis dari panjang rentang.
+func (s *DashboardService) Resolve(ctx context.Context, userID int, cal calendar.Calendar, q model.DashboardQuery) (model.DashboardQuery, *model.Budget, error) {
+	now := cal.Now()
+	var budget *model.Budget
+	switch q.Range {
+	case model.DashboardRangeCustom:
+		// From/To sudah diisi dan divalidasi oleh handler
+	case model.DashboardRangeBudget:
+		b, err := s.budgetRepo.GetBudgetByDate(ctx, userID, now)
+		switch {
+		case err == nil:
+			budget = b
+			// Tanggal budget dari database tidak membawa zona waktu; baca ulang di kalender user
+			q.From = time.Date(b.StartDate.Year(), b.StartDate.Month(), b.StartDate.Day(), 0, 0, 0, 0, cal.Location())
+			q.To = cal.EndOfDay(time.Date(b.EndDate.Year(), b.EndDate.Month(), b.EndDate.Day(), 12, 0, 0, 0, cal.Location()))
+		case errors.Is(err, repository.ErrNotFound):
+			q.From, q.To = cal.WeekRange(now)
+		default:
+			return q, nil, err
+		}
+	default:
+		q.From, q.To = dashboardWindow(cal, q.Range, now)
+	}
+
+	if q.Granularity == "" {
+		q.Granularity = autoGranularity(cal, q.From, q.To)
+	} else if CountPeriods(cal, q.From, q.To, q.Granularity) > MaxTrendPeriods {
+		// Periode budget custom bisa sangat panjang; turunkan resolusi daripada menolak request
+		q.Granularity = model.GranularityMonth
+	}
+	return q, budget, nil
+}
+
+// Summary menghitung total belanja dan sisa budget pada rentang q. Jika 'budget' diisi
+// (range 'budget'), dipakai budget efektif beserta carry-over dan alokasi per kategori;
+// selain itu budget adalah jumlah budget dasar yang periodenya beririsan dengan rentang.
+func (s *DashboardService) Summary(ctx context.Context, userID int, q model.DashboardQuery, budget *model.Budget) (*model.SummaryResponse, error) {
+	summary := &model.SummaryResponse{AlokasiKategori: []model.CategoryEnvelope{}}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		total, err := s.itemRepo.GetTotalSpendingByDateRange(ctx, userID, q.From, q.To)
+		summary.TotalBelanja = total
+		return err
+	})
+	if budget != nil {
+		summary.Budget = budget.EffectiveAmount
+		summary.CarryOver = budget.CarryOver
+		g.Go(func() error {
+			envelopes, err := s.budgetRepo.GetEnvelopeSummary(ctx, budget.ID, userID)
+			if err != nil {
+				// Alokasi hanya pelengkap; ringkasan tetap dikirim tanpa alokasi
+				log.Printf("[DashboardService] Gagal mengambil alokasi budget %d: %v", budget.ID, err)
+				return nil
+			}
+			summary.AlokasiKategori = envelopes.Envelopes
+			return nil
+		})
+	} else {
+		g.Go(func() error {
+			total, err := s.reportRepo.GetBudgetTotalByDateRange(ctx, userID, q.From, q.To)
+			summary.Budget = total
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	summary.SisaBudget = summary.Budget - summary.TotalBelanja
+	return summary, nil
+}
+
+// Charts menyusun pie chart per kategori dan bar chart tren belanja untuk rentang q,
+// serta chart alokasi untuk budget yang aktif di akhir rentang. 'depth' menentukan
+// tingkat rollup kategori bertingkat (lihat ReportRepository.GetSpendingByCategory).
+func (s *DashboardService) Charts(ctx context.Context, userID int, cal calendar.Calendar, q model.DashboardQuery, depth int) (*model.ChartResponse, error) {
+	charts := &model.ChartResponse{
+		PieChart:     []model.PieChartItem{},
+		BarChart:     []model.BarChartItem{},
+		AlokasiChart: []model.AllocationChartItem{},
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		categories, err := s.reportRepo.GetSpendingByCategory(ctx, userID, q.From, q.To, depth)
+		if err != nil {
+			return err
+		}
+		for _, c := range categories {
+			charts.PieChart = append(charts.PieChart, model.PieChartItem{Name: c.Kategori, Value: c.Total})
+		}
+		return nil
+	})
+	g.Go(func() error {
+		days, err := s.reportRepo.GetSpendingByDay(ctx, userID, q.From, q.To)
+		if err != nil {
+			return err
+		}
+		for _, p := range BucketSpending(cal, days, q.From, q.To, q.Granularity) {
+			charts.BarChart = append(charts.BarChart, model.BarChartItem{Name: p.Label, Pengeluaran: p.Total})
+		}
+		return nil
+	})
+	g.Go(func() error {
+		budget, err := s.budgetRepo.GetBudgetByDate(ctx, userID, q.To)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		envelopes, err := s.budgetRepo.GetEnvelopeSummary(ctx, budget.ID, userID)
+		if err != nil {
+			log.Printf("[DashboardService] Gagal mengambil chart alokasi budget %d: %v", budget.ID, err)
+			return nil
+		}
+		for _, env := range envelopes.Envelopes {
+			charts.AlokasiChart = append(charts.AlokasiChart, model.AllocationChartItem{
+				Name:         env.CategoryName,
+				Dialokasikan: env.Allocated,
+				Terpakai:     env.Spent,
+				Sisa:         env.Remaining,
+			})
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return charts, nil
+}
+
+// RecentActivity mengembalikan 'limit' item yang terakhir dicatat user
+func (s *DashboardService) RecentActivity(ctx context.Context, userID int, limit int) ([]model.Item, error) {
+	return s.itemRepo.GetRecentItems(ctx, userID, limit)
+}
+
+// BudgetProgress mengembalikan progres budget yang aktif hari ini; nil jika belum ada budget
+func (s *DashboardService) BudgetProgress(ctx context.Context, userID int, cal calendar.Calendar) (*model.BudgetProgress, error) {
+	now := cal.Now()
+	status, err := s.budgetRepo.GetBudgetStatusByDate(ctx, userID, now)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	progress := model.NewBudgetProgress(*status, now)
+	return &progress, nil
+}
+
+// Dashboard menyusun seluruh isi dasbor dalam satu panggilan. Setelah rentang ditentukan,
+// ringkasan, chart, aktivitas terakhir dan progres budget dimuat bersamaan.
+func (s *DashboardService) Dashboard(ctx context.Context, userID int, cal calendar.Calendar, q model.DashboardQuery, depth, recentLimit int) (*model.DashboardResponse, error) {
+	q, budget, err := s.Resolve(ctx, userID, cal, q)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.DashboardResponse{Periode: q}
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		summary, err := s.Summary(ctx, userID, q, budget)
+		if err == nil {
+			resp.Summary = *summary
+		}
+		return err
+	})
+	g.Go(func() error {
+		charts, err := s.Charts(ctx, userID, cal, q, depth)
+		if err == nil {
+			resp.Charts = *charts
+		}
+		return err
+	})
+	g.Go(func() error {
+		items, err := s.RecentActivity(ctx, userID, recentLimit)
+		resp.AktivitasTerakhir = items
+		return err
+	})
+	g.Go(func() error {
+		progress, err := s.BudgetProgress(ctx, userID, cal)
+		resp.ProgresBudget = progress
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// dashboardWindow menghitung rentang tetap (selain budget dan custom) sampai hari ini
+func dashboardWindow(cal calendar.Calendar, r string, now time.Time) (time.Time, time.Time) {
+	today := cal.StartOfDay(now)
+	from := today
+	switch r {
+	case model.DashboardRangeWeek:
+		from = cal.WeekStartOf(now)
+	case model.DashboardRangeMonth:
+		from = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	case model.DashboardRangeYear:
+		from = time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+	case model.DashboardRange7d:
+		from = today.AddDate(0, 0, -6)
+	case model.DashboardRange30d:
+		from = today.AddDate(0, 0, -29)
+	case model.DashboardRange90d:
+		from = today.AddDate(0, 0, -89)
+	}
+	return from, cal.EndOfDay(now)
+}
+
+// autoGranularity memilih granularitas bar chart sesuai panjang rentang
+func autoGranularity(cal calendar.Calendar, from, to time.Time) string {
+	days := int(cal.StartOfDay(to).Sub(cal.StartOfDay(from)).Hours()/24+0.5) + 1
+	switch {
+	case days <= autoDailyMaxDays:
+		return model.GranularityDay
+	case days <= autoWeeklyMaxDays:
+		return model.GranularityWeek
+	default:
+		return model.GranularityMonth
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/inflation"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// InflationService menghitung indeks harga pribadi dari riwayat belanja user.
+type InflationService struct {
+	analyticsRepo *repository.AnalyticsRepository
+}
+
+// NewInflationService adalah constructor untuk InflationService.
+func NewInflationService(analyticsRepo *repository.AnalyticsRepository) *InflationService {
+	return &InflationService{analyticsRepo: analyticsRepo}
+}
+
+// Index menghitung indeks untuk 'months' bulan yang berakhir di bulan tempat 'until'
+// jatuh (menurut kalender user); 'baseMonths' bulan pertama menjadi periode dasar.
+func (s *InflationService) Index(ctx context.Context, userID int, cal calendar.Calendar, until time.Time, months, baseMonths int) (*model.InflationIndex, error) {
+	local := until.In(cal.Location())
+	lastMonth := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, cal.Location())
+	start := lastMonth.AddDate(0, -(months - 1), 0)
+	end := cal.EndOfDay(lastMonth.AddDate(0, 1, -1))
+
+	purchases, err := s.analyticsRepo.GetProductMonths(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return inflation.Compute(inflation.Input{
+		Start:      start,
+		Months:     months,
+		BaseMonths: baseMonths,
+		Purchases:  purchases,
+	}), nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/helper"
+	"github.com/gusti3111/TKBMG/backend/internal/mailer"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/report"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// reportJobBatch adalah jumlah maksimal kiriman yang diproses per putaran scheduler
+const reportJobBatch = 50
+
+// reportJobStore adalah bagian SubscriptionRepository yang dipakai ReportMailService,
+// dipisah agar alur kiriman bisa diuji tanpa database
+type reportJobStore interface {
+	GetDueSubscriptions(ctx context.Context, now time.Time) ([]model.ReportSubscription, error)
+	EnqueueRun(ctx context.Context, s *model.ReportSubscription, nextRunAt time.Time) error
+	GetPendingJobIDs(ctx context.Context, limit int) ([]int, error)
+	ClaimJob(ctx context.Context, jobID int) (*model.ReportJob, error)
+	FinishJob(ctx context.Context, job *model.ReportJob, runErr error) error
+}
+
+// ReportMailService menjadwalkan dan mengirim laporan berkala lewat email sesuai
+// langganan user. Jadwal dan antrean kiriman disimpan di database sehingga
+// aman dijalankan di beberapa replika dan tetap berjalan setelah restart.
+type ReportMailService struct {
+	subs         reportJobStore
+	reports      *ReportService
+	userRepo     *repository.UserRepository
+	settingsRepo *repository.SettingsRepository
+	mailer       mailer.Mailer
+
+	// compose menyusun email untuk satu kiriman; diganti oleh test
+	compose func(ctx context.Context, job *model.ReportJob) (*mailer.Message, error)
+}
+
+// NewReportMailService adalah constructor untuk ReportMailService.
+func NewReportMailService(
+	subs *repository.SubscriptionRepository,
+	reports *ReportService,
+	userRepo *repository.UserRepository,
+	settingsRepo *repository.SettingsRepository,
+	m mailer.Mailer,
+) *ReportMailService {
+	s := &ReportMailService{subs: subs, reports: reports, userRepo: userRepo, settingsRepo: settingsRepo, mailer: m}
+	s.compose = s.composeReport
+	return s
+}
+
+// Run menjadwalkan langganan yang sudah jatuh tempo lalu mengirim antrean kiriman.
+// Mengembalikan jumlah laporan yang terkirim.
+func (s *ReportMailService) Run(ctx context.Context, now time.Time) (int, error) {
+	if err := s.enqueueDue(ctx, now); err != nil {
+		return 0, err
+	}
+
+	ids, err := s.subs.GetPendingJobIDs(ctx, reportJobBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		ran, err := s.runJob(ctx, id)
+		if err != nil {
+			// Satu kiriman gagal tidak menghentikan kiriman lain; dicoba lagi di putaran berikutnya
+			log.Printf("[ReportMailService] Gagal mengirim laporan (job %d): %v", id, err)
+			continue
+		}
+		if ran {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// enqueueDue memasukkan jadwal yang sudah tiba ke antrean dan memajukan jadwal berikutnya.
+// Jika server mati melewati beberapa jadwal, hanya satu kiriman susulan yang dibuat.
+func (s *ReportMailService) enqueueDue(ctx context.Context, now time.Time) error {
+	due, err := s.subs.GetDueSubscriptions(ctx, now)
+	if err != nil {
+		return err
+	}
+	for i := range due {
+		sub := &due[i]
+		cal, err := s.settingsRepo.GetCalendar(ctx, sub.UserID)
+		if err != nil {
+			log.Printf("[ReportMailService] Gagal memuat kalender user %d: %v", sub.UserID, err)
+			continue
+		}
+		if err := s.subs.EnqueueRun(ctx, sub, sub.NextRun(cal, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runJob memproses satu kiriman: klaim (transaksi pendek dengan advisory lock), kirim
+// email tanpa transaksi terbuka, lalu catat hasilnya. 'ran' bernilai false jika
+// kiriman dilewati karena sedang atau sudah diproses replika lain.
+func (s *ReportMailService) runJob(ctx context.Context, jobID int) (ran bool, err error) {
+	job, err := s.subs.ClaimJob(ctx, jobID)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	runErr := s.deliver(ctx, job)
+	if err := s.subs.FinishJob(ctx, job, runErr); err != nil {
+		log.Printf("[ReportMailService] Gagal mencatat hasil job %d: %v", jobID, err)
+		return true, fmt.Errorf("failed to record report job result: %w", err)
+	}
+	return true, runErr
+}
+
+// deliver menyusun email untuk satu kiriman lalu mengirimnya
+func (s *ReportMailService) deliver(ctx context.Context, job *model.ReportJob) error {
+	msg, err := s.compose(ctx, job)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, *msg)
+}
+
+// composeReport menyusun laporan untuk satu kiriman sebagai lampiran email
+func (s *ReportMailService) composeReport(ctx context.Context, job *model.ReportJob) (*mailer.Message, error) {
+	sub := job.Subscription
+	user, err := s.userRepo.GetUserByID(ctx, sub.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, fmt.Errorf("user %d tidak memiliki alamat email", sub.UserID)
+	}
+
+	cal, err := s.settingsRepo.GetCalendar(ctx, sub.UserID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.reports.BuildReport(ctx, sub.UserID, cal, sub.ReportQuery(cal, job.RunAt))
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := report.NewWriter(sub.Format, report.Options{CSVBOM: true})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writer.WriteReport(&buf, data, s.reports.Items(ctx, sub.UserID, data)); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+
+	title := "Laporan Belanja Mingguan"
+	if sub.Frequency == model.ReportFrequencyMonthly {
+		title = "Laporan Belanja Bulanan"
+	}
+	return &mailer.Message{
+		To:      []string{user.Email},
+		Subject: title + ": " + helper.FormatPeriode(data.PeriodStart, data.PeriodEnd),
+		Body:    reportMailBody(data, user.Name),
+		Attachments: []mailer.Attachment{{
+			Filename:    fmt.Sprintf("Laporan_Belanja_%s%s", data.PeriodStart.Format("2006-01-02"), writer.Extension()),
+			ContentType: writer.ContentType(),
+			Data:        buf.Bytes(),
+		}},
+	}, nil
+}
+
+// reportMailBody adalah ringkasan singkat laporan di badan email
+func reportMailBody(data *model.ReportData, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Halo %s,\n\n", name)
+	fmt.Fprintf(&b, "Berikut ringkasan belanja Anda untuk periode %s.\n\n", helper.FormatPeriode(data.PeriodStart, data.PeriodEnd))
+	fmt.Fprintf(&b, "Total belanja : %s\n", helper.FormatRupiah(data.TotalBelanja))
+	if data.TotalBudget > 0 {
+		fmt.Fprintf(&b, "Total budget  : %s (%s terpakai)\n", helper.FormatRupiah(data.TotalBudget), helper.FormatPersen(data.PersenTerpakai()))
+	}
+	if cmp := data.Comparison; cmp != nil && cmp.PersenPerubahan != nil {
+		fmt.Fprintf(&b, "Dibanding periode sebelumnya: %s (%s)\n", helper.FormatRupiah(cmp.TotalBelanja), signedPercent(*cmp.PersenPerubahan))
+	}
+	if len(data.Categories) > 0 {
+		fmt.Fprintf(&b, "Kategori terbesar: %s (%s)\n", data.Categories[0].Kategori, helper.FormatRupiah(data.Categories[0].Total))
+	}
+	b.WriteString("\nLaporan lengkap terlampir.\n")
+	return b.String()
+}
+
+func signedPercent(v float64) string {
+	if v > 0 {
+		return "+" + helper.FormatPersen(v)
+	}
+	return helper.FormatPersen(v)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/mailer"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+)
+
+// fakeJobStore meniru report_jobs di memori: advisory lock, status dan hitungan percobaan
+type fakeJobStore struct {
+	jobs   map[int]*fakeJob
+	order  []int
+	claims int
+}
+
+type fakeJob struct {
+	job    model.ReportJob
+	status string
+	locked bool // Advisory lock sedang dipegang replika lain
+	err    string
+}
+
+func newFakeJobStore(jobs ...model.ReportJob) *fakeJobStore {
+	f := &fakeJobStore{jobs: make(map[int]*fakeJob)}
+	for _, j := range jobs {
+		f.jobs[j.ID] = &fakeJob{job: j, status: model.ReportJobPending}
+		f.order = append(f.order, j.ID)
+	}
+	return f
+}
+
+func (f *fakeJobStore) GetDueSubscriptions(ctx context.Context, now time.Time) ([]model.ReportSubscription, error) {
+	return nil, nil
+}
+
+func (f *fakeJobStore) EnqueueRun(ctx context.Context, s *model.ReportSubscription, nextRunAt time.Time) error {
+	return nil
+}
+
+func (f *fakeJobStore) GetPendingJobIDs(ctx context.Context, limit int) ([]int, error) {
+	var ids []int
+	for _, id := range f.order {
+		if f.jobs[id].status == model.ReportJobPending {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeJobStore) ClaimJob(ctx context.Context, jobID int) (*model.ReportJob, error) {
+	j := f.jobs[jobID]
+	if j.locked || j.status != model.ReportJobPending {
+		return nil, nil
+	}
+	f.claims++
+	j.status = model.ReportJobSending
+	j.job.Attempts++
+	j.job.ClaimedAt = time.Now()
+	claimed := j.job
+	return &claimed, nil
+}
+
+func (f *fakeJobStore) FinishJob(ctx context.Context, job *model.ReportJob, runErr error) error {
+	j := f.jobs[job.ID]
+	if j.status != model.ReportJobSending || !j.job.ClaimedAt.Equal(job.ClaimedAt) {
+		return errors.New("claim expired")
+	}
+	if runErr != nil {
+		j.status = job.StatusAfterFailure()
+		j.err = runErr.Error()
+		return nil
+	}
+	j.status = model.ReportJobDone
+	j.err = ""
+	return nil
+}
+
+func newTestMailService(store *fakeJobStore, m mailer.Mailer, failures int) *ReportMailService {
+	s := &ReportMailService{subs: store, mailer: m}
+	s.compose = func(ctx context.Context, job *model.ReportJob) (*mailer.Message, error) {
+		if job.Attempts <= failures {
+			return nil, errors.New("report unavailable")
+		}
+		return &mailer.Message{To: []string{"user@example.com"}, Subject: job.RunAt.Format("2006-01-02")}, nil
+	}
+	return s
+}
+
+func TestReportMailServiceRun(t *testing.T) {
+	runAt := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	t.Run("klaim, kirim lalu selesai", func(t *testing.T) {
+		store := newFakeJobStore(model.ReportJob{ID: 1, RunAt: runAt})
+		capture := mailer.NewCaptureMailer()
+		s := newTestMailService(store, capture, 0)
+
+		sent, err := s.Run(ctx, runAt)
+		if err != nil || sent != 1 {
+			t.Fatalf("Run() = %d, %v, want 1 sent", sent, err)
+		}
+		if got := store.jobs[1]; got.status != model.ReportJobDone || got.job.Attempts != 1 {
+			t.Errorf("job status = %s attempts = %d, want done after 1 attempt", got.status, got.job.Attempts)
+		}
+		msgs := capture.Messages()
+		if len(msgs) != 1 || msgs[0].Subject != "2026-10-19" {
+			t.Fatalf("captured %+v, want one message for run_at 2026-10-19", msgs)
+		}
+
+		// Kiriman untuk run_at yang sama tidak dikirim ulang
+		if sent, _ := s.Run(ctx, runAt); sent != 0 || len(capture.Messages()) != 1 {
+			t.Errorf("second Run() sent %d, captured %d, want 0 and 1", sent, len(capture.Messages()))
+		}
+	})
+
+	t.Run("dilewati jika dikunci replika lain", func(t *testing.T) {
+		store := newFakeJobStore(model.ReportJob{ID: 1, RunAt: runAt})
+		store.jobs[1].locked = true
+		capture := mailer.NewCaptureMailer()
+		s := newTestMailService(store, capture, 0)
+
+		sent, err := s.Run(ctx, runAt)
+		if err != nil || sent != 0 || len(capture.Messages()) != 0 {
+			t.Fatalf("Run() = %d, %v, captured %d, want nothing sent", sent, err, len(capture.Messages()))
+		}
+		if got := store.jobs[1]; got.status != model.ReportJobPending || got.job.Attempts != 0 {
+			t.Errorf("locked job status = %s attempts = %d, want untouched", got.status, got.job.Attempts)
+		}
+	})
+
+	t.Run("dicoba ulang lalu berhasil", func(t *testing.T) {
+		store := newFakeJobStore(model.ReportJob{ID: 1, RunAt: runAt})
+		capture := mailer.NewCaptureMailer()
+		s := newTestMailService(store, capture, model.MaxReportJobAttempts-1)
+
+		for i := 1; i < model.MaxReportJobAttempts; i++ {
+			if sent, _ := s.Run(ctx, runAt); sent != 0 {
+				t.Fatalf("attempt %d sent %d, want 0", i, sent)
+			}
+			if got := store.jobs[1]; got.status != model.ReportJobPending || got.err == "" {
+				t.Fatalf("attempt %d: status = %s err = %q, want pending with error", i, got.status, got.err)
+			}
+		}
+		if sent, _ := s.Run(ctx, runAt); sent != 1 || len(capture.Messages()) != 1 {
+			t.Fatalf("last attempt sent %d, captured %d, want 1", sent, len(capture.Messages()))
+		}
+		if got := store.jobs[1]; got.status != model.ReportJobDone || got.job.Attempts != model.MaxReportJobAttempts {
+			t.Errorf("status = %s attempts = %d, want done after %d attempts", got.status, got.job.Attempts, model.MaxReportJobAttempts)
+		}
+	})
+
+	t.Run("gagal setelah batas percobaan", func(t *testing.T) {
+		store := newFakeJobStore(model.ReportJob{ID: 1, RunAt: runAt})
+		capture := mailer.NewCaptureMailer()
+		s := newTestMailService(store, capture, model.MaxReportJobAttempts)
+
+		for i := 0; i < model.MaxReportJobAttempts+2; i++ {
+			s.Run(ctx, runAt)
+		}
+		if store.claims != model.MaxReportJobAttempts {
+			t.Errorf("claimed %d times, want %d", store.claims, model.MaxReportJobAttempts)
+		}
+		if got := store.jobs[1]; got.status != model.ReportJobFailed || len(capture.Messages()) != 0 {
+			t.Errorf("status = %s captured = %d, want failed with nothing sent", got.status, len(capture.Messages()))
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/inflation"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/report"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// MaxTrendPeriods membatasi jumlah titik tren dalam satu laporan
+// (misal 400 hari untuk granularitas harian)
+const MaxTrendPeriods = 400
+
+// ReportService mengumpulkan data laporan belanja yang dipakai oleh semua format unduhan.
+type ReportService struct {
+	reportRepo *repository.ReportRepository
+	userRepo   *repository.UserRepository
+	inflation  *InflationService
+}
+
+// NewReportService adalah constructor untuk ReportService.
+func NewReportService(reportRepo *repository.ReportRepository, userRepo *repository.UserRepository, inflation *InflationService) *ReportService {
+	return &ReportService{reportRepo: reportRepo, userRepo: userRepo, inflation: inflation}
+}
+
+// BuildReport menyusun laporan untuk rentang tanggal q.From..q.To menurut kalender user,
+// termasuk perbandingan dengan periode lain jika q.Compare bukan CompareNone.
+func (s *ReportService) BuildReport(ctx context.Context, userID int, cal calendar.Calendar, q model.ReportQuery) (*model.ReportData, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report user: %w", err)
+	}
+
+	data := &model.ReportData{
+		UserName:    user.Name,
+		PeriodStart: q.From,
+		PeriodEnd:   q.To,
+		GeneratedAt: cal.Now(),
+		Granularity: q.Granularity,
+	}
+	if data.UserName == "" {
+		data.UserName = user.Username
+	}
+
+	days, err := s.reportRepo.GetSpendingByDay(ctx, userID, q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	data.Trend = BucketSpending(cal, days, q.From, q.To, q.Granularity)
+
+	if data.Categories, err = s.reportRepo.GetSpendingByCategory(ctx, userID, q.From, q.To, 1); err != nil {
+		return nil, err
+	}
+	if data.TotalBudget, err = s.reportRepo.GetBudgetTotalByDateRange(ctx, userID, q.From, q.To); err != nil {
+		return nil, err
+	}
+	data.TotalBelanja = sumCategories(data.Categories)
+
+	if q.Compare != model.CompareNone {
+		from, to := ComparisonRange(cal, q.From, q.To, q.Compare)
+		previous, err := s.reportRepo.GetSpendingByCategory(ctx, userID, from, to, 1)
+		if err != nil {
+			return nil, err
+		}
+		prevTotal := sumCategories(previous)
+		data.Comparison = &model.ReportComparison{
+			Mode:            q.Compare,
+			PeriodStart:     from,
+			PeriodEnd:       to,
+			TotalBelanja:    prevTotal,
+			Selisih:         data.TotalBelanja - prevTotal,
+			PersenPerubahan: model.PercentChange(data.TotalBelanja, prevTotal),
+			Categories:      model.CompareCategories(data.Categories, previous),
+		}
+	}
+
+	// Indeks harga selalu memakai rentang bawaan (24 bulan sampai akhir periode laporan)
+	// karena laporan mingguan terlalu pendek untuk menilai perubahan harga
+	data.Inflation, err = s.inflation.Index(ctx, userID, cal, q.To, inflation.DefaultMonths, inflation.DefaultBaseMonths)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Items mengembalikan sumber item yang sudah dibeli pada periode laporan. Baris dibaca
+// langsung dari cursor database saat writer mengiterasinya.
+func (s *ReportService) Items(ctx context.Context, userID int, data *model.ReportData) report.ItemSource {
+	return func(fn func(model.ReportItem) error) error {
+		return s.reportRepo.StreamPurchasedItems(ctx, userID, data.PeriodStart, data.PeriodEnd, fn)
+	}
+}
+
+// ComparisonRange menghitung periode pembanding untuk from..to (inklusif):
+// ComparePrevious = rentang sepanjang yang sama tepat sebelum from,
+// CompareLastYear = tanggal yang sama setahun sebelumnya (29 Feb menjadi 28 Feb).
+func ComparisonRange(cal calendar.Calendar, from, to time.Time, mode string) (time.Time, time.Time) {
+	if mode == model.CompareLastYear {
+		return calendar.AddMonthsClamped(from, -12), cal.EndOfDay(calendar.AddMonthsClamped(cal.StartOfDay(to), -12))
+	}
+	days := int(cal.StartOfDay(to).Sub(cal.StartOfDay(from)).Hours()/24+0.5) + 1
+	prevTo := cal.EndOfDay(from.AddDate(0, 0, -1))
+	return cal.StartOfDay(from.AddDate(0, 0, -days)), prevTo
+}
+
+// CountPeriods menghitung jumlah titik tren dari 'from' sampai 'to' untuk granularitas g
+func CountPeriods(cal calendar.Calendar, from, to time.Time, g string) int {
+	n := 0
+	for p := periodStart(cal, from, g); !p.After(to); p = nextPeriod(p, g) {
+		n++
+		if n > MaxTrendPeriods {
+			break
+		}
+	}
+	return n
+}
+
+// BucketSpending mengelompokkan belanja harian ke titik tren harian/mingguan/bulanan
+// dari 'from' sampai 'to'. Periode tanpa belanja tetap muncul dengan total 0.
+func BucketSpending(cal calendar.Calendar, days []model.SpendingByDay, from, to time.Time, g string) []model.SpendingByPeriod {
+	totals := make(map[string]float64)
+	for _, d := range days {
+		// Tanggal dari database tidak membawa zona waktu; baca ulang sebagai tanggal di kalender user
+		day := time.Date(d.Tanggal.Year(), d.Tanggal.Month(), d.Tanggal.Day(), 0, 0, 0, 0, cal.Location())
+		totals[periodStart(cal, day, g).Format(calendar.DateLayout)] += d.Total
+	}
+
+	var result []model.SpendingByPeriod
+	for p := periodStart(cal, from, g); !p.After(to); p = nextPeriod(p, g) {
+		result = append(result, model.SpendingByPeriod{
+			Label: periodLabel(cal, p, g),
+			Mulai: p,
+			Total: totals[p.Format(calendar.DateLayout)],
+		})
+	}
+	return result
+}
+
+func periodStart(cal calendar.Calendar, t time.Time, g string) time.Time {
+	day := cal.StartOfDay(t)
+	switch g {
+	case model.GranularityWeek:
+		return cal.WeekStartOf(day)
+	case model.GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+func nextPeriod(p time.Time, g string) time.Time {
+	switch g {
+	case model.GranularityWeek:
+		return p.AddDate(0, 0, 7)
+	case model.GranularityMonth:
+		return p.AddDate(0, 1, 0)
+	default:
+		return p.AddDate(0, 0, 1)
+	}
+}
+
+func periodLabel(cal calendar.Calendar, p time.Time, g string) string {
+	switch g {
+	case model.GranularityWeek:
+		return cal.WeekLabel(p)
+	case model.GranularityMonth:
+		return p.Format("2006-01")
+	default:
+		return p.Format(calendar.DateLayout)
+	}
+}
+
+func sumCategories(categories []model.SpendingByCategory) float64 {
+	total := 0.0
+	for _, c := range categories {
+		total += c.Total
+	}
+	return total
+}
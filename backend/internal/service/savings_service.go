@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/gusti3111/TKBMG/backend/internal/calendar"
+	"github.com/gusti3111/TKBMG/backend/internal/model"
+	"github.com/gusti3111/TKBMG/backend/internal/repository"
+)
+
+// surplusHistoryPeriods adalah jumlah periode budget terakhir yang dipakai
+// untuk menghitung rata-rata surplus pada proyeksi tujuan tabungan.
+const surplusHistoryPeriods = 12
+
+// SavingsService menghitung kemajuan tujuan tabungan dan menyisihkan
+// surplus budget yang sudah tutup periode ke goal auto-sweep.
+type SavingsService struct {
+	savingsRepo  *repository.SavingsRepository
+	budgetRepo   *repository.BudgetRepository
+	settingsRepo *repository.SettingsRepository
+}
+
+// NewSavingsService adalah constructor untuk SavingsService.
+func NewSavingsService(
+	savingsRepo *repository.SavingsRepository,
+	budgetRepo *repository.BudgetRepository,
+	settingsRepo *repository.SettingsRepository,
+) *SavingsService {
+	return &SavingsService{savingsRepo: savingsRepo, budgetRepo: budgetRepo, settingsRepo: settingsRepo}
+}
+
+// Progress menghitung kemajuan dan proyeksi untuk daftar goal milik satu user
+func (s *SavingsService) Progress(ctx context.Context, userID int, cal calendar.Calendar, goals []model.SavingsGoal) ([]model.SavingsProgress, error) {
+	now := cal.Now()
+	surplus, err := s.savingsRepo.GetAverageDailySurplus(ctx, userID, now, surplusHistoryPeriods)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]model.SavingsProgress, len(goals))
+	for i, g := range goals {
+		progress[i] = model.ProjectSavings(g, surplus, now)
+	}
+	return progress, nil
+}
+
+// SweepClosedBudgets menyisihkan surplus setiap budget yang sudah tutup periode
+// ke goal auto-sweep household pemilik budget. Setiap budget hanya di-sweep sekali.
+// Mengembalikan jumlah budget yang di-sweep. Dipanggil oleh scheduler.
+func (s *SavingsService) SweepClosedBudgets(ctx context.Context) (int, error) {
+	householdIDs, err := s.savingsRepo.GetAutoSweepHouseholdIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	swept := 0
+	for _, householdID := range householdIDs {
+		// Periode dianggap tutup menurut zona waktu household
+		cal, err := s.settingsRepo.GetHouseholdCalendar(ctx, householdID)
+		if err != nil {
+			return swept, err
+		}
+		budgetIDs, err := s.savingsRepo.GetUnsweptClosedBudgetIDs(ctx, householdID, cal.Now())
+		if err != nil {
+			return swept, err
+		}
+
+		for _, budgetID := range budgetIDs {
+			status, err := s.budgetRepo.GetBudgetStatusForHousehold(ctx, budgetID, householdID)
+			if err != nil {
+				return swept, err
+			}
+			// Goal dimuat ulang tiap budget agar setoran sweep sebelumnya ikut dihitung
+			goals, err := s.savingsRepo.GetGoalsByHouseholdID(ctx, householdID)
+			if err != nil {
+				return swept, err
+			}
+
+			amounts := model.DistributeSurplus(status.Remaining, goals)
+			ok, err := s.savingsRepo.SweepBudget(ctx, budgetID, amounts)
+			if err != nil {
+				return swept, err
+			}
+			if ok {
+				swept++
+				log.Printf("[SavingsService] Surplus budget %d household %d disisihkan ke %d goal", budgetID, householdID, len(amounts))
+			}
+		}
+	}
+	return swept, nil
+}
@@ -0,0 +1,232 @@
+// Package stream adalah pub/sub di dalam proses untuk update live (Server-Sent Events).
+// Jalur tulis item, budget dan kategori mem-publish event ke Hub; setiap koneksi
+// /api/v1/stream berlangganan event household aktif user-nya, sehingga perubahan dari
+// satu anggota langsung terlihat oleh anggota lain. Dengan beberapa replika backend,
+// event diteruskan antar replika lewat Publisher seperti PGBridge (LISTEN/NOTIFY).
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// subscriptionBuffer adalah jumlah event yang boleh antre per koneksi. Koneksi yang
+// tertinggal lebih jauh diputus dan akan menyambung ulang dengan Last-Event-ID.
+const subscriptionBuffer = 64
+
+// MemberRemovedEvent dikirim ke stream household saat seorang anggota dikeluarkan
+// atau keluar sendiri. Setiap replika yang menerimanya memutus langganan anggota itu.
+const MemberRemovedEvent = "member.remove"
+
+// MemberRemoved adalah data MemberRemovedEvent
+type MemberRemoved struct {
+	UserID int `json:"id_user"`
+}
+
+// Event adalah satu pesan di stream milik sebuah household
+type Event struct {
+	ID          int64           `json:"id"`
+	HouseholdID int             `json:"id_household"`
+	ActorID     int             `json:"id_user"` // Anggota yang melakukan perubahan
+	Type        string          `json:"type"`    // <entitas>.<aksi>, misal "item.create"
+	Data        json.RawMessage `json:"data"`
+}
+
+// Publisher meneruskan event ke semua replika. Publisher memberi ID event lalu
+// mengantarkannya kembali ke Hub setiap replika lewat Hub.Deliver.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Subscription adalah langganan satu koneksi stream. C ditutup saat langganan
+// berakhir: koneksi terlalu lambat, user dikeluarkan dari household, Hub di-reset
+// atau Hub ditutup.
+type Subscription struct {
+	C           <-chan Event
+	ch          chan Event
+	householdID int
+	userID      int
+	closed      bool
+}
+
+// Hub menyimpan langganan aktif dan riwayat event terbaru untuk resume.
+type Hub struct {
+	mu        sync.Mutex
+	subs      map[int]map[*Subscription]struct{}
+	history   []Event // Urut sesuai urutan diterima, paling lama di depan
+	size      int
+	lastID    int64
+	publisher Publisher
+	closed    bool
+}
+
+// NewHub membuat Hub dengan riwayat sebanyak 'size' event (seluruh household) untuk resume.
+func NewHub(size int) *Hub {
+	return &Hub{
+		subs: make(map[int]map[*Subscription]struct{}),
+		size: size,
+		// ID lokal dimulai dari waktu start agar ID lama dari proses sebelumnya
+		// tidak tertukar dengan event baru setelah restart
+		lastID: time.Now().UnixMicro(),
+	}
+}
+
+// UsePublisher mengalihkan Publish ke p (misal PGBridge). Tanpa publisher, event
+// hanya diantarkan ke langganan di proses ini.
+func (h *Hub) UsePublisher(p Publisher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publisher = p
+}
+
+// Publish mengirim event bertipe 'eventType' dengan data 'data' dari actorID ke stream
+// milik householdID.
+func (h *Hub) Publish(ctx context.Context, householdID int, actorID int, eventType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("stream: failed to marshal event data: %w", err)
+	}
+	e := Event{HouseholdID: householdID, ActorID: actorID, Type: eventType, Data: raw}
+
+	h.mu.Lock()
+	publisher := h.publisher
+	if publisher == nil {
+		h.lastID++
+		e.ID = h.lastID
+		h.deliverLocked(e)
+	}
+	h.mu.Unlock()
+
+	if publisher != nil {
+		return publisher.Publish(ctx, e)
+	}
+	return nil
+}
+
+// RemoveMember memberi tahu stream household bahwa userID bukan lagi anggotanya.
+// Langganan userID di household itu diputus di semua replika, sehingga ia tidak lagi
+// menerima event household tersebut.
+func (h *Hub) RemoveMember(ctx context.Context, householdID int, actorID int, userID int) error {
+	return h.Publish(ctx, householdID, actorID, MemberRemovedEvent, MemberRemoved{UserID: userID})
+}
+
+// Deliver mengantarkan event yang sudah ber-ID (dari Publisher) ke langganan lokal.
+func (h *Hub) Deliver(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.deliverLocked(e)
+}
+
+func (h *Hub) deliverLocked(e Event) {
+	if h.closed {
+		return
+	}
+	if len(h.history) >= h.size && h.size > 0 {
+		h.history = append(h.history[1:], e)
+	} else if h.size > 0 {
+		h.history = append(h.history, e)
+	}
+
+	for sub := range h.subs[e.HouseholdID] {
+		select {
+		case sub.ch <- e:
+		default:
+			// Koneksi tidak sanggup mengikuti, putus agar klien resume dari riwayat
+			h.removeLocked(sub)
+		}
+	}
+
+	if e.Type == MemberRemovedEvent {
+		var removed MemberRemoved
+		if err := json.Unmarshal(e.Data, &removed); err == nil {
+			for sub := range h.subs[e.HouseholdID] {
+				if sub.userID == removed.UserID {
+					h.removeLocked(sub)
+				}
+			}
+		}
+	}
+}
+
+// Subscribe mendaftarkan koneksi userID ke stream householdID. Jika lastEventID bukan 0,
+// event household setelah ID tersebut dikembalikan sebagai backlog. resumed bernilai false
+// jika lastEventID sudah tidak ada di riwayat, sehingga klien perlu memuat ulang data.
+func (h *Hub) Subscribe(householdID int, userID int, lastEventID int64) (sub *Subscription, backlog []Event, resumed bool) {
+	ch := make(chan Event, subscriptionBuffer)
+	sub = &Subscription{C: ch, ch: ch, householdID: householdID, userID: userID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		sub.closed = true
+		close(ch)
+		return sub, nil, false
+	}
+
+	resumed = lastEventID == 0
+	for _, e := range h.history {
+		if !resumed {
+			resumed = e.ID == lastEventID
+			continue
+		}
+		if lastEventID != 0 && e.HouseholdID == householdID {
+			backlog = append(backlog, e)
+		}
+	}
+
+	if h.subs[householdID] == nil {
+		h.subs[householdID] = make(map[*Subscription]struct{})
+	}
+	h.subs[householdID][sub] = struct{}{}
+	return sub, backlog, resumed
+}
+
+// Unsubscribe mengakhiri langganan. Aman dipanggil lebih dari sekali.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+func (h *Hub) removeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+	delete(h.subs[sub.householdID], sub)
+	if len(h.subs[sub.householdID]) == 0 {
+		delete(h.subs, sub.householdID)
+	}
+}
+
+// Reset membuang riwayat dan memutus semua langganan. Dipakai saat event mungkin
+// terlewat (misal koneksi LISTEN terputus); klien yang menyambung ulang tidak bisa
+// resume dan akan diminta memuat ulang data.
+func (h *Hub) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.history = nil
+	h.disconnectAllLocked()
+}
+
+// Close memutus semua langganan dan menolak langganan baru, agar koneksi stream
+// berakhir saat server dimatikan.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	h.disconnectAllLocked()
+}
+
+func (h *Hub) disconnectAllLocked() {
+	for _, subs := range h.subs {
+		for sub := range subs {
+			h.removeLocked(sub)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// notifyChannel adalah channel LISTEN/NOTIFY yang dipakai bersama semua replika
+	notifyChannel = "bmg_stream"
+
+	// maxNotifyPayload sedikit di bawah batas payload NOTIFY PostgreSQL (8000 byte)
+	maxNotifyPayload = 7900
+
+	// listenerPingInterval memeriksa koneksi LISTEN saat tidak ada notifikasi
+	listenerPingInterval = 90 * time.Second
+)
+
+// PGBridge meneruskan event antar replika backend lewat PostgreSQL LISTEN/NOTIFY.
+// ID event diambil dari sequence stream_event_seq sehingga sama di semua replika,
+// dan Last-Event-ID tetap berlaku walau klien menyambung ulang ke replika lain.
+type PGBridge struct {
+	db      *sql.DB
+	connStr string
+}
+
+// NewPGBridge membuat bridge. connStr dipakai untuk koneksi LISTEN terpisah dari pool db.
+func NewPGBridge(db *sql.DB, connStr string) *PGBridge {
+	return &PGBridge{db: db, connStr: connStr}
+}
+
+// Publish memberi ID pada event lalu mengirimnya dengan NOTIFY. Event baru sampai ke
+// langganan (termasuk di replika ini) setelah diterima kembali oleh Run.
+func (b *PGBridge) Publish(ctx context.Context, e Event) error {
+	if err := b.db.QueryRowContext(ctx, `SELECT nextval('stream_event_seq')`).Scan(&e.ID); err != nil {
+		return fmt.Errorf("failed to allocate stream event id: %w", err)
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		// Data terlalu besar untuk NOTIFY; klien cukup tahu tipe event lalu memuat ulang
+		e.Data = nil
+		if payload, err = json.Marshal(e); err != nil {
+			return fmt.Errorf("failed to marshal stream event: %w", err)
+		}
+	}
+
+	if _, err := b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to notify stream event: %w", err)
+	}
+	return nil
+}
+
+// Run mendengarkan notifikasi dan mengantarkannya ke hub sampai ctx selesai. Publish
+// hub baru dialihkan ke bridge setelah LISTEN berhasil; sebelumnya hub tetap lokal.
+// Jika koneksi LISTEN sempat terputus, hub di-reset karena event mungkin terlewat.
+func (b *PGBridge) Run(ctx context.Context, hub *Hub) {
+	listener := pq.NewListener(b.connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("[Stream] Listener event %d: %v", ev, err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(notifyChannel); err != nil {
+		log.Printf("[Stream] Gagal LISTEN %s: %v", notifyChannel, err)
+		return
+	}
+	hub.UsePublisher(b)
+	log.Printf("[Stream] Bridge LISTEN/NOTIFY aktif di channel %s", notifyChannel)
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case n := <-listener.Notify:
+			if n == nil {
+				// Koneksi baru tersambung ulang; notifikasi selama terputus hilang
+				log.Println("[Stream] Listener tersambung ulang, stream di-reset")
+				hub.Reset()
+				continue
+			}
+			var e Event
+			if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+				log.Printf("[Stream] Payload notifikasi tidak valid: %v", err)
+				continue
+			}
+			hub.Deliver(e)
+
+		case <-ticker.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					log.Printf("[Stream] Ping listener gagal: %v", err)
+				}
+			}()
+		}
+	}
+}
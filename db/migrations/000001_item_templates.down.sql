@@ -0,0 +1,5 @@
+ALTER TABLE items DROP COLUMN IF EXISTS id_template;
+DROP TABLE IF EXISTS item_template_runs;
+DROP TABLE IF EXISTS item_template_entries;
+DROP TABLE IF EXISTS item_templates;
+ALTER TABLE items DROP COLUMN IF EXISTS status;
@@ -0,0 +1,34 @@
+-- Status item: 'planned' untuk item yang direncanakan (misal dari template),
+-- 'purchased' untuk item yang sudah dibeli. Item lama dianggap sudah dibeli.
+ALTER TABLE items ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'purchased';
+
+-- Template daftar belanja yang bisa dipakai ulang (misal "Belanja Mingguan Standar")
+CREATE TABLE IF NOT EXISTS item_templates (
+    id_template     SERIAL PRIMARY KEY,
+    id_user         INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    nama_template   VARCHAR(100) NOT NULL,
+    recurrence      VARCHAR(20) NOT NULL DEFAULT 'none', -- none | weekly | biweekly | monthly
+    start_date      DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS item_template_entries (
+    id_entry        SERIAL PRIMARY KEY,
+    id_template     INT NOT NULL REFERENCES item_templates(id_template) ON DELETE CASCADE,
+    id_kategori     INT NULL REFERENCES referensi_kategori(id_kategori) ON DELETE SET NULL,
+    nama_item       VARCHAR(100) NOT NULL,
+    jumlah_item     INT NOT NULL DEFAULT 1,
+    harga_satuan    NUMERIC(14, 2) NOT NULL DEFAULT 0
+);
+
+-- Satu baris per (template, minggu) yang sudah di-generate.
+-- Primary key ini yang membuat scheduler idempotent: restart server
+-- tidak akan membuat item ganda untuk minggu yang sama.
+CREATE TABLE IF NOT EXISTS item_template_runs (
+    id_template     INT NOT NULL REFERENCES item_templates(id_template) ON DELETE CASCADE,
+    period_start    DATE NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id_template, period_start)
+);
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS id_template INT NULL REFERENCES item_templates(id_template) ON DELETE SET NULL;
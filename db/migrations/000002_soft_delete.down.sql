@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_anggaran_deleted_at;
+DROP INDEX IF EXISTS idx_kategori_deleted_at;
+DROP INDEX IF EXISTS idx_items_deleted_at;
+ALTER TABLE anggaran DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE referensi_kategori DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE items DROP COLUMN IF EXISTS deleted_at;
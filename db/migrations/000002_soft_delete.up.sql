@@ -0,0 +1,9 @@
+-- Soft delete: baris yang dihapus hanya ditandai, lalu dibersihkan permanen
+-- oleh retention job setelah periode tertentu (lihat TRASH_RETENTION).
+ALTER TABLE items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE referensi_kategori ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE anggaran ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at ON items (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_kategori_deleted_at ON referensi_kategori (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_anggaran_deleted_at ON anggaran (deleted_at) WHERE deleted_at IS NOT NULL;
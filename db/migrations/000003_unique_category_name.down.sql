@@ -0,0 +1 @@
+DROP INDEX IF EXISTS uq_kategori_user_nama;
@@ -0,0 +1,19 @@
+-- Nama kategori harus unik per user (tanpa membedakan huruf besar/kecil).
+-- Duplikat yang sudah ada diberi akhiran ID agar index bisa dibuat.
+UPDATE referensi_kategori rk
+SET nama_kategori = rk.nama_kategori || ' (' || rk.id_kategori || ')'
+WHERE rk.deleted_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM referensi_kategori other
+      WHERE other.id_user = rk.id_user
+        AND other.deleted_at IS NULL
+        AND LOWER(other.nama_kategori) = LOWER(rk.nama_kategori)
+        AND other.id_kategori < rk.id_kategori
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_kategori_user_nama
+    ON referensi_kategori (id_user, LOWER(nama_kategori))
+    WHERE deleted_at IS NULL;
+
+-- Item boleh tidak memiliki kategori (pilihan "uncategorize" saat hapus kategori)
+ALTER TABLE items ALTER COLUMN id_kategori DROP NOT NULL;
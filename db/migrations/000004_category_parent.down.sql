@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_kategori_parent;
+ALTER TABLE referensi_kategori DROP COLUMN IF EXISTS id_parent;
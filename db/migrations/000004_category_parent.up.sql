@@ -0,0 +1,6 @@
+-- Kategori bertingkat, misal "Dapur > Bumbu" dan "Dapur > Sayur".
+-- Pencegahan siklus dilakukan di aplikasi (CategoryRepository).
+ALTER TABLE referensi_kategori
+    ADD COLUMN IF NOT EXISTS id_parent INT NULL REFERENCES referensi_kategori(id_kategori) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_kategori_parent ON referensi_kategori (id_parent);
@@ -0,0 +1,12 @@
+-- Aturan kategorisasi otomatis per user: kata kunci atau regex pada nama item.
+CREATE TABLE IF NOT EXISTS kategori_rules (
+    id_rule         SERIAL PRIMARY KEY,
+    id_user         INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    id_kategori     INT NOT NULL REFERENCES referensi_kategori(id_kategori) ON DELETE CASCADE,
+    match_type      VARCHAR(20) NOT NULL DEFAULT 'keyword', -- keyword | regex
+    pattern         VARCHAR(255) NOT NULL,
+    priority        INT NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kategori_rules_user ON kategori_rules (id_user);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_anggaran_user_period;
+ALTER TABLE anggaran DROP COLUMN IF EXISTS period_type;
@@ -0,0 +1,6 @@
+-- Jenis periode budget: weekly | biweekly | monthly | custom.
+-- Budget lama selalu mingguan (Minggu s/d Sabtu).
+ALTER TABLE anggaran ADD COLUMN IF NOT EXISTS period_type VARCHAR(20) NOT NULL DEFAULT 'weekly';
+
+-- Pencarian budget per user berdasarkan rentang tanggal (cek overlap, budget aktif)
+CREATE INDEX IF NOT EXISTS idx_anggaran_user_period ON anggaran (id_user, start_date, end_date) WHERE deleted_at IS NULL;
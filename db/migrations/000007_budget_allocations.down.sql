@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS anggaran_kategori;
+ALTER TABLE anggaran DROP COLUMN IF EXISTS allocation_mode;
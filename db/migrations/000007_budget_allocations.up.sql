@@ -0,0 +1,14 @@
+-- Envelope budgeting: alokasi budget per kategori dalam satu periode anggaran.
+-- allocation_mode 'cap': jumlah_anggaran adalah batas total, sisa di atas alokasi menjadi pool "belum dialokasikan".
+-- allocation_mode 'sum': jumlah_anggaran selalu sama dengan total alokasi.
+ALTER TABLE anggaran ADD COLUMN IF NOT EXISTS allocation_mode VARCHAR(10) NOT NULL DEFAULT 'cap';
+
+CREATE TABLE IF NOT EXISTS anggaran_kategori (
+    id_alokasi      SERIAL PRIMARY KEY,
+    id_anggaran     INT NOT NULL REFERENCES anggaran(id_anggaran) ON DELETE CASCADE,
+    id_kategori     INT NOT NULL REFERENCES referensi_kategori(id_kategori) ON DELETE CASCADE,
+    jumlah          NUMERIC(14, 2) NOT NULL CHECK (jumlah >= 0),
+    -- Jika true, pengeluaran di atas alokasi kategori ini diambil dari pool "belum dialokasikan"
+    draw_from_pool  BOOLEAN NOT NULL DEFAULT false,
+    UNIQUE (id_anggaran, id_kategori)
+);
@@ -0,0 +1,11 @@
+-- Pengaturan per user. Baris dibuat saat user pertama kali menyimpan pengaturan;
+-- jika belum ada, aplikasi memakai nilai bawaan.
+CREATE TABLE IF NOT EXISTS user_settings (
+    id_user          INT PRIMARY KEY REFERENCES "User"(id_user) ON DELETE CASCADE,
+    -- Kebijakan carry-over sisa budget ke periode berikutnya:
+    -- none | full | capped | deficit_only
+    rollover_policy  VARCHAR(20) NOT NULL DEFAULT 'none',
+    -- Batas surplus yang dibawa untuk kebijakan 'capped'
+    rollover_cap     NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    updated_at       TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+ALTER TABLE user_settings DROP COLUMN IF EXISTS notify_email;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS anggaran_alerts;
+ALTER TABLE anggaran DROP COLUMN IF EXISTS alert_thresholds;
@@ -0,0 +1,29 @@
+-- Ambang peringatan budget (persen terpakai) per anggaran
+ALTER TABLE anggaran ADD COLUMN IF NOT EXISTS alert_thresholds INTEGER[] NOT NULL DEFAULT '{50,80,100}';
+
+-- Ambang yang sudah terlewati per anggaran, agar notifikasi hanya dikirim sekali per ambang per periode
+CREATE TABLE IF NOT EXISTS anggaran_alerts (
+    id_anggaran   INT NOT NULL REFERENCES anggaran(id_anggaran) ON DELETE CASCADE,
+    threshold     INT NOT NULL,
+    triggered_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id_anggaran, threshold)
+);
+
+-- Pusat notifikasi in-app
+CREATE TABLE IF NOT EXISTS notifications (
+    id_notifikasi  SERIAL PRIMARY KEY,
+    id_user        INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    tipe           VARCHAR(30) NOT NULL,
+    judul          VARCHAR(150) NOT NULL,
+    pesan          TEXT NOT NULL,
+    id_anggaran    INT REFERENCES anggaran(id_anggaran) ON DELETE SET NULL,
+    is_read        BOOLEAN NOT NULL DEFAULT false,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at        TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (id_user, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (id_user) WHERE is_read = false;
+
+-- Opt-in notifikasi lewat email
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS notify_email BOOLEAN NOT NULL DEFAULT false;
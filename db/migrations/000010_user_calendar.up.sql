@@ -0,0 +1,4 @@
+-- Zona waktu dan hari pertama minggu per user (0 = Minggu, 1 = Senin, ...)
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS week_start SMALLINT NOT NULL DEFAULT 0
+    CHECK (week_start BETWEEN 0 AND 6);
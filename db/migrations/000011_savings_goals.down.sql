@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS anggaran_sweeps;
+DROP TABLE IF EXISTS savings_contributions;
+DROP TABLE IF EXISTS savings_goals;
@@ -0,0 +1,34 @@
+-- Tujuan tabungan per user
+CREATE TABLE IF NOT EXISTS savings_goals (
+    id_goal        SERIAL PRIMARY KEY,
+    id_user        INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    nama_goal      VARCHAR(100) NOT NULL,
+    target_amount  NUMERIC(14, 2) NOT NULL CHECK (target_amount > 0),
+    deadline       DATE,
+    -- Jika true, surplus budget yang sudah tutup periode otomatis disisihkan ke goal ini
+    auto_sweep     BOOLEAN NOT NULL DEFAULT false,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_savings_goals_user ON savings_goals (id_user);
+
+-- Setoran ke tujuan tabungan: manual oleh user atau sweep otomatis dari surplus budget
+CREATE TABLE IF NOT EXISTS savings_contributions (
+    id_kontribusi  SERIAL PRIMARY KEY,
+    id_goal        INT NOT NULL REFERENCES savings_goals(id_goal) ON DELETE CASCADE,
+    jumlah         NUMERIC(14, 2) NOT NULL,
+    sumber         VARCHAR(10) NOT NULL DEFAULT 'manual', -- manual | sweep
+    id_anggaran    INT REFERENCES anggaran(id_anggaran) ON DELETE SET NULL,
+    catatan        VARCHAR(255),
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_savings_contributions_goal ON savings_contributions (id_goal);
+
+-- Budget yang surplusnya sudah disisihkan (satu kali per periode).
+-- jumlah mengurangi sisa budget sehingga tidak ikut di-rollover.
+CREATE TABLE IF NOT EXISTS anggaran_sweeps (
+    id_anggaran  INT PRIMARY KEY REFERENCES anggaran(id_anggaran) ON DELETE CASCADE,
+    jumlah       NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    swept_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,35 @@
+-- Langganan laporan berkala yang dikirim lewat email
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id_langganan  SERIAL PRIMARY KEY,
+    id_user       INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    format        VARCHAR(10) NOT NULL DEFAULT 'pdf',    -- excel | pdf | csv | json | ods
+    frekuensi     VARCHAR(10) NOT NULL,                  -- weekly | monthly
+    -- weekly: hari dalam minggu (0 = Minggu ... 6 = Sabtu); monthly: tanggal 1..28
+    hari          SMALLINT NOT NULL DEFAULT 1,
+    jam           TIME NOT NULL DEFAULT '07:00',         -- Jam kirim di zona waktu user
+    aktif         BOOLEAN NOT NULL DEFAULT true,
+    -- Waktu absolut (bukan jam lokal) kiriman berikutnya, dihitung dari hari/jam di zona waktu user
+    next_run_at   TIMESTAMPTZ NOT NULL,
+    last_run_at   TIMESTAMPTZ,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_due ON report_subscriptions (next_run_at) WHERE aktif;
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_user ON report_subscriptions (id_user);
+
+-- Satu baris per kiriman terjadwal. Disimpan di database agar kiriman yang tertunda
+-- (misal server mati) tetap dijalankan setelah restart; UNIQUE mencegah kiriman ganda
+-- saat beberapa replika menjadwalkan langganan yang sama.
+CREATE TABLE IF NOT EXISTS report_jobs (
+    id_job        SERIAL PRIMARY KEY,
+    id_langganan  INT NOT NULL REFERENCES report_subscriptions(id_langganan) ON DELETE CASCADE,
+    run_at        TIMESTAMPTZ NOT NULL,
+    status        VARCHAR(10) NOT NULL DEFAULT 'pending', -- pending | done | failed
+    attempts      INT NOT NULL DEFAULT 0,
+    last_error    TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at   TIMESTAMPTZ,
+    UNIQUE (id_langganan, run_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_jobs_pending ON report_jobs (run_at) WHERE status = 'pending';
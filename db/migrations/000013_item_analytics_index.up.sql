@@ -0,0 +1,5 @@
+-- Analitik item membaca item 'purchased' per user dalam rentang tanggal
+-- lalu mengelompokkannya berdasarkan nama ternormalisasi.
+CREATE INDEX IF NOT EXISTS idx_items_user_purchased
+    ON items (id_user, purchased_date)
+    WHERE status = 'purchased' AND deleted_at IS NULL;
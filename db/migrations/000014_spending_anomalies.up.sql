@@ -0,0 +1,23 @@
+-- Tanda belanja tidak biasa hasil deteksi otomatis
+CREATE TABLE IF NOT EXISTS spending_anomalies (
+    id_anomali       SERIAL PRIMARY KEY,
+    id_user          INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    jenis            VARCHAR(20) NOT NULL,                 -- price | category_week | duplicate
+    status           VARCHAR(20) NOT NULL DEFAULT 'open',  -- open | dismissed | confirmed
+    id_item          INT REFERENCES items(id_item) ON DELETE CASCADE,
+    id_item_terkait  INT REFERENCES items(id_item) ON DELETE CASCADE,
+    id_kategori      INT REFERENCES referensi_kategori(id_kategori) ON DELETE CASCADE,
+    awal_minggu      DATE,
+    nilai            NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    median           NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    skor             NUMERIC(6, 2) NOT NULL DEFAULT 0,
+    pesan            TEXT NOT NULL,
+    kunci            VARCHAR(100) NOT NULL,
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at      TIMESTAMP,
+    -- Deteksi yang diulang (edit item, batch malam) tidak membuat tanda ganda,
+    -- dan tanda yang sudah di-dismiss tidak muncul lagi
+    UNIQUE (id_user, kunci)
+);
+
+CREATE INDEX IF NOT EXISTS idx_spending_anomalies_user ON spending_anomalies (id_user, status, created_at DESC);
@@ -0,0 +1,30 @@
+-- Log audit append-only: perubahan item, kategori dan budget serta login.
+-- Sengaja tanpa foreign key agar riwayat tetap utuh setelah data (atau user) dihapus permanen.
+CREATE TABLE IF NOT EXISTS events (
+    id_event     BIGSERIAL PRIMARY KEY,
+    id_actor     INT,                          -- NULL untuk login gagal (user tidak dikenal)
+    aksi         VARCHAR(20) NOT NULL,         -- create | update | delete | login | login_failed
+    entitas      VARCHAR(20) NOT NULL,         -- item | category | budget | user
+    id_entitas   INT,
+    sebelum      JSONB,                        -- Field yang berubah, nilai lama (seluruh data untuk delete)
+    sesudah      JSONB,                        -- Field yang berubah, nilai baru (seluruh data untuk create)
+    request_id   VARCHAR(64) NOT NULL DEFAULT '',
+    ip_address   VARCHAR(45) NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_actor ON events (id_actor, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_events_entity ON events (entitas, id_entitas, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_events_created ON events (created_at DESC);
+
+-- Tolak UPDATE/DELETE agar log tidak bisa diubah dari aplikasi
+CREATE OR REPLACE FUNCTION events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_events_append_only ON events;
+CREATE TRIGGER trg_events_append_only
+    BEFORE UPDATE OR DELETE ON events
+    FOR EACH ROW EXECUTE FUNCTION events_append_only();
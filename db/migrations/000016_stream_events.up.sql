@@ -0,0 +1,3 @@
+-- ID event stream live (SSE) yang sama di semua replika backend.
+-- Event sendiri tidak disimpan; replika meneruskannya lewat LISTEN/NOTIFY channel bmg_stream.
+CREATE SEQUENCE IF NOT EXISTS stream_event_seq;
@@ -0,0 +1,28 @@
+DROP FUNCTION IF EXISTS household_writable(INT);
+DROP FUNCTION IF EXISTS household_of(INT);
+
+DROP INDEX IF EXISTS idx_item_templates_household;
+DROP INDEX IF EXISTS idx_items_household_purchased;
+CREATE INDEX IF NOT EXISTS idx_items_user_purchased
+    ON items (id_user, purchased_date)
+    WHERE status = 'purchased' AND deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_anggaran_household_period;
+CREATE INDEX IF NOT EXISTS idx_anggaran_user_period ON anggaran (id_user, start_date, end_date) WHERE deleted_at IS NULL;
+
+-- Kategori yang namanya bentrok setelah kembali per user tidak bisa dipulihkan otomatis
+DROP INDEX IF EXISTS uq_kategori_household_nama;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_kategori_user_nama
+    ON referensi_kategori (id_user, LOWER(nama_kategori))
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE item_templates DROP COLUMN IF EXISTS id_household;
+ALTER TABLE anggaran DROP COLUMN IF EXISTS id_household;
+ALTER TABLE referensi_kategori DROP COLUMN IF EXISTS id_household;
+ALTER TABLE items DROP COLUMN IF EXISTS id_household;
+
+ALTER TABLE "User" DROP COLUMN IF EXISTS id_household_aktif;
+
+DROP TABLE IF EXISTS household_invitations;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
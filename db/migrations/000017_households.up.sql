@@ -0,0 +1,107 @@
+-- Household: beberapa user berbagi item, kategori, budget dan template daftar belanja.
+-- Setiap user selalu punya minimal satu household (household pribadi dibuat saat registrasi).
+CREATE TABLE IF NOT EXISTS households (
+    id_household   SERIAL PRIMARY KEY,
+    nama_household VARCHAR(100) NOT NULL,
+    created_by     INT REFERENCES "User"(id_user) ON DELETE SET NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+    id_household   INT NOT NULL REFERENCES households(id_household) ON DELETE CASCADE,
+    id_user        INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    role           VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    joined_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id_household, id_user)
+);
+
+CREATE INDEX IF NOT EXISTS idx_household_members_user ON household_members (id_user);
+
+-- Undangan bergabung lewat link. Hanya hash SHA-256 token yang disimpan;
+-- token asli hanya ditampilkan sekali saat undangan dibuat.
+CREATE TABLE IF NOT EXISTS household_invitations (
+    id_undangan    SERIAL PRIMARY KEY,
+    id_household   INT NOT NULL REFERENCES households(id_household) ON DELETE CASCADE,
+    token_hash     CHAR(64) NOT NULL UNIQUE,
+    role           VARCHAR(10) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    created_by     INT REFERENCES "User"(id_user) ON DELETE SET NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMPTZ NOT NULL,
+    accepted_by    INT REFERENCES "User"(id_user) ON DELETE SET NULL,
+    accepted_at    TIMESTAMP,
+    revoked_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_household_invitations_household ON household_invitations (id_household, created_at DESC);
+
+-- Household yang sedang dipakai user; semua data item/kategori/budget dibaca dari sini
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS id_household_aktif INT NULL REFERENCES households(id_household) ON DELETE SET NULL;
+
+-- Household pribadi untuk setiap user lama. created_by dipakai sementara untuk mencocokkan
+-- household baru dengan pemiliknya.
+INSERT INTO households (nama_household, created_by)
+SELECT 'Rumah ' || u.username, u.id_user
+FROM "User" u
+WHERE NOT EXISTS (SELECT 1 FROM household_members m WHERE m.id_user = u.id_user);
+
+INSERT INTO household_members (id_household, id_user, role)
+SELECT h.id_household, h.created_by, 'owner'
+FROM households h
+WHERE h.created_by IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM household_members m WHERE m.id_user = h.created_by)
+  AND h.id_household = (SELECT MIN(h2.id_household) FROM households h2 WHERE h2.created_by = h.created_by);
+
+UPDATE "User" u
+SET id_household_aktif = (SELECT MIN(m.id_household) FROM household_members m WHERE m.id_user = u.id_user)
+WHERE u.id_household_aktif IS NULL;
+
+-- Data bersama: pemilik baris kini household; id_user tetap dicatat sebagai pembuatnya
+ALTER TABLE items ADD COLUMN IF NOT EXISTS id_household INT REFERENCES households(id_household) ON DELETE CASCADE;
+ALTER TABLE referensi_kategori ADD COLUMN IF NOT EXISTS id_household INT REFERENCES households(id_household) ON DELETE CASCADE;
+ALTER TABLE anggaran ADD COLUMN IF NOT EXISTS id_household INT REFERENCES households(id_household) ON DELETE CASCADE;
+ALTER TABLE item_templates ADD COLUMN IF NOT EXISTS id_household INT REFERENCES households(id_household) ON DELETE CASCADE;
+
+UPDATE items t SET id_household = u.id_household_aktif FROM "User" u WHERE u.id_user = t.id_user AND t.id_household IS NULL;
+UPDATE referensi_kategori t SET id_household = u.id_household_aktif FROM "User" u WHERE u.id_user = t.id_user AND t.id_household IS NULL;
+UPDATE anggaran t SET id_household = u.id_household_aktif FROM "User" u WHERE u.id_user = t.id_user AND t.id_household IS NULL;
+UPDATE item_templates t SET id_household = u.id_household_aktif FROM "User" u WHERE u.id_user = t.id_user AND t.id_household IS NULL;
+
+ALTER TABLE items ALTER COLUMN id_household SET NOT NULL;
+ALTER TABLE referensi_kategori ALTER COLUMN id_household SET NOT NULL;
+ALTER TABLE anggaran ALTER COLUMN id_household SET NOT NULL;
+ALTER TABLE item_templates ALTER COLUMN id_household SET NOT NULL;
+
+-- Index per user diganti index per household
+DROP INDEX IF EXISTS uq_kategori_user_nama;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_kategori_household_nama
+    ON referensi_kategori (id_household, LOWER(nama_kategori))
+    WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_anggaran_user_period;
+CREATE INDEX IF NOT EXISTS idx_anggaran_household_period
+    ON anggaran (id_household, start_date, end_date) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_items_user_purchased;
+CREATE INDEX IF NOT EXISTS idx_items_household_purchased
+    ON items (id_household, purchased_date)
+    WHERE status = 'purchased' AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_item_templates_household ON item_templates (id_household);
+
+-- Pemeriksaan akses yang dipakai semua query repository.
+-- household_of: household aktif user, selama user masih anggotanya (semua role).
+-- household_writable: sama, tetapi hanya untuk role owner/editor.
+-- Keduanya NULL jika user tidak berhak, sehingga query tidak menemukan baris apa pun.
+CREATE OR REPLACE FUNCTION household_of(p_user INT) RETURNS INT AS $$
+    SELECT m.id_household
+    FROM "User" u
+    JOIN household_members m ON m.id_household = u.id_household_aktif AND m.id_user = u.id_user
+    WHERE u.id_user = p_user
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION household_writable(p_user INT) RETURNS INT AS $$
+    SELECT m.id_household
+    FROM "User" u
+    JOIN household_members m ON m.id_household = u.id_household_aktif AND m.id_user = u.id_user
+    WHERE u.id_user = p_user AND m.role IN ('owner', 'editor')
+$$ LANGUAGE sql STABLE;
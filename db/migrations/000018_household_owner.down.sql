@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS household_owner(INT);
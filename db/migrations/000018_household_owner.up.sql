@@ -0,0 +1,9 @@
+-- Owner yang paling lama menjadi anggota household. Pengaturan periode budget bersama
+-- (zona waktu, awal minggu, kebijakan rollover) dibaca dari pengaturan user ini.
+CREATE OR REPLACE FUNCTION household_owner(p_household INT) RETURNS INT AS $$
+    SELECT m.id_user
+    FROM household_members m
+    WHERE m.id_household = p_household AND m.role = 'owner'
+    ORDER BY m.joined_at, m.id_user
+    LIMIT 1
+$$ LANGUAGE sql STABLE;
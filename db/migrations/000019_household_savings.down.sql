@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_savings_goals_household;
+CREATE INDEX IF NOT EXISTS idx_savings_goals_user ON savings_goals (id_user);
+
+ALTER TABLE savings_goals DROP COLUMN IF EXISTS id_household;
@@ -0,0 +1,14 @@
+-- Tujuan tabungan ikut dimiliki household agar surplus budget bersama disisihkan
+-- ke goal bersama, bukan ke goal anggota yang kebetulan diproses lebih dulu.
+-- id_user tetap dicatat sebagai pembuat goal.
+ALTER TABLE savings_goals ADD COLUMN IF NOT EXISTS id_household INT REFERENCES households(id_household) ON DELETE CASCADE;
+
+UPDATE savings_goals g SET id_household = u.id_household_aktif FROM "User" u WHERE u.id_user = g.id_user AND g.id_household IS NULL;
+
+ALTER TABLE savings_goals ALTER COLUMN id_household SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_savings_goals_user;
+CREATE INDEX IF NOT EXISTS idx_savings_goals_household ON savings_goals (id_household);
+
+-- Penarikan dari goal dicatat sebagai setoran negatif dengan sumber 'withdrawal'
+COMMENT ON COLUMN savings_contributions.sumber IS 'manual | sweep | withdrawal';
@@ -0,0 +1,8 @@
+UPDATE report_jobs SET status = 'pending' WHERE status = 'sending';
+
+DROP INDEX IF EXISTS idx_report_jobs_pending;
+CREATE INDEX IF NOT EXISTS idx_report_jobs_pending ON report_jobs (run_at) WHERE status = 'pending';
+
+COMMENT ON COLUMN report_jobs.status IS NULL;
+
+ALTER TABLE report_jobs DROP COLUMN IF EXISTS claimed_at;
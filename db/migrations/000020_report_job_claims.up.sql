@@ -0,0 +1,10 @@
+-- Kiriman laporan diklaim (status 'sending') dan di-commit sebelum email dikirim,
+-- sehingga transaksi dan advisory lock tidak tertahan selama koneksi SMTP.
+-- claimed_at dipakai untuk mengambil ulang kiriman yang terhenti di tengah jalan
+-- (misal server mati setelah klaim).
+ALTER TABLE report_jobs ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN report_jobs.status IS 'pending | sending | done | failed';
+
+DROP INDEX IF EXISTS idx_report_jobs_pending;
+CREATE INDEX IF NOT EXISTS idx_report_jobs_pending ON report_jobs (run_at) WHERE status IN ('pending', 'sending');
@@ -0,0 +1,12 @@
+-- Tiket sekali pakai untuk membuka /api/v1/stream. EventSource di browser tidak bisa
+-- mengirim header Authorization, jadi kredensial harus lewat query string yang ikut
+-- tercatat di access log; tiket berumur pendek dipakai sebagai pengganti JWT.
+-- Disimpan di database agar tiket dari satu replika bisa dipakai di replika lain.
+CREATE TABLE IF NOT EXISTS stream_tickets (
+    ticket_hash  TEXT PRIMARY KEY,             -- sha256 tiket; tiket asli hanya dikirim ke klien
+    id_user      INT NOT NULL REFERENCES "User"(id_user) ON DELETE CASCADE,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stream_tickets_expires ON stream_tickets (expires_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_kategori_rules_household;
+CREATE INDEX IF NOT EXISTS idx_kategori_rules_user ON kategori_rules (id_user);
+
+ALTER TABLE kategori_rules DROP COLUMN IF EXISTS id_household;
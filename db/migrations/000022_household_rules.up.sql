@@ -0,0 +1,10 @@
+-- Aturan kategorisasi ikut dimiliki household, sama seperti kategori yang ditunjuknya,
+-- agar semua anggota memakai aturan yang sama. id_user tetap dicatat sebagai pembuat aturan.
+ALTER TABLE kategori_rules ADD COLUMN IF NOT EXISTS id_household INT REFERENCES households(id_household) ON DELETE CASCADE;
+
+UPDATE kategori_rules r SET id_household = rk.id_household FROM referensi_kategori rk WHERE rk.id_kategori = r.id_kategori AND r.id_household IS NULL;
+
+ALTER TABLE kategori_rules ALTER COLUMN id_household SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_kategori_rules_user;
+CREATE INDEX IF NOT EXISTS idx_kategori_rules_household ON kategori_rules (id_household);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_spending_anomalies_household;
+CREATE INDEX IF NOT EXISTS idx_spending_anomalies_user ON spending_anomalies (id_user, status, created_at DESC);
+
+ALTER TABLE spending_anomalies DROP CONSTRAINT IF EXISTS spending_anomalies_id_household_kunci_key;
+ALTER TABLE spending_anomalies ADD CONSTRAINT spending_anomalies_id_user_kunci_key UNIQUE (id_user, kunci);
+
+ALTER TABLE spending_anomalies DROP COLUMN IF EXISTS id_household;
@@ -0,0 +1,25 @@
+-- Anomali ikut dimiliki household, sama seperti item dan kategori yang ditandainya,
+-- agar semua anggota melihat dan menindaklanjuti tanda yang sama.
+-- id_user tetap dicatat sebagai user yang item-nya memicu deteksi.
+ALTER TABLE spending_anomalies ADD COLUMN IF NOT EXISTS id_household INT REFERENCES households(id_household) ON DELETE CASCADE;
+
+-- price dan duplicate punya id_item; category_week punya id_kategori
+UPDATE spending_anomalies a SET id_household = i.id_household FROM items i WHERE i.id_item = a.id_item AND a.id_household IS NULL;
+UPDATE spending_anomalies a SET id_household = rk.id_household FROM referensi_kategori rk WHERE rk.id_kategori = a.id_kategori AND a.id_household IS NULL;
+DELETE FROM spending_anomalies WHERE id_household IS NULL;
+
+-- Anggota berbeda bisa memicu tanda category_week yang sama. Pertahankan satu per kunci:
+-- yang sudah ditindaklanjuti lebih dulu, lalu yang paling lama.
+DELETE FROM spending_anomalies a
+USING spending_anomalies b
+WHERE a.id_household = b.id_household AND a.kunci = b.kunci AND a.id_anomali <> b.id_anomali
+  AND ((a.status = 'open') > (b.status = 'open')
+       OR ((a.status = 'open') = (b.status = 'open') AND a.id_anomali > b.id_anomali));
+
+ALTER TABLE spending_anomalies ALTER COLUMN id_household SET NOT NULL;
+
+ALTER TABLE spending_anomalies DROP CONSTRAINT IF EXISTS spending_anomalies_id_user_kunci_key;
+ALTER TABLE spending_anomalies ADD CONSTRAINT spending_anomalies_id_household_kunci_key UNIQUE (id_household, kunci);
+
+DROP INDEX IF EXISTS idx_spending_anomalies_user;
+CREATE INDEX IF NOT EXISTS idx_spending_anomalies_household ON spending_anomalies (id_household, status, created_at DESC);